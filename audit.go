@@ -0,0 +1,275 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Redacted replaces redacted header values and JSON fields in audit records.
+const Redacted = "[REDACTED]"
+
+// AuditRecord describes a handled request.
+type AuditRecord struct {
+	Time   time.Time
+	Method string
+	Path   string
+	Route  string
+	Query  string
+	// ClientIP is the client IP as resolved by Context.ClientIP.
+	ClientIP string
+	// Principal is the principal stored under PrincipalKey, if any.
+	Principal any
+	Status    int
+	Latency   time.Duration
+	// RequestHeaders and ResponseHeaders hold the headers selected with AuditHeaders.
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+	// RequestBody and ResponseBody are only captured with AuditBodies.
+	RequestBody           []byte
+	RequestBodyTruncated  bool
+	ResponseBody          []byte
+	ResponseBodyTruncated bool
+}
+
+// AuditSink receives audit records. Records are delivered one at a time in request completion order.
+type AuditSink interface {
+	Write(record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to an AuditSink.
+type AuditSinkFunc func(record AuditRecord) error
+
+func (f AuditSinkFunc) Write(record AuditRecord) error {
+	return f(record)
+}
+
+type auditConfig struct {
+	headers      []string
+	redacted     map[string]bool
+	bodyLimit    int
+	redactFields map[string]bool
+	bufferSize   int
+}
+
+// AuditOption configures the Audit middleware.
+type AuditOption func(c *auditConfig)
+
+// AuditHeaders selects the request and response headers to record.
+func AuditHeaders(names ...string) AuditOption {
+	return func(c *auditConfig) {
+		c.headers = append(c.headers, names...)
+	}
+}
+
+// AuditRedactHeaders records the given headers as Redacted. Authorization, Cookie and Set-Cookie are always redacted.
+func AuditRedactHeaders(names ...string) AuditOption {
+	return func(c *auditConfig) {
+		for _, n := range names {
+			c.redacted[http.CanonicalHeaderKey(n)] = true
+		}
+	}
+}
+
+// AuditBodies records request and response bodies up to limit bytes each.
+func AuditBodies(limit int) AuditOption {
+	return func(c *auditConfig) {
+		c.bodyLimit = limit
+	}
+}
+
+// AuditRedactFields replaces the values of the given fields in JSON bodies with Redacted, at any depth.
+// Bodies that cannot be parsed as JSON, e.g. truncated bodies, are omitted, so that no field is recorded unredacted.
+func AuditRedactFields(fields ...string) AuditOption {
+	return func(c *auditConfig) {
+		for _, f := range fields {
+			c.redactFields[f] = true
+		}
+	}
+}
+
+// AuditBuffer sets the number of records buffered for the sink. Defaults to 1024.
+// Requests block while the buffer is full, so that no record is lost.
+func AuditBuffer(size int) AuditOption {
+	return func(c *auditConfig) {
+		c.bufferSize = size
+	}
+}
+
+// Audit returns a middleware that records requests and delivers them asynchronously to sink.
+// Sink errors are logged.
+func Audit(sink AuditSink, opts ...AuditOption) HandlerFunc {
+	cfg := &auditConfig{
+		redacted: map[string]bool{
+			"Authorization": true,
+			"Cookie":        true,
+			"Set-Cookie":    true,
+		},
+		redactFields: make(map[string]bool),
+		bufferSize:   1024,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	records := make(chan AuditRecord, cfg.bufferSize)
+	var start sync.Once
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		start.Do(func() {
+			go deliverAuditRecords(sink, records)
+		})
+		record := cfg.begin(gc)
+		record.ClientIP = c.ClientIP()
+		var capture *auditWriter
+		if cfg.bodyLimit > 0 {
+			capture = &auditWriter{ResponseWriter: gc.Writer, limit: cfg.bodyLimit}
+			gc.Writer = capture
+		}
+		c.Next()
+		record.Status = gc.Writer.Status()
+		record.Latency = time.Since(record.Time)
+		record.ResponseHeaders = cfg.selectHeaders(gc.Writer.Header())
+		record.Principal, _ = gc.Get(PrincipalKey)
+		if capture != nil {
+			record.ResponseBody = cfg.redactBody(capture.body.Bytes())
+			record.ResponseBodyTruncated = capture.truncated
+		}
+		records <- record
+	}
+}
+
+func deliverAuditRecords(sink AuditSink, records <-chan AuditRecord) {
+	for record := range records {
+		if err := sink.Write(record); err != nil {
+			log.Printf("[jug] unable to write audit record: %v", err)
+		}
+	}
+}
+
+// begin records the request and captures its body, leaving the body readable for handlers.
+func (cfg *auditConfig) begin(c *gin.Context) AuditRecord {
+	record := AuditRecord{
+		Time:           time.Now(),
+		Method:         c.Request.Method,
+		Path:           c.Request.URL.Path,
+		Route:          c.FullPath(),
+		Query:          c.Request.URL.RawQuery,
+		RequestHeaders: cfg.selectHeaders(c.Request.Header),
+	}
+	if cfg.bodyLimit > 0 && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, int64(cfg.bodyLimit)+1))
+		rest := c.Request.Body
+		c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), rest), Closer: rest}
+		if err == nil {
+			record.RequestBodyTruncated = len(body) > cfg.bodyLimit
+			if record.RequestBodyTruncated {
+				body = body[:cfg.bodyLimit]
+			}
+			record.RequestBody = cfg.redactBody(body)
+		}
+	}
+	return record
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func (cfg *auditConfig) selectHeaders(h http.Header) map[string]string {
+	if len(cfg.headers) == 0 {
+		return nil
+	}
+	selected := make(map[string]string, len(cfg.headers))
+	for _, name := range cfg.headers {
+		key := http.CanonicalHeaderKey(name)
+		values := h.Values(key)
+		if len(values) == 0 {
+			continue
+		}
+		if cfg.redacted[key] {
+			selected[key] = Redacted
+			continue
+		}
+		selected[key] = strings.Join(values, ", ")
+	}
+	return selected
+}
+
+func (cfg *auditConfig) redactBody(body []byte) []byte {
+	if len(cfg.redactFields) == 0 || len(body) == 0 {
+		return body
+	}
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(cfg.redactValue(doc))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+func (cfg *auditConfig) redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, fv := range t {
+			if cfg.redactFields[k] {
+				t[k] = Redacted
+			} else {
+				t[k] = cfg.redactValue(fv)
+			}
+		}
+	case []any:
+		for i, e := range t {
+			t[i] = cfg.redactValue(e)
+		}
+	}
+	return v
+}
+
+// auditWriter captures up to limit bytes of the response body.
+type auditWriter struct {
+	gin.ResponseWriter
+	limit     int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *auditWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *auditWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *auditWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *auditWriter) capture(data []byte) {
+	remaining := w.limit - w.body.Len()
+	if len(data) > remaining {
+		data = data[:remaining]
+		w.truncated = true
+	}
+	w.body.Write(data)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAudit(t *testing.T) {
+	records := make(chan AuditRecord, 1)
+	sink := AuditSinkFunc(func(record AuditRecord) error {
+		records <- record
+		return nil
+	})
+	e := New()
+	e.Use(Audit(sink, AuditHeaders("X-Request-Id", "Authorization", "Content-Type"), AuditBodies(64), AuditRedactFields("password")))
+
+	e.POST("/users/:id", func(c Context) {
+		var body map[string]any
+		if !c.MustBindJSON(&body) {
+			return
+		}
+		c.SetHeader("X-Request-Id", "abc")
+		c.RespondCreated(map[string]any{"name": body["name"], "password": "secret", "bio": strings.Repeat("x", 100)})
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/users/1?notify=true", strings.NewReader(`{"name":"jug","password":"hunter2"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer token")
+	w := serveRequest(e, r)
+	if w.Code != http.StatusCreated {
+		t.Fatal("expected handler to read the body, got", w.Code, w.Body.String())
+	}
+
+	var record AuditRecord
+	select {
+	case record = <-records:
+	case <-time.After(time.Second):
+		t.Fatal("expected audit record")
+	}
+	if record.Method != http.MethodPost || record.Path != "/users/1" || record.Route != "/users/:id" || record.Query != "notify=true" || record.Status != http.StatusCreated {
+		t.Fatal("unexpected record", record)
+	}
+	if record.RequestHeaders["Authorization"] != Redacted || record.RequestHeaders["Content-Type"] != "application/json" {
+		t.Fatal("unexpected request headers", record.RequestHeaders)
+	}
+	if record.ResponseHeaders["X-Request-Id"] != "abc" {
+		t.Fatal("unexpected response headers", record.ResponseHeaders)
+	}
+	if string(record.RequestBody) != `{"name":"jug","password":"[REDACTED]"}` || record.RequestBodyTruncated {
+		t.Fatal("unexpected request body", string(record.RequestBody))
+	}
+	if record.ResponseBody != nil || !record.ResponseBodyTruncated {
+		t.Fatal("expected truncated response body to be omitted, got", string(record.ResponseBody))
+	}
+
+	e = New()
+	e.GET("/text", Audit(sink, AuditBodies(4)), func(c Context) {
+		c.String(http.StatusOK, "hello")
+	})
+	serve(e, http.MethodGet, "/text")
+	select {
+	case record = <-records:
+	case <-time.After(time.Second):
+		t.Fatal("expected audit record")
+	}
+	if string(record.ResponseBody) != "hell" || !record.ResponseBodyTruncated {
+		t.Fatal("expected truncated response body, got", string(record.ResponseBody))
+	}
+}
+
+func TestAudit_ClientIP(t *testing.T) {
+	records := make(chan AuditRecord, 1)
+	e := New()
+	e.Use(Audit(AuditSinkFunc(func(record AuditRecord) error {
+		records <- record
+		return nil
+	})))
+	e.GET("/admin", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	serveFrom(e, "198.51.100.1:1234", "203.0.113.7")
+	select {
+	case record := <-records:
+		if record.ClientIP != "198.51.100.1" {
+			t.Error("expected the peer address for a spoofed X-Forwarded-For, got", record.ClientIP)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected audit record")
+	}
+}
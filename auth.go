@@ -0,0 +1,54 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// PrincipalKey is the context key holding the principal authenticated by BasicAuth or APIKeyAuth.
+const PrincipalKey = "jug.principal"
+
+// BasicAuth returns a middleware that requires HTTP basic authentication.
+// The user name is stored as principal on the context under PrincipalKey.
+// Requests without valid credentials are aborted with 401 and a WWW-Authenticate challenge for the given realm.
+func BasicAuth(realm string, verify func(user string, pass string) bool) HandlerFunc {
+	challenge := fmt.Sprintf("Basic realm=%s, charset=\"UTF-8\"", strconv.Quote(realm))
+	return func(c Context) {
+		user, pass, ok := c.BasicAuth()
+		if !ok || !verify(user, pass) {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.HandleError(NewUnauthorizedError("invalid credentials"))
+			c.Abort()
+			return
+		}
+		c.Set(PrincipalKey, user)
+	}
+}
+
+// APIKeyAuth returns a middleware that requires an API key in the given request header.
+// The principal returned by verify is stored on the context under PrincipalKey.
+// Requests without a valid key are aborted with 401.
+func APIKeyAuth(header string, verify func(key string) (principal any, ok bool)) HandlerFunc {
+	challenge := fmt.Sprintf("APIKey header=%s", strconv.Quote(header))
+	return func(c Context) {
+		key := c.GetHeader(header)
+		if len(key) == 0 {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.HandleError(NewUnauthorizedError(fmt.Sprintf("header %s is required", header)))
+			c.Abort()
+			return
+		}
+		principal, ok := verify(key)
+		if !ok {
+			c.SetHeader("WWW-Authenticate", challenge)
+			c.HandleError(NewUnauthorizedError("invalid api key"))
+			c.Abort()
+			return
+		}
+		c.Set(PrincipalKey, principal)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func authTestEngine(auth HandlerFunc) Engine {
+	e := New()
+	e.Use(auth)
+	e.GET("/", func(c Context) {
+		c.String(http.StatusOK, fmt.Sprint(c.MustGet(PrincipalKey)))
+	})
+	return e
+}
+
+func TestBasicAuth(t *testing.T) {
+	e := authTestEngine(BasicAuth("jug", func(user, pass string) bool {
+		return user == "alice" && pass == "secret"
+	}))
+	tests := []struct {
+		user, pass string
+		expected   int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"alice", "wrong", http.StatusUnauthorized},
+		{"alice", "secret", http.StatusOK},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if len(test.user) > 0 {
+			r.SetBasicAuth(test.user, test.pass)
+		}
+		w := serveRequest(e, r)
+		if w.Code != test.expected {
+			t.Errorf("%s:%s: expected %d, got %d", test.user, test.pass, test.expected, w.Code)
+		}
+		if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Basic realm="jug", charset="UTF-8"` {
+			t.Error("unexpected challenge", w.Header().Get("WWW-Authenticate"))
+		}
+		if w.Code == http.StatusOK && w.Body.String() != "alice" {
+			t.Error("expected principal alice, got", w.Body.String())
+		}
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	e := authTestEngine(APIKeyAuth("X-API-Key", func(key string) (any, bool) {
+		return "service-a", key == "k1"
+	}))
+	tests := []struct {
+		key      string
+		expected int
+	}{
+		{"", http.StatusUnauthorized},
+		{"k2", http.StatusUnauthorized},
+		{"k1", http.StatusOK},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-API-Key", test.key)
+		w := serveRequest(e, r)
+		if w.Code != test.expected {
+			t.Errorf("%s: expected %d, got %d", test.key, test.expected, w.Code)
+		}
+		if w.Code == http.StatusOK && w.Body.String() != "service-a" {
+			t.Error("expected principal service-a, got", w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type msgPackItem struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count"`
+}
+
+func encodeMsgPack(t *testing.T, obj any) []byte {
+	var buf bytes.Buffer
+	if err := MsgPackEncoder.Encode(&buf, obj); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func postBody(e Engine, path string, contentType string, body []byte) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	if len(contentType) > 0 {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return serveRequest(e, r)
+}
+
+func TestContext_Proto(t *testing.T) {
+	e := New()
+	e.POST("/echo", func(c Context) {
+		var msg wrapperspb.StringValue
+		if !c.MustBindProto(&msg) {
+			return
+		}
+		c.RespondProto(http.StatusOK, wrapperspb.String(strings.ToUpper(msg.Value)))
+	})
+	body, _ := proto.Marshal(wrapperspb.String("jug"))
+	w := postBody(e, "/echo", ProtobufContentType, body)
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != ProtobufContentType {
+		t.Fatal("expected protobuf response, got", w.Code, w.Header().Get("Content-Type"))
+	}
+	var res wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &res); err != nil || res.Value != "JUG" {
+		t.Fatal("unexpected response message", res.Value, err)
+	}
+	if w := postBody(e, "/echo", ProtobufContentType, []byte{0xff}); w.Code != http.StatusBadRequest {
+		t.Fatal("expected invalid message to be rejected, got", w.Code)
+	}
+}
+
+func TestContext_MsgPack(t *testing.T) {
+	e := New()
+	e.POST("/echo", func(c Context) {
+		var item msgPackItem
+		if !c.MustBindMsgPack(&item) {
+			return
+		}
+		c.RespondMsgPack(http.StatusCreated, item)
+	})
+	w := postBody(e, "/echo", MsgPackContentType, encodeMsgPack(t, msgPackItem{Name: "jug", Count: 2}))
+	if w.Code != http.StatusCreated || w.Header().Get("Content-Type") != MsgPackContentType {
+		t.Fatal("expected MessagePack response, got", w.Code, w.Header().Get("Content-Type"))
+	}
+	var res msgPackItem
+	if err := codec.NewDecoderBytes(w.Body.Bytes(), new(codec.MsgpackHandle)).Decode(&res); err != nil || res.Name != "jug" || res.Count != 2 {
+		t.Fatal("unexpected response", res, err)
+	}
+	if w := postBody(e, "/echo", MsgPackContentType, encodeMsgPack(t, msgPackItem{Count: 2})); w.Code != http.StatusBadRequest {
+		t.Fatal("expected validation to fail, got", w.Code)
+	}
+}
+
+func TestContext_MustBindBody(t *testing.T) {
+	e := New()
+	e.POST("/items", func(c Context) {
+		var item msgPackItem
+		if !c.MustBindBody(&item) {
+			return
+		}
+		c.RespondOk(item)
+	})
+	e.POST("/messages", func(c Context) {
+		var msg wrapperspb.StringValue
+		if !c.MustBindBody(&msg) {
+			return
+		}
+		c.String(http.StatusOK, msg.Value)
+	})
+	protoBody, _ := proto.Marshal(wrapperspb.String("proto"))
+
+	tests := []struct {
+		path        string
+		contentType string
+		body        []byte
+		status      int
+		response    string
+	}{
+		{"/items", "application/json", []byte(`{"name":"json"}`), http.StatusOK, `{"name":"json","count":0}`},
+		{"/items", "", []byte(`{"name":"default"}`), http.StatusOK, `{"name":"default","count":0}`},
+		{"/items", "application/yaml", []byte("name: yaml\n"), http.StatusOK, `{"name":"yaml","count":0}`},
+		{"/items", "application/x-msgpack", encodeMsgPack(t, msgPackItem{Name: "msgpack"}), http.StatusOK, `{"name":"msgpack","count":0}`},
+		{"/items", "text/plain", []byte("name"), http.StatusUnsupportedMediaType, `{"error":"unsupported content type text/plain"}`},
+		{"/items", ProtobufContentType, protoBody, http.StatusUnsupportedMediaType, `{"error":"unsupported content type application/x-protobuf"}`},
+		{"/messages", ProtobufContentType, protoBody, http.StatusOK, "proto"},
+	}
+	for _, tt := range tests {
+		w := postBody(e, tt.path, tt.contentType, tt.body)
+		if w.Code != tt.status || w.Body.String() != tt.response {
+			t.Fatalf("%s %q: expected %d %s, got %d %s", tt.path, tt.contentType, tt.status, tt.response, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRespond_NegotiatesMsgPack(t *testing.T) {
+	e := New()
+	e.GET("/item", func(c Context) {
+		c.RespondOk(msgPackItem{Name: "jug"})
+	})
+	r := httptest.NewRequest(http.MethodGet, "/item", nil)
+	r.Header.Set("Accept", MsgPackContentType)
+	w := serveRequest(e, r)
+	if w.Header().Get("Content-Type") != MsgPackContentType || !bytes.Equal(w.Body.Bytes(), encodeMsgPack(t, msgPackItem{Name: "jug"})) {
+		t.Fatal("expected MessagePack response, got", w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// valueBinder populates structs from string values.
+// Fields are matched by the given struct tag. A tag value of "-" skips the field.
+// Missing fields are set to the value of their default tag. Missing fields tagged binding:"required" fail.
+type valueBinder struct {
+	tag string
+	// location is used to parse dates without time zone information.
+	location *time.Location
+	values   func(key string) ([]string, bool)
+	// optional skips binding:"required" checks.
+	optional bool
+}
+
+// BindingError is returned when values cannot be bound to a struct. It lists all fields that failed.
+type BindingError struct {
+	Fields []FieldBindingError
+}
+
+// FieldBindingError describes a single value that could not be bound.
+type FieldBindingError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *BindingError) Error() string {
+	var sb strings.Builder
+	for i, f := range e.Fields {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(f.Message)
+	}
+	return sb.String()
+}
+
+// bind populates the struct pointed to by obj.
+// All values are bound before an error is returned. If any value fails, the error is a *BindingError.
+func (b *valueBinder) bind(obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding target must be a pointer to a struct")
+	}
+	if err := bindingTagsOf(v.Elem().Type()).err; err != nil {
+		return err
+	}
+	e := &BindingError{}
+	b.bindStruct(v.Elem(), e)
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func (b *valueBinder) bindStruct(v reflect.Value, e *BindingError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := field.Tag.Lookup(b.tag)
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				b.bindStruct(v.Field(i), e)
+			}
+			continue
+		}
+		name = strings.Split(name, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		raw, ok := b.values(name)
+		if !ok || len(raw) == 0 {
+			if values, ok := defaultValues(field); ok {
+				applyDefault(b, v.Field(i), values)
+			} else if isBindingRequired(field) && !b.optional {
+				e.Fields = append(e.Fields, FieldBindingError{
+					Field:   name,
+					Message: fmt.Sprintf("%s is required", name),
+				})
+			}
+			continue
+		}
+		if err := b.setField(v.Field(i), raw); err != nil {
+			e.Fields = append(e.Fields, FieldBindingError{
+				Field:   name,
+				Message: fmt.Sprintf("invalid value for %s: %s", name, err.Error()),
+			})
+		}
+	}
+}
+
+func (b *valueBinder) setField(f reflect.Value, raw []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(f.Type(), len(raw), len(raw))
+		for i, r := range raw {
+			if err := b.setValue(s.Index(i), r); err != nil {
+				return err
+			}
+		}
+		f.Set(s)
+		return nil
+	}
+	return b.setValue(f, raw[0])
+}
+
+func (b *valueBinder) setValue(f reflect.Value, raw string) error {
+	if f.Kind() == reflect.Pointer {
+		p := reflect.New(f.Type().Elem())
+		if err := b.setValue(p.Elem(), raw); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+	if f.Type() == timeType {
+		t, err := parseTime(raw, b.location)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		x, err := strconv.ParseFloat(raw, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(x)
+	default:
+		return fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return nil
+}
+
+// parseTime parses an ISO 8601 date time or, failing that, an ISO 8601 date in the given location.
+func parseTime(raw string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.ParseInLocation("2006-01-02", raw, loc)
+}
@@ -0,0 +1,249 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var bindingTagsCache sync.Map
+
+// bindingTags describes the default and binding:"required" tags of a struct type.
+type bindingTags struct {
+	found bool
+	// err reports the first default that cannot be parsed.
+	err error
+}
+
+// invalidDefaultError is returned when binding to a struct with a default that cannot be parsed.
+// It is a configuration error and answered with 500.
+type invalidDefaultError struct {
+	field string
+	err   error
+}
+
+func (e *invalidDefaultError) Error() string {
+	return fmt.Sprintf("jug: invalid default for %s: %s", e.field, e.err.Error())
+}
+
+func (e *invalidDefaultError) Unwrap() error {
+	return e.err
+}
+
+// hasBindingTags reports whether a struct type or one of its nested structs has default or binding:"required" tags.
+func hasBindingTags(t reflect.Type) bool {
+	return bindingTagsOf(t).found
+}
+
+// bindingTagsOf returns the binding tags of a struct type. Defaults are checked once, when the type is first seen.
+func bindingTagsOf(t reflect.Type) bindingTags {
+	if cached, ok := bindingTagsCache.Load(t); ok {
+		return cached.(bindingTags)
+	}
+	var result bindingTags
+	findBindingTags(t, map[reflect.Type]bool{}, &result)
+	bindingTagsCache.Store(t, result)
+	return result
+}
+
+// findBindingTags searches a struct type for binding tags. visited guards against recursive types.
+func findBindingTags(t reflect.Type, visited map[reflect.Type]bool, result *bindingTags) {
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if values, ok := defaultValues(f); ok {
+			result.found = true
+			if err := (&valueBinder{}).setField(reflect.New(f.Type).Elem(), values); err != nil && result.err == nil {
+				result.err = &invalidDefaultError{field: t.Name() + "." + f.Name, err: err}
+			}
+		} else if isBindingRequired(f) {
+			result.found = true
+		}
+		if nested := nestedStructType(f.Type); nested != nil {
+			findBindingTags(nested, visited, result)
+		}
+	}
+}
+
+// nestedStructType returns the struct type of a struct or struct pointer field, or nil.
+func nestedStructType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return nil
+	}
+	return t
+}
+
+func isBindingRequired(f reflect.StructField) bool {
+	for _, rule := range strings.Split(f.Tag.Get("binding"), ",") {
+		if strings.TrimSpace(rule) == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultValues returns the values of the default tag of a field. Defaults of slices are separated by commas.
+func defaultValues(f reflect.StructField) ([]string, bool) {
+	def, ok := f.Tag.Lookup("default")
+	if !ok {
+		return nil, false
+	}
+	if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() != reflect.Uint8 {
+		return strings.Split(def, ","), true
+	}
+	return []string{def}, true
+}
+
+// applyDefault sets a field to its default value. Defaults are checked by bindingTagsOf before.
+func applyDefault(b *valueBinder, v reflect.Value, values []string) {
+	_ = b.setField(v, values)
+}
+
+// applyJSONBindingTags sets fields missing from the JSON object in data to their default values
+// and reports missing required fields. obj must already be unmarshalled from data.
+func applyJSONBindingTags(codec JSONCodec, data []byte, obj any) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if tags := bindingTagsOf(v.Elem().Type()); tags.err != nil || !tags.found {
+		return tags.err
+	}
+	var present map[string]json.RawMessage
+	if err := codec.Unmarshal(data, &present); err != nil {
+		return nil
+	}
+	e := &BindingError{}
+	applyJSONStruct(codec, v.Elem(), present, "", e)
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func applyJSONStruct(codec JSONCodec, v reflect.Value, present map[string]json.RawMessage, prefix string, e *BindingError) {
+	b := &valueBinder{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && len(tag) == 0 && f.Type.Kind() == reflect.Struct {
+			applyJSONStruct(codec, v.Field(i), present, prefix, e)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		name := jsonFieldName(f)
+		raw, ok := lookupJSONKey(present, name)
+		if !ok {
+			if values, ok := defaultValues(f); ok {
+				applyDefault(b, v.Field(i), values)
+			} else if isBindingRequired(f) {
+				e.Fields = append(e.Fields, FieldBindingError{
+					Field:   prefix + name,
+					Message: fmt.Sprintf("%s%s is required", prefix, name),
+				})
+			}
+			continue
+		}
+		nested := nestedStructType(f.Type)
+		if nested == nil || !hasBindingTags(nested) {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+		var nestedPresent map[string]json.RawMessage
+		if err := codec.Unmarshal(raw, &nestedPresent); err == nil {
+			applyJSONStruct(codec, fv, nestedPresent, prefix+name+".", e)
+		}
+	}
+}
+
+// lookupJSONKey looks up a key like encoding/json matches keys to fields, preferring an exact match.
+func lookupJSONKey(present map[string]json.RawMessage, name string) (json.RawMessage, bool) {
+	if raw, ok := present[name]; ok {
+		return raw, true
+	}
+	for key, raw := range present {
+		if strings.EqualFold(key, name) {
+			return raw, true
+		}
+	}
+	return nil, false
+}
+
+// bindingValidationError converts the errors of the binding validator to a *BindingError with JSON field names.
+// Failed required rules are dropped, binding:"required" only requires a field to be present, see applyJSONBindingTags.
+func bindingValidationError(obj any, err error) error {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	e := &BindingError{}
+	for _, fe := range errs {
+		if fe.Tag() == "required" {
+			continue
+		}
+		rule := fe.Tag()
+		if len(fe.Param()) > 0 {
+			rule += "=" + fe.Param()
+		}
+		path := jsonFieldPath(t, fe.StructNamespace())
+		e.Fields = append(e.Fields, FieldBindingError{
+			Field:   path,
+			Message: fmt.Sprintf("invalid value for %s: must satisfy %s", path, rule),
+		})
+	}
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// jsonFieldPath converts a struct namespace like Order.Items[0].Name to JSON field names, e.g. items[0].name.
+func jsonFieldPath(t reflect.Type, namespace string) string {
+	segments := strings.Split(namespace, ".")[1:]
+	for i, segment := range segments {
+		name, index, _ := strings.Cut(segment, "[")
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		f, ok := t.FieldByName(name)
+		if t.Kind() != reflect.Struct || !ok {
+			continue
+		}
+		segments[i] = jsonFieldName(f)
+		if len(index) > 0 {
+			segments[i] += "[" + index
+		}
+		t = f.Type
+	}
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,207 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindingTestQuery struct {
+	Name    string     `query:"name"`
+	Limit   int        `query:"limit"`
+	Active  bool       `query:"active"`
+	Since   *time.Time `query:"since"`
+	Tags    []string   `query:"tag"`
+	Ignored string     `query:"-"`
+}
+
+func bindQuery(t *testing.T, raw string, obj any) error {
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &valueBinder{
+		tag: "query",
+		values: func(key string) ([]string, bool) {
+			v, ok := query[key]
+			return v, ok
+		},
+	}
+	return b.bind(obj)
+}
+
+func TestValueBinder_Bind(t *testing.T) {
+	var q bindingTestQuery
+	if err := bindQuery(t, "name=jug&limit=10&active=true&since=2023-09-27&tag=a&tag=b&Ignored=x", &q); err != nil {
+		t.Fatal("bind() should not fail, got", err)
+	}
+	if q.Name != "jug" || q.Limit != 10 || !q.Active {
+		t.Fatalf("unexpected scalar values: %+v", q)
+	}
+	if q.Since == nil || !q.Since.Equal(time.Date(2023, 9, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected since to be parsed as date, got", q.Since)
+	}
+	if len(q.Tags) != 2 || q.Tags[1] != "b" {
+		t.Fatal("expected two tags, got", q.Tags)
+	}
+	if q.Ignored != "" {
+		t.Fatal("expected ignored field to stay empty, got", q.Ignored)
+	}
+}
+
+func TestValueBinder_Bind_InvalidValue(t *testing.T) {
+	var q bindingTestQuery
+	err := bindQuery(t, "limit=ten", &q)
+	if err == nil {
+		t.Fatal("bind() should fail for a non numeric int")
+	}
+}
+
+func TestValueBinder_Bind_CollectsAllErrors(t *testing.T) {
+	var q bindingTestQuery
+	err := bindQuery(t, "limit=ten&active=maybe&since=yesterday&name=jug", &q)
+	e, ok := err.(*BindingError)
+	if !ok {
+		t.Fatal("bind() should return a *BindingError, got", err)
+	}
+	if len(e.Fields) != 3 {
+		t.Fatal("expected three field errors, got", e.Fields)
+	}
+	if e.Fields[0].Field != "limit" || e.Fields[1].Field != "active" || e.Fields[2].Field != "since" {
+		t.Fatal("expected field errors in declaration order, got", e.Fields)
+	}
+	if q.Name != "jug" {
+		t.Fatal("expected valid values to be bound, got", q.Name)
+	}
+}
+
+func TestValueBinder_Bind_NonPointer(t *testing.T) {
+	if err := bindQuery(t, "", bindingTestQuery{}); err == nil {
+		t.Fatal("bind() should fail when not given a pointer")
+	}
+}
+
+type bindingTestDefaults struct {
+	Query  string   `query:"q" binding:"required"`
+	Limit  int      `query:"limit" default:"10"`
+	Sort   []string `query:"sort" default:"name,id"`
+	Offset *int     `query:"offset" default:"0"`
+}
+
+func TestValueBinder_Bind_DefaultsAndRequired(t *testing.T) {
+	var q bindingTestDefaults
+	if err := bindQuery(t, "q=jug&limit=5", &q); err != nil {
+		t.Fatal("bind() should not fail, got", err)
+	}
+	if q.Limit != 5 || len(q.Sort) != 2 || q.Sort[1] != "id" || q.Offset == nil || *q.Offset != 0 {
+		t.Fatalf("expected defaults for missing values only, got %+v", q)
+	}
+	err := bindQuery(t, "", &bindingTestDefaults{})
+	be, ok := err.(*BindingError)
+	if !ok || len(be.Fields) != 1 || be.Fields[0].Field != "q" || be.Fields[0].Message != "q is required" {
+		t.Fatal("expected required error for q, got", err)
+	}
+}
+
+type bindingTestAddress struct {
+	City    string `json:"city" binding:"required"`
+	Country string `json:"country" default:"AT"`
+}
+
+type bindingTestBody struct {
+	Name    string              `json:"name" binding:"required"`
+	Age     int                 `json:"age" default:"18"`
+	Admin   bool                `json:"admin" default:"true"`
+	Address *bindingTestAddress `json:"address"`
+}
+
+func TestJSONBinding_DefaultsAndRequired(t *testing.T) {
+	e := New()
+	e.POST("/people", func(c Context) {
+		var body bindingTestBody
+		if c.MustBindJSON(&body) {
+			c.RespondOk(body)
+		}
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, "/people", strings.NewReader(body)))
+	}
+
+	w := post(`{"name":"Jane","admin":false,"address":{"city":"Vienna"}}`)
+	expected := `{"name":"Jane","age":18,"admin":false,"address":{"city":"Vienna","country":"AT"}}`
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Error("expected defaults for missing fields, got", w.Code, w.Body.String())
+	}
+	w = post(`{"address":{}}`)
+	expected = `{"error":"name is required, address.city is required","fields":[{"field":"name","message":"name is required"},{"field":"address.city","message":"address.city is required"}]}`
+	if w.Code != http.StatusBadRequest || w.Body.String() != expected {
+		t.Error("expected field errors for required fields, got", w.Code, w.Body.String())
+	}
+}
+
+type bindingTestItem struct {
+	Count int `json:"count" binding:"required"`
+	Price int `json:"price" binding:"min=1"`
+}
+
+func TestJSONBinding_RequiredAllowsZeroValues(t *testing.T) {
+	e := New()
+	e.POST("/items", func(c Context) {
+		var body bindingTestItem
+		if c.MustBindJSON(&body) {
+			c.RespondOk(body)
+		}
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+	}
+
+	if w := post(`{"count":0,"price":1}`); w.Code != http.StatusOK {
+		t.Error("expected present zero values to satisfy required, got", w.Code, w.Body.String())
+	}
+	w := post(`{"price":0}`)
+	expected := `{"error":"count is required","fields":[{"field":"count","message":"count is required"}]}`
+	if w.Code != http.StatusBadRequest || w.Body.String() != expected {
+		t.Error("expected required error for missing field, got", w.Code, w.Body.String())
+	}
+	w = post(`{"count":1,"price":0}`)
+	expected = `{"error":"invalid value for price: must satisfy min=1","fields":[{"field":"price","message":"invalid value for price: must satisfy min=1"}]}`
+	if w.Code != http.StatusBadRequest || w.Body.String() != expected {
+		t.Error("expected binding error for failed rules, got", w.Code, w.Body.String())
+	}
+}
+
+type bindingTestInvalidDefault struct {
+	Limit int `query:"limit" json:"limit" default:"ten"`
+}
+
+func TestBinding_InvalidDefault(t *testing.T) {
+	e := New()
+	e.GET("/query", func(c Context) {
+		var q bindingTestInvalidDefault
+		if c.MayBindQuery(&q) {
+			c.RespondNoContent()
+		}
+	})
+	e.POST("/json", func(c Context) {
+		var body bindingTestInvalidDefault
+		if c.MustBindJSON(&body) {
+			c.RespondNoContent()
+		}
+	})
+
+	if w := serve(e, http.MethodGet, "/query"); w.Code != http.StatusInternalServerError {
+		t.Error("expected 500 for an invalid default, got", w.Code)
+	}
+	w := serveRequest(e, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{}`)))
+	if w.Code != http.StatusInternalServerError {
+		t.Error("expected 500 for an invalid default, got", w.Code)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// bodylessWriter discards response bodies that must not be sent:
+// bodies of responses to HEAD requests and bodies of 1xx, 204 and 304 responses.
+// For HEAD requests the Content-Length of the discarded body is reported, unless the response was flushed.
+type bodylessWriter struct {
+	gin.ResponseWriter
+	head      bool
+	discarded int64
+	flushed   bool
+}
+
+func (w *bodylessWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// suppressBodies installs a bodylessWriter for the request.
+func suppressBodies(c *gin.Context) {
+	w := &bodylessWriter{
+		ResponseWriter: c.Writer,
+		head:           c.Request.Method == http.MethodHead,
+	}
+	c.Writer = w
+	c.Next()
+	w.finish()
+}
+
+func (w *bodylessWriter) Write(data []byte) (int, error) {
+	if w.head {
+		w.discarded += int64(len(data))
+		return len(data), nil
+	}
+	if !bodyAllowedForStatus(w.Status()) {
+		w.WriteHeaderNow()
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodylessWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bodylessWriter) Written() bool {
+	return w.discarded > 0 || w.ResponseWriter.Written()
+}
+
+func (w *bodylessWriter) Flush() {
+	w.flushed = true
+	w.ResponseWriter.Flush()
+}
+
+// finish sets the Content-Length of a discarded HEAD response body if the headers are still pending.
+func (w *bodylessWriter) finish() {
+	if !w.head || w.flushed || w.discarded == 0 || w.ResponseWriter.Written() {
+		return
+	}
+	if !bodyAllowedForStatus(w.Status()) || len(w.Header().Get("Content-Length")) > 0 {
+		return
+	}
+	w.Header().Set("Content-Length", strconv.FormatInt(w.discarded, 10))
+}
+
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serve(e Engine, method string, path string) *httptest.ResponseRecorder {
+	return serveRequest(e, httptest.NewRequest(method, path, nil))
+}
+
+// serveRequest serves r with e and returns the recorded response.
+func serveRequest(e Engine, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	return w
+}
+
+// serveHeader serves a GET request for path with the given header, which is omitted if value is empty.
+func serveHeader(e Engine, path string, header string, value string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if len(value) > 0 {
+		r.Header.Set(header, value)
+	}
+	return serveRequest(e, r)
+}
+
+func TestBodylessWriter_Head(t *testing.T) {
+	e := New()
+	handler := func(c Context) {
+		c.RespondOk(map[string]string{"message": "hello"})
+	}
+	e.GET("/hello", handler)
+	e.HEAD("/hello", handler)
+
+	get := serve(e, http.MethodGet, "/hello")
+	head := serve(e, http.MethodHead, "/hello")
+	if head.Code != http.StatusOK {
+		t.Fatal("expected status 200, got", head.Code)
+	}
+	if head.Body.Len() != 0 {
+		t.Fatal("expected no body for HEAD, got", head.Body.String())
+	}
+	if head.Header().Get("Content-Length") != "19" || get.Body.Len() != 19 {
+		t.Fatal("expected the Content-Length of the GET body, got", head.Header().Get("Content-Length"))
+	}
+	if head.Header().Get("Content-Type") != get.Header().Get("Content-Type") {
+		t.Fatal("expected the Content-Type of the GET response, got", head.Header().Get("Content-Type"))
+	}
+}
+
+func TestBodylessWriter_HeadStream(t *testing.T) {
+	e := New()
+	e.HEAD("/stream", func(c Context) {
+		c.Stream(func(w io.Writer) bool {
+			t.Fatal("step function should not be called for HEAD")
+			return false
+		})
+	})
+	w := serve(e, http.MethodHead, "/stream")
+	if w.Body.Len() != 0 || len(w.Header().Get("Content-Length")) > 0 {
+		t.Fatal("expected neither body nor Content-Length")
+	}
+}
+
+func TestBodylessWriter_NoBodyStatus(t *testing.T) {
+	e := New()
+	e.GET("/no-content", func(c Context) {
+		c.String(http.StatusNoContent, "body")
+	})
+	e.GET("/not-modified", func(c Context) {
+		c.Data(http.StatusNotModified, "text/plain", []byte("body"))
+	})
+	for _, path := range []string{"/no-content", "/not-modified"} {
+		w := serve(e, http.MethodGet, path)
+		if w.Body.Len() != 0 {
+			t.Errorf("expected no body for %s, got %s", path, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import "time"
+
+// RouteBudget declares the resources a request to a route may use. It is enforced for the route and reported in its
+// RouteInfo, so documentation generated from the routes does not drift from the behavior.
+type RouteBudget struct {
+	// MaxBodySize limits request bodies to the given number of bytes like MaxBodySize. Zero keeps the engine default.
+	MaxBodySize int64
+	// Timeout sets a deadline for the handlers of the route like Timeout. Zero means no deadline.
+	Timeout time.Duration
+}
+
+// middleware returns the handler enforcing the budget.
+func (b RouteBudget) middleware() HandlerFunc {
+	limit := MaxBodySize(b.MaxBodySize)
+	timeout := Timeout(b.Timeout)
+	return func(c Context) {
+		if b.MaxBodySize > 0 {
+			limit(c)
+		}
+		if b.Timeout > 0 {
+			timeout(c)
+		}
+	}
+}
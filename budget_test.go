@@ -0,0 +1,80 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestRoute_Budget(t *testing.T) {
+	e := New()
+	budget := RouteBudget{MaxBodySize: 16, Timeout: 10 * time.Millisecond}
+	e.POST("/imports", func(c Context) {
+		if _, ok := c.Deadline(); !ok {
+			t.Error("expected a deadline")
+		}
+		var body map[string]string
+		if !c.MustBindJSON(&body) {
+			return
+		}
+		if body["wait"] == "yes" {
+			<-c.Done()
+			return
+		}
+		c.RespondNoContent()
+	}).Budget(budget).Name("imports.create")
+
+	post := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, "/imports", strings.NewReader(body)))
+	}
+	if w := post(`{"a":"b"}`); w.Code != http.StatusNoContent {
+		t.Error("expected request within budget to pass, got", w.Code)
+	}
+	if w := post(`{"name":"` + strings.Repeat("x", 64) + `"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected body size budget to be enforced, got", w.Code)
+	}
+	if w := post(`{"wait":"yes"}`); w.Code != http.StatusServiceUnavailable {
+		t.Error("expected timeout budget to be enforced, got", w.Code)
+	}
+
+	info, ok := e.Route("imports.create")
+	if !ok || info.Budget != budget {
+		t.Error("expected budget in route info, got", info)
+	}
+}
+
+func TestRoute_Budget_Static(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Budget on a static route to panic")
+		}
+	}()
+	New().StaticFS("/assets", fstest.MapFS{}).Budget(RouteBudget{Timeout: time.Second})
+}
+
+func TestRoute_Budget_ReplacedBodies(t *testing.T) {
+	e := New()
+	api := e.Group("/api", Idempotency(IdempotencyConfig{}))
+	api.POST("/echo", echoBody).Budget(RouteBudget{MaxBodySize: 8})
+	post := func(key string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/echo", strings.NewReader(body))
+		r.Header.Set("Idempotency-Key", key)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post("k1", "hello"); w.Code != http.StatusOK || w.Body.String() != "|hello" {
+		t.Error("expected the body read by Idempotency to reach the handler, got", w.Code, w.Body.String())
+	}
+	if w := post("k2", "hello world"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected the budget to limit the body, got", w.Code, w.Body.String())
+	}
+}
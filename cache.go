@@ -0,0 +1,273 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"container/list"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by the Cache middleware.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Stored is the time the response was stored.
+	Stored time.Time
+}
+
+func (r *CachedResponse) size() int64 {
+	size := int64(len(r.Body))
+	for k, values := range r.Header {
+		for _, v := range values {
+			size += int64(len(k) + len(v))
+		}
+	}
+	return size
+}
+
+// CacheStore stores responses. Implement it to share the cache between instances.
+type CacheStore interface {
+	// Get gets a response. Expired responses are not returned.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores a response for the given time.
+	Set(key string, response *CachedResponse, ttl time.Duration)
+	// Delete deletes a response.
+	Delete(key string)
+}
+
+// KeyFunc derives a key from a request.
+type KeyFunc func(c Context) string
+
+// CacheKeyByHeader returns a KeyFunc that varies the cache key by a request header.
+func CacheKeyByHeader(name string) KeyFunc {
+	return func(c Context) string {
+		return name + "=" + c.GetHeader(name)
+	}
+}
+
+// CacheKey returns the default cache key of a request path and raw query.
+func CacheKey(path string, rawQuery string) string {
+	if len(rawQuery) == 0 {
+		return path
+	}
+	return path + "?" + rawQuery
+}
+
+// Cache returns a middleware that caches successful GET responses and serves GET and HEAD requests from the cache.
+// If ttl is zero, the max age of the route's cache policy is used. Responses are keyed by path and query,
+// additional key functions vary the key, e.g. by header. Responses in other formats than JSON, as negotiated from the
+// Accept header, are cached separately and all responses carry Vary: Accept. Responses with Set-Cookie or a private or no-store
+// Cache-Control header are not cached. The X-Cache response header reports HIT or MISS.
+func Cache(store CacheStore, ttl time.Duration, keyFn ...KeyFunc) HandlerFunc {
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		method := gc.Request.Method
+		if method != http.MethodGet && method != http.MethodHead {
+			return
+		}
+		ttl := ttl
+		if ttl <= 0 {
+			policy, ok := c.CachePolicy()
+			if !ok || policy.MaxAge <= 0 {
+				return
+			}
+			ttl = policy.MaxAge
+		}
+		key := CacheKey(gc.Request.URL.Path, gc.Request.URL.RawQuery)
+		for _, f := range keyFn {
+			key += "|" + f(c)
+		}
+		if w, ok := c.(*contextWrapper); ok {
+			key = mediaTypeCacheKey(key, negotiateEncoder(gc.GetHeader("Accept"), w.config.encoders), w.config.encoders)
+		}
+		bypass := strings.Contains(gc.GetHeader("Cache-Control"), "no-cache")
+		if res, ok := store.Get(key); ok && !bypass {
+			serveCached(gc, res)
+			c.Abort()
+			return
+		}
+		w := &captureWriter{ResponseWriter: gc.Writer}
+		gc.Writer = w
+		gc.Header("X-Cache", "MISS")
+		w.Header().Add("Vary", "Accept")
+		c.Next()
+		gc.Writer = w.ResponseWriter
+		if method != http.MethodGet || w.Status() != http.StatusOK || !cacheable(w.Header()) {
+			return
+		}
+		header := w.Header().Clone()
+		header.Del("X-Cache")
+		store.Set(key, &CachedResponse{
+			Status: w.Status(),
+			Header: header,
+			Body:   w.body.Bytes(),
+			Stored: time.Now(),
+		}, ttl)
+	}
+}
+
+// InvalidateCache returns a middleware that deletes cached responses after a request succeeded,
+// e.g. the cached item after an update. Responses cached in other formats than JSON are deleted as well.
+func InvalidateCache(store CacheStore, keys func(c Context) []string) HandlerFunc {
+	return func(c Context) {
+		c.Next()
+		gc, ok := ginContextOf(c)
+		if !ok || gc.Writer.Status() >= 400 {
+			return
+		}
+		for _, key := range keys(c) {
+			store.Delete(key)
+			if w, ok := c.(*contextWrapper); ok {
+				for _, e := range w.config.encoders[1:] {
+					store.Delete(mediaTypeCacheKey(key, e, w.config.encoders))
+				}
+			}
+		}
+	}
+}
+
+// mediaTypeCacheKey varies key by the media type of the response encoder. Keys of the first encoder, JSON by default,
+// are not varied, so they can be built with CacheKey.
+func mediaTypeCacheKey(key string, e encoderEntry, encoders []encoderEntry) string {
+	if e.mediaType == encoders[0].mediaType {
+		return key
+	}
+	return key + "|" + e.mediaType
+}
+
+func cacheable(header http.Header) bool {
+	if len(header.Get("Set-Cookie")) > 0 {
+		return false
+	}
+	cc := header.Get("Cache-Control")
+	return !strings.Contains(cc, "no-store") && !strings.Contains(cc, "private")
+}
+
+func serveCached(c *gin.Context, res *CachedResponse) {
+	header := c.Writer.Header()
+	for k, v := range res.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set("X-Cache", "HIT")
+	header.Set("Age", strconv.Itoa(int(time.Since(res.Stored).Seconds())))
+	c.Status(res.Status)
+	_, _ = c.Writer.Write(res.Body)
+}
+
+// captureWriter keeps a copy of the response body.
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *captureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// MemoryCacheStore is an in-memory LRU cache limited by the total size of the stored responses.
+type MemoryCacheStore struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	entries  map[string]*list.Element
+	lru      *list.List
+}
+
+type memoryCacheEntry struct {
+	key      string
+	response *CachedResponse
+	size     int64
+	expires  time.Time
+}
+
+// NewMemoryCacheStore creates an in-memory cache holding at most maxBytes of responses.
+func NewMemoryCacheStore(maxBytes int64) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryCacheEntry)
+	if time.Now().After(e.expires) {
+		s.remove(el)
+		return nil, false
+	}
+	s.lru.MoveToFront(el)
+	return e.response, true
+}
+
+func (s *MemoryCacheStore) Set(key string, response *CachedResponse, ttl time.Duration) {
+	size := response.size()
+	if size > s.maxBytes {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+	s.entries[key] = s.lru.PushFront(&memoryCacheEntry{
+		key:      key,
+		response: response,
+		size:     size,
+		expires:  time.Now().Add(ttl),
+	})
+	s.size += size
+	for s.size > s.maxBytes {
+		s.remove(s.lru.Back())
+	}
+}
+
+func (s *MemoryCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[key]; ok {
+		s.remove(el)
+	}
+}
+
+// Purge deletes all responses.
+func (s *MemoryCacheStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = make(map[string]*list.Element)
+	s.lru.Init()
+	s.size = 0
+}
+
+func (s *MemoryCacheStore) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*memoryCacheEntry)
+	delete(s.entries, e.key)
+	s.size -= e.size
+}
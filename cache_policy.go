@@ -0,0 +1,100 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const cachePolicyKey = "jug.cachePolicy"
+
+// CachePolicy describes the cacheability of a route's responses.
+type CachePolicy struct {
+	// MaxAge is the time responses may be cached. A MaxAge of zero requires revalidation.
+	MaxAge time.Duration
+	// Public allows shared caches to store responses.
+	Public bool
+}
+
+// String returns the Cache-Control header value of the policy.
+func (p CachePolicy) String() string {
+	if p.MaxAge <= 0 {
+		return "no-cache"
+	}
+	visibility := "private"
+	if p.Public {
+		visibility = "public"
+	}
+	return visibility + ", max-age=" + strconv.Itoa(int(p.MaxAge.Seconds()))
+}
+
+// setCachePolicy declares the cache policy of a route. It applies to GET and HEAD requests only.
+func (cfg *engineConfig) setCachePolicy(route *ginRoute, policy CachePolicy) {
+	if contains(route.methods, http.MethodGet) || contains(route.methods, http.MethodHead) {
+		cfg.cachePolicies[http.MethodGet+" "+route.path] = policy
+		cfg.cachePolicies[http.MethodHead+" "+route.path] = policy
+	}
+}
+
+// applyCachePolicies stores the cache policy of the matched route on the context and
+// sets the Cache-Control header of successful responses, unless the handler set it.
+func applyCachePolicies(config *engineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy, ok := config.cachePolicies[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			return
+		}
+		c.Set(cachePolicyKey, policy)
+		w := &cacheControlWriter{ResponseWriter: c.Writer, value: policy.String()}
+		c.Writer = w
+		c.Next()
+		w.apply()
+	}
+}
+
+// cacheControlWriter sets the Cache-Control header before the headers are written.
+type cacheControlWriter struct {
+	gin.ResponseWriter
+	value   string
+	applied bool
+}
+
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *cacheControlWriter) apply() {
+	if w.applied || w.ResponseWriter.Written() {
+		return
+	}
+	w.applied = true
+	status := w.Status()
+	if status >= 200 && status < 300 && len(w.Header().Get("Cache-Control")) == 0 {
+		w.Header().Set("Cache-Control", w.value)
+	}
+}
+
+func (w *cacheControlWriter) WriteHeaderNow() {
+	w.apply()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *cacheControlWriter) Write(data []byte) (int, error) {
+	w.apply()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cacheControlWriter) WriteString(s string) (int, error) {
+	w.apply()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *cacheControlWriter) Flush() {
+	w.apply()
+	w.ResponseWriter.Flush()
+}
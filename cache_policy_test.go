@@ -0,0 +1,61 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCachePolicy_String(t *testing.T) {
+	tests := []struct {
+		policy   CachePolicy
+		expected string
+	}{
+		{CachePolicy{}, "no-cache"},
+		{CachePolicy{MaxAge: time.Minute}, "private, max-age=60"},
+		{CachePolicy{MaxAge: time.Hour, Public: true}, "public, max-age=3600"},
+	}
+	for _, test := range tests {
+		if actual := test.policy.String(); actual != test.expected {
+			t.Errorf("%+v: expected %s, got %s", test.policy, test.expected, actual)
+		}
+	}
+}
+
+func TestRouter_Cache(t *testing.T) {
+	e := New()
+	api := e.Group("/api")
+	api.GET("/items/:id", func(c Context) {
+		if c.Param("id") == "missing" {
+			c.RespondNotFound("not found")
+			return
+		}
+		policy, _ := c.CachePolicy()
+		c.RespondOk(policy.MaxAge.String())
+	}).Cache(time.Minute, true)
+	api.GET("/custom", func(c Context) {
+		c.SetCacheControl(CachePolicy{})
+		c.RespondNoContent()
+	}).Cache(time.Minute, true)
+	api.GET("/uncached", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	w := serve(e, http.MethodGet, "/api/items/1")
+	if w.Header().Get("Cache-Control") != "public, max-age=60" || w.Body.String() != `"1m0s"` {
+		t.Error("expected route cache policy, got", w.Header().Get("Cache-Control"), w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/api/items/missing"); w.Header().Get("Cache-Control") != "" {
+		t.Error("expected error responses not to be cacheable, got", w.Header().Get("Cache-Control"))
+	}
+	if w := serve(e, http.MethodGet, "/api/custom"); w.Header().Get("Cache-Control") != "no-cache" {
+		t.Error("expected handler to override the route policy, got", w.Header().Get("Cache-Control"))
+	}
+	if w := serve(e, http.MethodGet, "/api/uncached"); w.Header().Get("Cache-Control") != "" {
+		t.Error("expected no cache policy, got", w.Header().Get("Cache-Control"))
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	store := NewMemoryCacheStore(1 << 20)
+	calls := 0
+	e := New()
+	items := e.Group("/items", Cache(store, time.Minute))
+	items.GET("/:id", func(c Context) {
+		calls++
+		c.String(http.StatusOK, "item %s #%d", c.Param("id"), calls)
+	})
+	items.PUT("/:id", InvalidateCache(store, func(c Context) []string {
+		return []string{CacheKey("/items/"+c.Param("id"), "")}
+	}), func(c Context) {
+		c.RespondNoContent()
+	})
+
+	w := serve(e, http.MethodGet, "/items/1")
+	if w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "item 1 #1" {
+		t.Fatal("expected cache miss, got", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	w = serve(e, http.MethodGet, "/items/1")
+	if w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "item 1 #1" {
+		t.Fatal("expected cache hit, got", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/items/1?v=2"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected query to be part of the key, got", w.Header().Get("X-Cache"))
+	}
+
+	serve(e, http.MethodPut, "/items/1")
+	if w := serve(e, http.MethodGet, "/items/1"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected cache to be invalidated, got", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestCache_RoutePolicy(t *testing.T) {
+	store := NewMemoryCacheStore(1 << 20)
+	e := New()
+	e.Use(Cache(store, 0))
+	e.GET("/cached", func(c Context) {
+		c.RespondOk("ok")
+	}).Cache(time.Minute, true)
+	e.GET("/uncached", func(c Context) {
+		c.RespondOk("ok")
+	})
+	serve(e, http.MethodGet, "/cached")
+	if w := serve(e, http.MethodGet, "/cached"); w.Header().Get("X-Cache") != "HIT" || w.Header().Get("Cache-Control") != "public, max-age=60" {
+		t.Fatal("expected route policy to enable caching, got", w.Header())
+	}
+	serve(e, http.MethodGet, "/uncached")
+	if w := serve(e, http.MethodGet, "/uncached"); w.Header().Get("X-Cache") != "" {
+		t.Fatal("expected routes without policy not to be cached, got", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestMemoryCacheStore_Evicts(t *testing.T) {
+	s := NewMemoryCacheStore(10)
+	for i := 0; i < 3; i++ {
+		s.Set(strconv.Itoa(i), &CachedResponse{Body: []byte("abcd")}, time.Minute)
+	}
+	if _, ok := s.Get("0"); ok {
+		t.Fatal("expected least recently used entry to be evicted")
+	}
+	if _, ok := s.Get("2"); !ok {
+		t.Fatal("expected latest entry to be kept")
+	}
+	s.Set("x", &CachedResponse{Body: []byte("x")}, -time.Second)
+	if _, ok := s.Get("x"); ok {
+		t.Fatal("expected expired entry not to be returned")
+	}
+}
+
+func TestCache_Negotiation(t *testing.T) {
+	type item struct {
+		ID string `json:"id" xml:"id"`
+	}
+	store := NewMemoryCacheStore(1 << 20)
+	e := New()
+	items := e.Group("/items", Cache(store, time.Minute))
+	items.GET("/:id", func(c Context) {
+		c.RespondOk(item{ID: c.Param("id")})
+	})
+	items.PUT("/:id", InvalidateCache(store, func(c Context) []string {
+		return []string{CacheKey("/items/"+c.Param("id"), "")}
+	}), func(c Context) {
+		c.RespondNoContent()
+	})
+	get := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("application/json"); w.Header().Get("X-Cache") != "MISS" || w.Header().Get("Vary") != "Accept" {
+		t.Fatal("expected cache miss varying by Accept, got", w.Header())
+	}
+	if w := get("application/xml"); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "<item><id>1</id></item>" {
+		t.Fatal("expected XML to be cached separately, got", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("application/xml"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "<item><id>1</id></item>" {
+		t.Fatal("expected cached XML, got", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("application/json"); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != `{"id":"1"}` {
+		t.Fatal("expected cached JSON, got", w.Header().Get("X-Cache"), w.Body.String())
+	}
+
+	serve(e, http.MethodPut, "/items/1")
+	if w := get("application/xml"); w.Header().Get("X-Cache") != "MISS" {
+		t.Fatal("expected XML to be invalidated, got", w.Header().Get("X-Cache"))
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	// VariantKey is the context key holding the variant that served the request.
+	VariantKey = "jug.variant"
+	// VariantPrimary is the variant name of the primary handler.
+	VariantPrimary = "primary"
+	// VariantCanary is the variant name of the canary handler.
+	VariantCanary = "canary"
+)
+
+// MetricCanaryRequests counts requests served by a variant of a canary route. Labels: method, route, variant.
+const MetricCanaryRequests = "jug_canary_requests_total"
+
+// CanaryConfig configures how requests are split between a primary and a canary handler.
+//
+// Experimental: this API may change.
+type CanaryConfig struct {
+	// Percent is the share of requests (0-100) served by the canary.
+	Percent float64
+	// Header optionally selects the variant by request header.
+	// If the header is present its value decides the variant, overriding Percent.
+	Header string
+	// HeaderValue is the header value selecting the canary. If empty, any non-empty value selects the canary.
+	HeaderValue string
+	// Observer is invoked after each request with the variant that served it and the handler duration.
+	// Use it to record metrics per variant.
+	Observer func(variant string, duration time.Duration)
+}
+
+// Canary returns a handler that routes requests either to primary or to canary.
+// The selected variant is stored on the context under VariantKey. See Route.Canary to split a registered route.
+//
+// Experimental: this API may change.
+func Canary(primary HandlerFunc, canary HandlerFunc, config CanaryConfig) HandlerFunc {
+	split := newCanarySplit(canary, config)
+	return func(c Context) {
+		split.serve(c, primary)
+	}
+}
+
+// canarySplit splits requests between a primary handler and a canary.
+type canarySplit struct {
+	canary HandlerFunc
+	config CanaryConfig
+	// random returns a number in [0, 1). Tests replace it with a deterministic source.
+	random func() float64
+}
+
+func newCanarySplit(canary HandlerFunc, config CanaryConfig) *canarySplit {
+	return &canarySplit{canary: canary, config: config, random: rand.Float64}
+}
+
+func (s *canarySplit) serve(c Context, primary HandlerFunc) {
+	variant := s.selectVariant(c)
+	c.Set(VariantKey, variant)
+	start := time.Now()
+	if variant == VariantCanary {
+		s.canary(c)
+	} else {
+		primary(c)
+	}
+	if s.config.Observer != nil {
+		s.config.Observer(variant, time.Since(start))
+	}
+	if w, ok := c.(*contextWrapper); ok && w.config.metrics != nil {
+		w.config.metrics.IncCounter(MetricCanaryRequests, map[string]string{
+			"method":  w.c.Request.Method,
+			"route":   w.c.FullPath(),
+			"variant": variant,
+		})
+	}
+}
+
+func (s *canarySplit) selectVariant(c Context) string {
+	if len(s.config.Header) > 0 {
+		if v := c.GetHeader(s.config.Header); len(v) > 0 {
+			if len(s.config.HeaderValue) == 0 || v == s.config.HeaderValue {
+				return VariantCanary
+			}
+			return VariantPrimary
+		}
+	}
+	if s.random()*100 < s.config.Percent {
+		return VariantCanary
+	}
+	return VariantPrimary
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// sequence returns a random source yielding the given values in turn.
+func sequence(values ...float64) func() float64 {
+	i := 0
+	return func() float64 {
+		v := values[i%len(values)]
+		i++
+		return v
+	}
+}
+
+func TestRoute_Canary(t *testing.T) {
+	counters := make(map[string]int)
+	e := New()
+	e.SetMetrics(MetricsFunc(func(name string, labels map[string]string) {
+		counters[name+" "+labels["method"]+" "+labels["route"]+" "+labels["variant"]]++
+	}))
+	middleware := 0
+	route := e.GET("/items/:id", func(c Context) {
+		middleware++
+	}, func(c Context) {
+		c.String(http.StatusOK, "old")
+	}).Canary(func(c Context) {
+		c.String(http.StatusOK, "new")
+	}, 5)
+	route.(*ginRoutesRouter).route.canary.random = sequence(0.01, 0.04, 0.05, 0.5, 0.99)
+
+	bodies := make(map[string]int)
+	for i := 0; i < 5; i++ {
+		bodies[serve(e, http.MethodGet, "/items/1").Body.String()]++
+	}
+	if bodies["new"] != 2 || bodies["old"] != 3 {
+		t.Error("expected values below 0.05 to select the canary, got", bodies)
+	}
+	if middleware != 5 {
+		t.Error("expected route middleware to run for both variants, got", middleware)
+	}
+	if counters[MetricCanaryRequests+" GET /items/:id canary"] != 2 || counters[MetricCanaryRequests+" GET /items/:id primary"] != 3 {
+		t.Error("expected requests counted per variant, got", counters)
+	}
+}
+
+func TestRoute_CanaryWith(t *testing.T) {
+	observed := make(map[string]int)
+	e := New()
+	e.GET("/", func(c Context) {
+		c.String(http.StatusOK, "old")
+	}).CanaryWith(func(c Context) {
+		c.String(http.StatusOK, "new")
+	}, CanaryConfig{
+		Header:      "X-Canary",
+		HeaderValue: "always",
+		Observer: func(variant string, duration time.Duration) {
+			observed[variant]++
+		},
+	})
+
+	requests := map[string]string{"always": "new", "never": "old", "": "old"}
+	for header, expected := range requests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if len(header) > 0 {
+			r.Header.Set("X-Canary", header)
+		}
+		w := serveRequest(e, r)
+		if w.Body.String() != expected {
+			t.Errorf("expected %s for header %q, got %s", expected, header, w.Body.String())
+		}
+	}
+	if observed[VariantCanary] != 1 || observed[VariantPrimary] != 2 {
+		t.Error("expected the observer to be called with the variant, got", observed)
+	}
+}
+
+func TestRoute_Canary_Static(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Canary to panic for static routes")
+		}
+	}()
+	New().Static("/assets", ".").Canary(func(c Context) {}, 5)
+}
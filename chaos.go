@@ -0,0 +1,72 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// ChaosConfig configures the faults injected by the Chaos middleware.
+// Each fault is injected independently into the given share of requests.
+//
+// Experimental: this API may change.
+type ChaosConfig struct {
+	// Enabled must be set for any fault to be injected. Wire it to an explicit flag, never enable it by default.
+	Enabled bool
+	// LatencyPercent is the share of requests (0-100) that are delayed.
+	LatencyPercent float64
+	// MinLatency is the minimum added latency.
+	MinLatency time.Duration
+	// MaxLatency is the maximum added latency.
+	MaxLatency time.Duration
+	// ErrorPercent is the share of requests (0-100) that are answered with ErrorStatus.
+	ErrorPercent float64
+	// ErrorStatus is the status of injected errors. Defaults to 503.
+	ErrorStatus int
+	// DropPercent is the share of requests (0-100) whose connection is dropped without a response.
+	DropPercent float64
+}
+
+// Chaos returns a middleware that injects latency, errors and dropped connections.
+// Apply it to the routes or groups whose clients should be tested. If the config is not enabled, it does nothing.
+//
+// Experimental: this API may change.
+func Chaos(config ChaosConfig) HandlerFunc {
+	if config.ErrorStatus == 0 {
+		config.ErrorStatus = http.StatusServiceUnavailable
+	}
+	return func(c Context) {
+		if !config.Enabled {
+			return
+		}
+		if chance(config.LatencyPercent) && config.MaxLatency > 0 {
+			delay := config.MinLatency
+			if spread := config.MaxLatency - config.MinLatency; spread > 0 {
+				delay += time.Duration(rand.Int63n(int64(spread)))
+			}
+			select {
+			case <-time.After(delay):
+			case <-c.Done():
+				c.Abort()
+				return
+			}
+		}
+		if chance(config.DropPercent) {
+			// net/http closes the connection without a response, the panic is not logged
+			panic(http.ErrAbortHandler)
+		}
+		if chance(config.ErrorPercent) {
+			c.HandleError(NewResponseStatusError(config.ErrorStatus, fmt.Sprintf("chaos: injected %d", config.ErrorStatus)))
+			c.Abort()
+		}
+	}
+}
+
+func chance(percent float64) bool {
+	return percent > 0 && rand.Float64()*100 < percent
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func chaosTestEngine(config ChaosConfig) Engine {
+	e := New()
+	e.GET("/", Chaos(config), func(c Context) {
+		c.RespondNoContent()
+	})
+	return e
+}
+
+func TestChaos_Disabled(t *testing.T) {
+	e := chaosTestEngine(ChaosConfig{ErrorPercent: 100, DropPercent: 100})
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusNoContent {
+		t.Fatal("expected no faults when disabled, got", w.Code)
+	}
+}
+
+func TestChaos_Error(t *testing.T) {
+	e := chaosTestEngine(ChaosConfig{Enabled: true, ErrorPercent: 100, ErrorStatus: http.StatusBadGateway})
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusBadGateway {
+		t.Fatal("expected injected error, got", w.Code)
+	}
+}
+
+func TestChaos_Latency(t *testing.T) {
+	e := chaosTestEngine(ChaosConfig{Enabled: true, LatencyPercent: 100, MinLatency: 20 * time.Millisecond, MaxLatency: 30 * time.Millisecond})
+	start := time.Now()
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusNoContent {
+		t.Fatal("expected request to pass, got", w.Code)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("expected latency to be injected")
+	}
+}
+
+func TestChaos_Drop(t *testing.T) {
+	e := chaosTestEngine(ChaosConfig{Enabled: true, DropPercent: 100})
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Fatal("expected handler to be aborted, got", recovered)
+		}
+	}()
+	serve(e, http.MethodGet, "/")
+}
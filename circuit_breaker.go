@@ -0,0 +1,200 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects all requests.
+	CircuitOpen
+	// CircuitHalfOpen lets a single trial request through.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures the CircuitBreaker middleware.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit. Defaults to 5.
+	FailureThreshold int
+	// Cooldown is the time the circuit stays open before a trial request is let through. Defaults to 30 seconds.
+	Cooldown time.Duration
+	// SuccessThreshold is the number of successful trial requests closing a half open circuit. Defaults to 1.
+	SuccessThreshold int
+	// IsFailure reports whether a finished request counts as failure.
+	// Defaults to responses with status 5xx and requests that exceeded their deadline.
+	IsFailure func(c Context, status int) bool
+	// OnStateChange is called when the circuit of a route changes its state. The route is the method followed by the path.
+	OnStateChange func(route string, from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker returns a middleware that tracks failures of subsequent handlers per route.
+// After FailureThreshold consecutive failures, the circuit opens and requests are answered with 503 and Retry-After
+// without calling the handlers. After Cooldown, a trial request is let through. If it succeeds, the circuit closes,
+// otherwise it opens again. State changes and rejections are counted by the metrics of the engine.
+// Panics in subsequent handlers count as failures.
+func CircuitBreaker(cfg CircuitBreakerConfig) HandlerFunc {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = defaultCircuitFailure
+	}
+	var mu sync.Mutex
+	circuits := make(map[string]*circuit)
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		route := gc.Request.Method + " " + gc.FullPath()
+		mu.Lock()
+		cb, ok := circuits[route]
+		if !ok {
+			cb = &circuit{}
+			circuits[route] = cb
+		}
+		mu.Unlock()
+
+		now := time.Now()
+		allowed, retryAfter := cb.allow(&cfg, route, now, circuitEmitter(c))
+		if !allowed {
+			incCircuitCounter(c, MetricCircuitBreakerRejections, "")
+			c.HandleError(NewServiceUnavailableError("service unavailable").WithRetryAfter(retryAfter))
+			c.Abort()
+			return
+		}
+		failed := true
+		defer func() {
+			cb.record(&cfg, route, failed, time.Now(), circuitEmitter(c))
+		}()
+		c.Next()
+		failed = cfg.IsFailure(c, gc.Writer.Status())
+	}
+}
+
+func defaultCircuitFailure(c Context, status int) bool {
+	return status >= http.StatusInternalServerError || c.Err() == context.DeadlineExceeded
+}
+
+// circuit is the circuit breaker state of a single route.
+type circuit struct {
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	successes int
+	openedAt  time.Time
+	trial     bool
+}
+
+// allow reports whether a request may pass. Rejected requests get the time until the next trial.
+func (cb *circuit) allow(cfg *CircuitBreakerConfig, route string, now time.Time, emit func(route string, from CircuitState, to CircuitState)) (bool, time.Duration) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitOpen:
+		if elapsed := now.Sub(cb.openedAt); elapsed < cfg.Cooldown {
+			return false, cfg.Cooldown - elapsed
+		}
+		cb.transition(cfg, route, CircuitHalfOpen, now, emit)
+		cb.trial = true
+		return true, 0
+	case CircuitHalfOpen:
+		if cb.trial {
+			return false, time.Second
+		}
+		cb.trial = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// record records the outcome of a request that was let through.
+func (cb *circuit) record(cfg *CircuitBreakerConfig, route string, failed bool, now time.Time, emit func(route string, from CircuitState, to CircuitState)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitHalfOpen {
+		cb.trial = false
+		if failed {
+			cb.transition(cfg, route, CircuitOpen, now, emit)
+			return
+		}
+		cb.successes++
+		if cb.successes >= cfg.SuccessThreshold {
+			cb.transition(cfg, route, CircuitClosed, now, emit)
+		}
+		return
+	}
+	if !failed {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.state == CircuitClosed && cb.failures >= cfg.FailureThreshold {
+		cb.transition(cfg, route, CircuitOpen, now, emit)
+	}
+}
+
+func (cb *circuit) transition(cfg *CircuitBreakerConfig, route string, to CircuitState, now time.Time, emit func(route string, from CircuitState, to CircuitState)) {
+	from := cb.state
+	cb.state = to
+	cb.failures = 0
+	cb.successes = 0
+	if to == CircuitOpen {
+		cb.openedAt = now
+	}
+	emit(route, from, to)
+	if cfg.OnStateChange != nil {
+		cfg.OnStateChange(route, from, to)
+	}
+}
+
+// circuitEmitter returns a function counting state changes with the metrics of the engine.
+func circuitEmitter(c Context) func(route string, from CircuitState, to CircuitState) {
+	return func(route string, from CircuitState, to CircuitState) {
+		incCircuitCounter(c, MetricCircuitBreakerTransitions, to.String())
+	}
+}
+
+func incCircuitCounter(c Context, name string, state string) {
+	w, ok := c.(*contextWrapper)
+	if !ok || w.config.metrics == nil {
+		return
+	}
+	labels := map[string]string{
+		"method": w.c.Request.Method,
+		"route":  w.c.FullPath(),
+	}
+	if len(state) > 0 {
+		labels["state"] = state
+	}
+	w.config.metrics.IncCounter(name, labels)
+}
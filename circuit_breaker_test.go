@@ -0,0 +1,110 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var mu sync.Mutex
+	transitions := make([]string, 0)
+	rejections := 0
+	fail := true
+	calls := 0
+	e := New()
+	e.SetMetrics(MetricsFunc(func(name string, labels map[string]string) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch name {
+		case MetricCircuitBreakerTransitions:
+			transitions = append(transitions, labels["route"]+" "+labels["state"])
+		case MetricCircuitBreakerRejections:
+			rejections++
+		}
+	}))
+	e.GET("/upstream/:id", CircuitBreaker(CircuitBreakerConfig{FailureThreshold: 2, Cooldown: 50 * time.Millisecond}), func(c Context) {
+		calls++
+		if fail {
+			c.RespondServiceUnavailable(nil)
+			return
+		}
+		c.RespondNoContent()
+	})
+
+	for i := 0; i < 2; i++ {
+		if w := serve(e, http.MethodGet, "/upstream/1"); w.Code != http.StatusServiceUnavailable {
+			t.Fatal("expected failing handler to respond with 503, got", w.Code)
+		}
+	}
+	w := serve(e, http.MethodGet, "/upstream/2")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" || calls != 2 {
+		t.Fatal("expected open circuit to reject the request", w.Code, w.Header().Get("Retry-After"), calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	fail = false
+	if w := serve(e, http.MethodGet, "/upstream/3"); w.Code != http.StatusNoContent {
+		t.Fatal("expected trial request to pass, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/upstream/4"); w.Code != http.StatusNoContent {
+		t.Fatal("expected closed circuit to pass, got", w.Code)
+	}
+
+	expected := []string{"/upstream/:id open", "/upstream/:id half_open", "/upstream/:id closed"}
+	if len(transitions) != len(expected) || rejections != 1 {
+		t.Fatal("unexpected metrics", transitions, rejections)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Fatal("unexpected transitions", transitions)
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailure(t *testing.T) {
+	var states []CircuitState
+	e := New()
+	e.GET("/upstream", CircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 1,
+		Cooldown:         10 * time.Millisecond,
+		OnStateChange: func(route string, from CircuitState, to CircuitState) {
+			if route != "GET /upstream" {
+				t.Error("unexpected route", route)
+			}
+			states = append(states, to)
+		},
+	}), func(c Context) {
+		c.RespondInternalServerError(nil)
+	})
+
+	serve(e, http.MethodGet, "/upstream")
+	time.Sleep(20 * time.Millisecond)
+	serve(e, http.MethodGet, "/upstream")
+	if w := serve(e, http.MethodGet, "/upstream"); w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected reopened circuit to reject, got", w.Code)
+	}
+	if len(states) != 3 || states[0] != CircuitOpen || states[1] != CircuitHalfOpen || states[2] != CircuitOpen {
+		t.Fatal("unexpected states", states)
+	}
+}
+
+func TestCircuitBreaker_SeparatesRoutes(t *testing.T) {
+	e := New()
+	cb := CircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1})
+	e.GET("/broken", cb, func(c Context) { c.RespondInternalServerError(nil) })
+	e.GET("/healthy", cb, func(c Context) { c.RespondNoContent() })
+
+	serve(e, http.MethodGet, "/broken")
+	if w := serve(e, http.MethodGet, "/broken"); w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected broken route to be open, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/healthy"); w.Code != http.StatusNoContent {
+		t.Fatal("expected healthy route to pass, got", w.Code)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientIPStrategy determines the header used to resolve the client IP behind trusted proxies.
+type ClientIPStrategy int
+
+const (
+	// ClientIPXForwardedFor uses the X-Forwarded-For header. Addresses are checked from right to left,
+	// the first address that is not a trusted proxy is the client.
+	ClientIPXForwardedFor ClientIPStrategy = iota
+	// ClientIPXRealIP uses the X-Real-IP header.
+	ClientIPXRealIP
+	// ClientIPCFConnectingIP uses the CF-Connecting-IP header set by Cloudflare.
+	ClientIPCFConnectingIP
+	// ClientIPForwarded uses the for parameters of the RFC 7239 Forwarded header like X-Forwarded-For.
+	ClientIPForwarded
+)
+
+// clientIP resolves the client IP of a request. Without trusted proxies the peer address is used.
+func (cfg *engineConfig) clientIP(c *gin.Context) string {
+	if ip := resolveClientIP(c.Request, cfg.trustedProxies, cfg.clientIPStrategy); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// resolveClientIP returns the peer address, or the address reported by the strategy's header if the peer is a trusted proxy.
+func resolveClientIP(r *http.Request, trusted []*net.IPNet, strategy ClientIPStrategy) net.IP {
+	peer := remoteIP(r)
+	if peer == nil || !containsIP(trusted, peer) {
+		return peer
+	}
+	switch strategy {
+	case ClientIPXRealIP:
+		return headerIP(r, "X-Real-IP", peer)
+	case ClientIPCFConnectingIP:
+		return headerIP(r, "CF-Connecting-IP", peer)
+	case ClientIPForwarded:
+		return rightmostUntrusted(forwardedFor(r.Header.Values("Forwarded")), trusted, peer)
+	default:
+		return forwardedClientIP(r, trusted)
+	}
+}
+
+func headerIP(r *http.Request, header string, peer net.IP) net.IP {
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get(header))); ip != nil {
+		return ip
+	}
+	return peer
+}
+
+// rightmostUntrusted returns the first hop from the right that is not a trusted proxy.
+// Invalid hops end the search at the last valid hop.
+func rightmostUntrusted(hops []string, trusted []*net.IPNet, peer net.IP) net.IP {
+	ip := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(hops[i])
+		if hop == nil {
+			return ip
+		}
+		ip = hop
+		if !containsIP(trusted, hop) {
+			return hop
+		}
+	}
+	return ip
+}
+
+// forwardedFor returns the for parameters of Forwarded header values in order, without quotes and ports.
+func forwardedFor(values []string) []string {
+	hops := make([]string, 0)
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			for _, pair := range strings.Split(element, ";") {
+				key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(key, "for") {
+					continue
+				}
+				hops = append(hops, forwardedNode(val))
+			}
+		}
+	}
+	return hops
+}
+
+// forwardedNode strips quotes, brackets and ports from a node, e.g. "[2001:db8::1]:4711".
+func forwardedNode(node string) string {
+	node = strings.Trim(node, `"`)
+	if strings.HasPrefix(node, "[") {
+		if end := strings.Index(node, "]"); end > 0 {
+			return node[1:end]
+		}
+		return node
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return node
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEngine_SetTrustedProxies(t *testing.T) {
+	tests := []struct {
+		strategy   ClientIPStrategy
+		remoteAddr string
+		header     string
+		value      string
+		expected   string
+	}{
+		{ClientIPXForwardedFor, "10.0.0.1:1234", "X-Forwarded-For", "203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{ClientIPXForwardedFor, "198.51.100.1:1234", "X-Forwarded-For", "203.0.113.7", "198.51.100.1"},
+		{ClientIPXRealIP, "10.0.0.1:1234", "X-Real-IP", "203.0.113.7", "203.0.113.7"},
+		{ClientIPXRealIP, "10.0.0.1:1234", "X-Real-IP", "invalid", "10.0.0.1"},
+		{ClientIPCFConnectingIP, "10.0.0.1:1234", "CF-Connecting-IP", "2001:db8::7", "2001:db8::7"},
+		{ClientIPForwarded, "10.0.0.1:1234", "Forwarded", `for=203.0.113.7;proto=https, for="[2001:db8::1]:4711", for=10.0.0.2`, "2001:db8::1"},
+		{ClientIPForwarded, "10.0.0.1:1234", "Forwarded", `for=unknown`, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		e := New()
+		if err := e.SetTrustedProxies([]string{"10.0.0.0/8"}); err != nil {
+			t.Fatal(err)
+		}
+		e.SetClientIPStrategy(tt.strategy)
+		e.GET("/", func(c Context) {
+			c.String(http.StatusOK, c.ClientIP())
+		})
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		r.Header.Set(tt.header, tt.value)
+		w := serveRequest(e, r)
+		if w.Body.String() != tt.expected {
+			t.Errorf("expected %s for %s: %s, got %s", tt.expected, tt.header, tt.value, w.Body.String())
+		}
+	}
+}
+
+func TestEngine_SetTrustedProxies_Invalid(t *testing.T) {
+	if err := New().SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Fatal("expected invalid CIDR to fail")
+	}
+}
+
+func TestForwardedFor(t *testing.T) {
+	hops := forwardedFor([]string{`for=192.0.2.60;proto=http;by=203.0.113.43`, `For="[2001:db8:cafe::17]:4711", for=198.51.100.17:80`})
+	expected := []string{"192.0.2.60", "2001:db8:cafe::17", "198.51.100.17"}
+	if !reflect.DeepEqual(hops, expected) {
+		t.Fatalf("expected %v, got %v", expected, hops)
+	}
+}
+
+func TestEngine_ClientIP_IgnoresHeadersWithoutTrustedProxies(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		c.String(http.StatusOK, c.ClientIP())
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "198.51.100.1:1234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.7")
+	r.Header.Set("X-Real-IP", "203.0.113.8")
+	w := serveRequest(e, r)
+	if w.Body.String() != "198.51.100.1" {
+		t.Error("expected the peer address for spoofed headers, got", w.Body.String())
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimit returns a middleware that allows at most n simultaneous executions of subsequent handlers.
+// Up to queue requests wait for a free slot for at most timeout. Requests exceeding the queue, waiting longer than timeout
+// or disconnecting while waiting are answered with 503 and a Retry-After header.
+//
+// The limit is shared by all routes the middleware is registered on. Create a middleware per route for separate limits.
+func ConcurrencyLimit(n int, queue int, timeout time.Duration) HandlerFunc {
+	if n <= 0 {
+		panic("jug: concurrency limit must be positive")
+	}
+	slots := make(chan struct{}, n)
+	var waiting int64
+	retryAfter := timeout
+	if retryAfter < time.Second {
+		retryAfter = time.Second
+	}
+	reject := func(c Context) {
+		c.HandleError(NewServiceUnavailableError("too many concurrent requests").WithRetryAfter(retryAfter))
+		c.Abort()
+	}
+	return func(c Context) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			if atomic.AddInt64(&waiting, 1) > int64(queue) {
+				atomic.AddInt64(&waiting, -1)
+				reject(c)
+				return
+			}
+			acquired := acquireSlot(c, slots, timeout)
+			atomic.AddInt64(&waiting, -1)
+			if !acquired {
+				reject(c)
+				return
+			}
+		}
+		defer func() {
+			<-slots
+		}()
+		c.Next()
+	}
+}
+
+// acquireSlot waits for a free slot until timeout passes or the request is done.
+func acquireSlot(c Context, slots chan struct{}, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-c.Done():
+		return false
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimit(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	e := New()
+	e.GET("/report", ConcurrencyLimit(1, 1, 200*time.Millisecond), func(c Context) {
+		started <- struct{}{}
+		<-release
+		c.RespondNoContent()
+	})
+
+	results := make([]*httptest.ResponseRecorder, 2)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = serve(e, http.MethodGet, "/report")
+		}(i)
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	w := serve(e, http.MethodGet, "/report")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatal("expected saturated limit to reject with 503, got", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	wg.Wait()
+	for i, w := range results {
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected request %d to run, got %d", i, w.Code)
+		}
+	}
+}
+
+func TestConcurrencyLimit_QueueTimeout(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	e := New()
+	e.GET("/report", ConcurrencyLimit(1, 5, 20*time.Millisecond), func(c Context) {
+		close(started)
+		<-release
+		c.RespondNoContent()
+	})
+	go serve(e, http.MethodGet, "/report")
+	<-started
+	defer close(release)
+
+	if w := serve(e, http.MethodGet, "/report"); w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected waiting request to time out, got", w.Code)
+	}
+}
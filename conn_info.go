@@ -0,0 +1,75 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+)
+
+// ConnInfo describes the connection a request arrived on.
+type ConnInfo struct {
+	// LocalAddr is the address the request was received on. It is empty if unknown.
+	LocalAddr string
+	// RemoteAddr is the address of the peer, which may be a proxy.
+	RemoteAddr string
+	// Proto is the protocol of the request, e.g. "HTTP/1.1", "HTTP/2.0" or "HTTP/3.0".
+	Proto string
+	// ProtoMajor is the major protocol version, i.e. 1, 2 or 3.
+	ProtoMajor int
+	// TLS describes the TLS connection. It is nil for plain text connections.
+	TLS *TLSInfo
+}
+
+// TLSInfo describes the TLS state of a connection.
+type TLSInfo struct {
+	// Version is the TLS version, e.g. "TLS 1.3".
+	Version string
+	// CipherSuite is the name of the negotiated cipher suite.
+	CipherSuite string
+	// ServerName is the server name sent by the client (SNI).
+	ServerName string
+	// NegotiatedProtocol is the protocol negotiated with ALPN, e.g. "h2".
+	NegotiatedProtocol string
+	// HandshakeComplete reports whether the handshake has been completed.
+	HandshakeComplete bool
+}
+
+func connInfo(r *http.Request) ConnInfo {
+	info := ConnInfo{
+		RemoteAddr: r.RemoteAddr,
+		Proto:      r.Proto,
+		ProtoMajor: r.ProtoMajor,
+	}
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		info.LocalAddr = addr.String()
+	}
+	if r.TLS != nil {
+		info.TLS = &TLSInfo{
+			Version:            tlsVersionName(r.TLS.Version),
+			CipherSuite:        tls.CipherSuiteName(r.TLS.CipherSuite),
+			ServerName:         r.TLS.ServerName,
+			NegotiatedProtocol: r.TLS.NegotiatedProtocol,
+			HandshakeComplete:  r.TLS.HandshakeComplete,
+		}
+	}
+	return info
+}
+
+func tlsVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,31 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnInfo(t *testing.T) {
+	r := httptest.NewRequest("GET", "https://example.com/", nil)
+	r.TLS.Version = tls.VersionTLS13
+	r.TLS.CipherSuite = tls.TLS_AES_128_GCM_SHA256
+	r.TLS.NegotiatedProtocol = "h2"
+	info := connInfo(r)
+	if info.ProtoMajor != 1 || info.Proto != "HTTP/1.1" {
+		t.Fatalf("unexpected protocol %s (%d)", info.Proto, info.ProtoMajor)
+	}
+	if info.RemoteAddr != "192.0.2.1:1234" {
+		t.Fatal("unexpected remote address", info.RemoteAddr)
+	}
+	if info.TLS == nil || info.TLS.Version != "TLS 1.3" || info.TLS.CipherSuite != "TLS_AES_128_GCM_SHA256" || info.TLS.NegotiatedProtocol != "h2" {
+		t.Fatalf("unexpected tls info %+v", info.TLS)
+	}
+	if connInfo(httptest.NewRequest("GET", "/", nil)).TLS != nil {
+		t.Fatal("expected no tls info for plain text requests")
+	}
+}
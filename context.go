@@ -5,7 +5,15 @@
 package jug
 
 import (
+	"bufio"
+	"context"
+	"google.golang.org/protobuf/proto"
 	"io"
+	"io/fs"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -23,29 +31,75 @@ type Context interface {
 	QueryArray(key string) []string
 	// IntQuery gets a query value as int
 	IntQuery(key string) (int, error)
+	// Int64Query gets a query value as int64
+	Int64Query(key string) (int64, error)
+	// UIntQuery gets a query value as uint
+	UIntQuery(key string) (uint, error)
+	// UInt64Query gets a query value as uint64
+	UInt64Query(key string) (uint64, error)
+	// Float64Query gets a query value as float64
+	Float64Query(key string) (float64, error)
+	// DurationQuery gets a query value as duration, e.g. 90s or 1h30m
+	DurationQuery(key string) (time.Duration, error)
 	// BoolQuery gets a query value as bool
 	BoolQuery(key string) (bool, error)
-	// Iso8601DateQuery gets a query value as ISO 8601 Date
+	// Iso8601DateQuery gets a query value as ISO 8601 Date in the location configured on the engine
 	Iso8601DateQuery(key string) (*time.Time, error)
+	// Iso8601DateQueryIn gets a query value as ISO 8601 Date in the given location
+	Iso8601DateQueryIn(key string, loc *time.Location) (*time.Time, error)
 	// Iso8601DateTimeQuery gets a query values as ISO 8601 DateTime
 	Iso8601DateTimeQuery(key string) (*time.Time, error)
+	// PeriodQuery gets a query value as calendar period, e.g. 2024, 2024-Q2, 2024-03, 2024-W07 or 2024-03-15.
+	// The period boundaries are midnight in the location configured on the engine.
+	PeriodQuery(key string) (*Period, error)
 	// StringQuery gets a query value as string. This method performs unescaping.
 	StringQuery(key string) (string, error)
+	// Pagination parses the page and size or the limit and offset query parameters. The size is capped at the maximum.
+	// Invalid values return a ResponseStatusError with status 400.
+	Pagination(defaults PaginationDefaults) (Page, error)
 	// DefaultQuery gets a query value. If the value cannot be found a default value is returned.
 	DefaultQuery(key string, defaultValue string) string
 	// DefaultIntQuery gets a query value as int. If the value cannot be found a default value is returned.
 	DefaultIntQuery(key string, defaultValue int) (int, error)
+	// DefaultInt64Query gets a query value as int64. If the value cannot be found a default value is returned.
+	DefaultInt64Query(key string, defaultValue int64) (int64, error)
+	// DefaultUIntQuery gets a query value as uint. If the value cannot be found a default value is returned.
+	DefaultUIntQuery(key string, defaultValue uint) (uint, error)
+	// DefaultUInt64Query gets a query value as uint64. If the value cannot be found a default value is returned.
+	DefaultUInt64Query(key string, defaultValue uint64) (uint64, error)
+	// DefaultFloat64Query gets a query value as float64. If the value cannot be found a default value is returned.
+	DefaultFloat64Query(key string, defaultValue float64) (float64, error)
+	// DefaultDurationQuery gets a query value as duration. If the value cannot be found a default value is returned.
+	DefaultDurationQuery(key string, defaultValue time.Duration) (time.Duration, error)
 	// DefaultBoolQuery gets a query value as bool. If the value cannot be found a default value is returned.
 	DefaultBoolQuery(key string, defaultValue bool) (bool, error)
 	// DefaultStringQuery gets a query value as string. If the value cannot be found a default value is returned.
 	DefaultStringQuery(key string, defaultValue string) (string, error)
 	// GetHeader gets a request header
 	GetHeader(key string) string
+	// ClientIP returns the IP address of the client.
+	ClientIP() string
+	// BasicAuth returns the user name and password of the HTTP basic authentication header.
+	BasicAuth() (user string, pass string, ok bool)
+	// ConnInfo returns information about the connection the request arrived on.
+	ConnInfo() ConnInfo
+	// Claims returns the claims of the token validated by the JWT middleware.
+	Claims() (Claims, bool)
 
 	// Param gets a request param (aka path parameter)
 	Param(key string) string
-
-	//TODO: ParamAsInt
+	// IntParam gets a path parameter as int
+	IntParam(key string) (int, error)
+	// Int64Param gets a path parameter as int64
+	Int64Param(key string) (int64, error)
+	// UUIDParam gets a path parameter as UUID in lower case
+	UUIDParam(key string) (string, error)
+	// MustIntParam gets a path parameter as int. If the value is invalid, responds with 400 and aborts the request.
+	MustIntParam(key string) (int, bool)
+	// MustInt64Param gets a path parameter as int64. If the value is invalid, responds with 400 and aborts the request.
+	MustInt64Param(key string) (int64, bool)
+	// MustUUIDParam gets a path parameter as UUID. If the value is invalid, responds with 400 and aborts the request.
+	MustUUIDParam(key string) (string, bool)
 
 	// GetRawData gets the raw request body
 	GetRawData() ([]byte, error)
@@ -55,9 +109,51 @@ type Context interface {
 	MayBindJSONV(obj any, validator func() error) bool
 	// MustBindJSON tries to bind the request body from JSON to the given object. If that fails the request is aborted with 400.
 	MustBindJSON(obj any) bool
+	// MustBindJSONStrict binds the request body from JSON like MustBindJSON, but rejects keys not matching a field
+	// of obj, e.g. typos. All unknown keys are listed in the 400 response.
+	MustBindJSONStrict(obj any) bool
 	// MustBindJSONV tries to bind the request body from JSON to the given object. If that fails the request is aborted with 400.
 	// If it succeeds the provided validator function is invoked.
 	MustBindJSONV(obj any, validator func() error) bool
+	// BindNDJSONEach reads a newline delimited JSON request body and calls fn for each non-empty line.
+	// Decoded values are validated like other request bodies. Lines that cannot be decoded or are invalid are answered with 400,
+	// other errors returned by fn are passed to HandleError. Returns whether all lines were processed.
+	BindNDJSONEach(fn func(dec Decoder) error) bool
+	// BindCSV binds a CSV request body with a header row to dest, a pointer to a slice of structs.
+	// Columns are matched by the csv tag of the fields, each record is validated.
+	// If that fails the request is aborted with 400. Returns whether the body was bound.
+	BindCSV(dest any) bool
+	// MayBindXML tries to bind the request body from XML to the given object.
+	MayBindXML(obj any) bool
+	// MustBindXML tries to bind the request body from XML to the given object. If that fails the request is aborted with 400.
+	MustBindXML(obj any) bool
+	// MustBindProto tries to bind the request body from Protocol Buffers to the given message. If that fails the request is aborted with 400.
+	MustBindProto(msg proto.Message) bool
+	// MustBindMsgPack tries to bind the request body from MessagePack to the given object. If that fails the request is aborted with 400.
+	MustBindMsgPack(obj any) bool
+	// MustBindBody tries to bind the request body to the given object, selecting JSON, XML, YAML, MessagePack or Protocol Buffers
+	// by the Content-Type header. Bodies without content type are bound from JSON, unsupported content types are answered with 415.
+	// If binding fails the request is aborted with 400.
+	MustBindBody(obj any) bool
+	// MayBindYAML tries to bind the request body from YAML to the given object.
+	MayBindYAML(obj any) bool
+	// MustBindYAML tries to bind the request body from YAML to the given object. If that fails the request is aborted with 400.
+	MustBindYAML(obj any) bool
+	// MayBindQuery tries to bind the query parameters to the given object using `query` struct tags.
+	// If the request has no query parameters, nothing is bound.
+	MayBindQuery(obj any) bool
+	// MustBindQuery binds the query parameters to the given object using `query` struct tags.
+	// If that fails the request is aborted with 400.
+	MustBindQuery(obj any) bool
+	// MustBindForm binds the form values (url encoded or multipart) to the given object using `form` struct tags.
+	// If that fails the request is aborted with 400.
+	MustBindForm(obj any) bool
+	// FormValue gets a form value from an url encoded or multipart form.
+	FormValue(key string) string
+	// FormFile gets the first file for the given form key.
+	FormFile(name string) (*multipart.FileHeader, error)
+	// SaveUploadedFile saves an uploaded file to the given destination.
+	SaveUploadedFile(file *multipart.FileHeader, dst string) error
 
 	// Status sets the response status code.
 	Status(code int) Context
@@ -66,29 +162,145 @@ type Context interface {
 
 	// SetHeader sets a response header.
 	SetHeader(key string, value string)
+	// OnResponse registers a hook called before the response headers are written. Hooks may modify the status
+	// and the headers, e.g. to sign responses. Hooks run in order of registration.
+	OnResponse(hook func(r *ResponseInfo))
+	// BufferResponse holds back the response body until the handlers returned, so OnResponse hooks receive and may
+	// replace it, e.g. to inject HTML. Flushing has no effect on buffered responses.
+	// It does nothing once the response was written.
+	BufferResponse()
+
+	// Locale returns the locale selected by the I18n middleware. It returns an empty string without I18n.
+	Locale() string
+	// T returns the message for key in the request locale, formatted with args. It returns key if there is no
+	// translation, see I18n.
+	T(key string, args ...any) string
+	// NewValidator creates a Validator translating messages into the request locale, see Validator.Localize.
+	NewValidator() *Validator
+
+	// SetTrailer sets a trailer sent after the response body, e.g. a checksum of a streamed body.
+	// Trailers require a chunked response, i.e. a response without Content-Length.
+	SetTrailer(key string, value string)
+	// EarlyHints sends a 103 Early Hints response with the given Link header values,
+	// e.g. "</style.css>; rel=preload; as=style", so clients can preload resources while the response is prepared.
+	// The links are sent with the final response as well. It does nothing once the response was written.
+	EarlyHints(links ...string)
 	// SetContentType sets the response content type.
 	SetContentType(value string)
+	// SetCacheControl sets the Cache-Control header according to a cache policy.
+	SetCacheControl(policy CachePolicy)
+	// CachePolicy returns the cache policy declared for the route.
+	CachePolicy() (CachePolicy, bool)
 
 	// Cookie returns the named cookie provided in the request or false if not found.
 	// If multiple cookies match the given name, only one cookie will be returned.
 	Cookie(name string) (string, bool)
 	// SetCookie sets a cookie.
 	SetCookie(name string, value string, maxAge int, path string, domain string, secure bool, httpOnly bool)
+	// SetHTTPCookie sets a cookie with all attributes, e.g. SameSite.
+	SetHTTPCookie(cookie *http.Cookie)
+	// Session returns the session of the request. It panics if the Sessions middleware is not installed.
+	Session() *Session
+
+	// Flush sends buffered response data to the client.
+	// It returns http.ErrNotSupported if the connection does not support flushing.
+	Flush() error
+	// Hijack takes over the connection, e.g. for protocols upgraded from HTTP. The caller is responsible for closing
+	// the connection and nothing must be written through the Context afterwards.
+	// It returns http.ErrNotSupported if the connection cannot be hijacked, e.g. for HTTP/2.
+	Hijack() (net.Conn, *bufio.ReadWriter, error)
+	// Push initiates an HTTP/2 server push of target. opts may be nil.
+	// It returns http.ErrNotSupported if the connection does not support server push.
+	Push(target string, opts *http.PushOptions) error
 
 	// Stream writes a stream response.
 	Stream(step func(w io.Writer) bool) bool
+	// RespondJSONStream sets status 200 and writes the items received from items as JSON array until items is closed.
+	// Each item is flushed to the client, so the array is never buffered as a whole.
+	// It returns early if the client disconnects, producers should stop sending when Done is closed.
+	RespondJSONStream(items <-chan any) error
+	// RespondNDJSON sets status 200 and writes the items received from items as newline delimited JSON until items is closed.
+	// Each item is flushed to the client. It returns early if the client disconnects.
+	RespondNDJSON(items <-chan any) error
+	// RespondCSV sets status 200 and writes rows as CSV. Rows are a slice, an array or a receive channel of structs,
+	// pointers to structs or string slices. Columns are taken from the csv tags of the struct fields.
+	// Rows received from a channel are flushed one by one. It returns an error if rows are not supported.
+	RespondCSV(rows any, opts ...CSVOption) error
 	// SSEvent writes a server sent event.
 	SSEvent(name string, message any)
+	// EventStream writes events from source as server sent events until source is closed,
+	// ctx is done or the client disconnects.
+	EventStream(ctx context.Context, source <-chan Event) error
+	// LastEventID gets the id of the last event the client received before reconnecting.
+	LastEventID() string
+	// Go runs task in the background after the response, e.g. to send emails or webhooks.
+	// Tasks run on the task pool of the engine, panics are recovered and logged. Shutdown waits for pending tasks,
+	// the context passed to the task is canceled if the shutdown deadline is exceeded.
+	// Returns ErrTaskQueueFull if the pool is busy and ErrShuttingDown if the engine is shutting down.
+	Go(task Task) error
+	// ShuttingDown returns a channel that is closed when the engine starts shutting down.
+	// Long-lived handlers like long polls should return when it is closed.
+	ShuttingDown() <-chan struct{}
+
+	// Proxy forwards the request to target and copies the response. Upstream failures respond with 502,
+	// upstream timeouts with 504.
+	Proxy(target *url.URL, opts ...ProxyOption)
+	// UpgradeWebSocket upgrades the connection to the WebSocket protocol.
+	// If the upgrade fails, an error response has already been written.
+	UpgradeWebSocket(opts ...WSOption) (WSConn, error)
 
 	// Data sets the response status code and writes the given data as is.
 	Data(code int, contentType string, data []byte)
 
+	// HTML sets the response status code and renders the named HTML template.
+	HTML(code int, name string, data any)
+
+	// Respond sets the given status, marshals obj to JSON. If obj is nil, no response body is written.
+	Respond(status int, obj any)
 	// RespondOk sets status 200, marshals obj to JSON
 	RespondOk(obj any)
+	// RespondPage sets status 200, marshals items and the page to a PageResponse and sets the X-Total-Count and Link headers
+	RespondPage(items any, total int64, page Page)
+	// RespondOkData sets status 200, writes the given data as is
+	RespondOkData(contentType string, data []byte)
+	// RespondOkReader sets status 200, copies the reader to the response. Use a negative length if the length is unknown.
+	RespondOkReader(contentType string, length int64, r io.Reader)
+	// Content serves content with support for Range, If-Range, conditional and HEAD requests, which makes downloads resumable.
+	// The content type is derived from the name unless it is set already. A zero modtime omits the Last-Modified header.
+	Content(name string, modtime time.Time, content io.ReadSeeker)
+	// File serves the file at the given path. The content type is derived from the file extension.
+	// Range and conditional requests are supported. Missing files are answered with 404.
+	File(path string)
+	// FileFromFS serves the named file from fsys. Range requests are supported if the file is seekable.
+	// Missing files and directories are answered with 404.
+	FileFromFS(name string, fsys fs.FS)
+	// Attachment streams reader as a download with the given filename in the Content-Disposition header.
+	// If contentType is empty, it is derived from the filename. Range requests are supported if reader is an io.ReadSeeker.
+	Attachment(reader io.Reader, filename string, contentType string)
+	// RespondOkXML sets status 200, marshals obj to XML
+	RespondOkXML(obj any)
+	// XML sets the response status code and marshals obj to XML.
+	XML(code int, obj any)
+	// RespondProto sets the response status code and marshals msg to Protocol Buffers.
+	RespondProto(code int, msg proto.Message)
+	// RespondMsgPack sets the response status code and marshals obj to MessagePack.
+	RespondMsgPack(code int, obj any)
+	// RespondOkYAML sets status 200, marshals obj to YAML
+	RespondOkYAML(obj any)
+	// YAML sets the response status code and marshals obj to YAML.
+	YAML(code int, obj any)
 	// RespondNoContent sets status 204, no response body
 	RespondNoContent()
 	// RespondCreated sets status 201, marshals obj to JSON
 	RespondCreated(obj any)
+	// RespondAccepted sets status 202, marshals obj to JSON
+	RespondAccepted(obj any)
+	// RespondPartialContent sets status 206, marshals obj to JSON
+	RespondPartialContent(obj any)
+	// RespondMovedPermanently sets status 301 and the Location header
+	RespondMovedPermanently(location string)
+	// RespondFound sets status 302 and the Location header
+	RespondFound(location string)
 	// RespondForbidden sets status 403, marshals obj to JSON
 	RespondForbidden(obj any)
 	// RespondForbiddenE sets status 403, writes error as error response (JSON)
@@ -109,10 +321,28 @@ type Context interface {
 	RespondConflict(obj any)
 	// RespondConflictE sets status 409, writes error as error response (JSON)
 	RespondConflictE(err error)
+	// RespondGone sets status 410, marshals obj to JSON
+	RespondGone(obj any)
+	// RespondGoneE sets status 410, writes error as error response (JSON)
+	RespondGoneE(err error)
+	// RespondUnprocessableEntity sets status 422, marshals obj to JSON
+	RespondUnprocessableEntity(obj any)
+	// RespondUnprocessableEntityE sets status 422, writes error as error response (JSON)
+	RespondUnprocessableEntityE(err error)
+	// RespondTooManyRequests sets status 429 and the Retry-After header, marshals obj to JSON.
+	// The Retry-After header is omitted if retryAfter is not positive.
+	RespondTooManyRequests(retryAfter time.Duration, obj any)
+	// RespondTooManyRequestsE sets status 429 and the Retry-After header, writes error as error response (JSON).
+	// The Retry-After header is omitted if retryAfter is not positive.
+	RespondTooManyRequestsE(retryAfter time.Duration, err error)
 	// RespondInternalServerError sets status 500, marshals obj to JSON
 	RespondInternalServerError(obj any)
 	// RespondInternalServerErrorE sets status 500, writes error as error response (JSON)
 	RespondInternalServerErrorE(err error)
+	// RespondServiceUnavailable sets status 503, marshals obj to JSON
+	RespondServiceUnavailable(obj any)
+	// RespondServiceUnavailableE sets status 503, writes error as error response (JSON)
+	RespondServiceUnavailableE(err error)
 
 	// RespondMissingRequestBody sets status 400, writes error response (JSON)
 	RespondMissingRequestBody()
@@ -128,8 +358,15 @@ type Context interface {
 	Next()
 
 	// HandleError inspects the given error and writes an appropriate response.
+	// The error handler of the route is used, see DefaultErrorHandler for the default mapping.
+	// The error is recorded on the request, see Errors.
 	HandleError(err error)
 
+	// Error records an error on the request without writing a response. Nil errors are ignored.
+	Error(err error)
+	// Errors returns the errors recorded on the request by Error, HandleError and AbortWithError in order.
+	Errors() []error
+
 	// Deadline returns that there is no deadline (ok==false) when c.Request has no Context.
 	Deadline() (deadline time.Time, ok bool)
 	// Done returns nil (chan which will wait forever) when c.Request has no Context.
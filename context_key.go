@@ -0,0 +1,70 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import "fmt"
+
+// ContextKey is a typed key for values stored on a Context. Create keys with Key.
+//
+//	var CurrentUser = jug.Key[*User]("currentUser")
+//
+//	CurrentUser.Set(c, user)
+//	user, ok := CurrentUser.Get(c)
+type ContextKey[T any] struct {
+	name string
+}
+
+// Key creates a typed context key. Values are stored on the Context under the given name.
+func Key[T any](name string) ContextKey[T] {
+	return ContextKey[T]{name: name}
+}
+
+// Name returns the name the value is stored under.
+func (k ContextKey[T]) Name() string {
+	return k.name
+}
+
+// Set stores the value on the context.
+func (k ContextKey[T]) Set(c Context, value T) {
+	c.Set(k.name, value)
+}
+
+// Get returns the value stored on the context. It returns false if no value is set or if the value is not a T.
+func (k ContextKey[T]) Get(c Context) (T, bool) {
+	v, ok := c.Get(k.name)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	t, ok := v.(T)
+	return t, ok
+}
+
+// MustGet returns the value stored on the context. It panics if no value of type T is set.
+func (k ContextKey[T]) MustGet(c Context) T {
+	t, ok := k.Get(c)
+	if !ok {
+		panic(fmt.Sprintf("jug: no %s value for key %s", TypeKey[T](), k.name))
+	}
+	return t
+}
+
+// GetOr returns the value stored on the context or fallback if no value of type T is set.
+func (k ContextKey[T]) GetOr(c Context, fallback T) T {
+	if t, ok := k.Get(c); ok {
+		return t
+	}
+	return fallback
+}
+
+// SetTyped stores a value on the context under a typed key.
+func SetTyped[T any](c Context, key ContextKey[T], value T) {
+	key.Set(c, value)
+}
+
+// GetTyped returns the value stored on the context under a typed key.
+func GetTyped[T any](c Context, key ContextKey[T]) (T, bool) {
+	return key.Get(c)
+}
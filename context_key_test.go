@@ -0,0 +1,56 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+type keyTestUser struct {
+	Name string
+}
+
+var currentUser = Key[*keyTestUser]("currentUser")
+
+func TestContextKey(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		if _, ok := currentUser.Get(c); ok {
+			t.Error("expected no value")
+		}
+		if u := currentUser.GetOr(c, &keyTestUser{Name: "anonymous"}); u.Name != "anonymous" {
+			t.Error("expected fallback, got", u.Name)
+		}
+		SetTyped(c, currentUser, &keyTestUser{Name: "alice"})
+		if u, ok := GetTyped(c, currentUser); !ok || u.Name != "alice" {
+			t.Error("expected stored value, got", u, ok)
+		}
+		if u := currentUser.MustGet(c); u.Name != "alice" {
+			t.Error("expected stored value, got", u.Name)
+		}
+		if v, ok := c.Get(currentUser.Name()); !ok || v.(*keyTestUser).Name != "alice" {
+			t.Error("expected value stored under the key name")
+		}
+
+		c.Set("count", "not a number")
+		count := Key[int]("count")
+		if _, ok := count.Get(c); ok {
+			t.Error("expected mismatching type to be reported")
+		}
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected MustGet to panic")
+				}
+			}()
+			count.MustGet(c)
+		}()
+		c.RespondNoContent()
+	})
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusNoContent {
+		t.Fatal("unexpected status", w.Code)
+	}
+}
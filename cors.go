@@ -0,0 +1,162 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy configures cross-origin resource sharing for an engine or a group.
+type CORSPolicy struct {
+	// AllowOrigins lists the allowed origins. "*" allows any origin.
+	AllowOrigins []string
+	// AllowOriginFunc decides whether an origin is allowed. It is consulted if the origin is not in AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+	// AllowHeaders lists the request headers clients may send. If empty, the requested headers are allowed.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers clients may read.
+	ExposeHeaders []string
+	// AllowCredentials allows requests with credentials like cookies.
+	AllowCredentials bool
+	// MaxAge is the time preflight responses may be cached.
+	MaxAge time.Duration
+}
+
+type corsPolicy struct {
+	CORSPolicy
+	anyOrigin bool
+	origins   map[string]bool
+	// preflights holds the absolute paths of the preflight handlers registered by any policy of the engine.
+	preflights map[string]bool
+}
+
+func newCORSPolicy(policy CORSPolicy, config *engineConfig) *corsPolicy {
+	p := &corsPolicy{
+		CORSPolicy: policy,
+		origins:    make(map[string]bool),
+		preflights: config.preflights,
+	}
+	for _, o := range policy.AllowOrigins {
+		if o == "*" {
+			p.anyOrigin = true
+		}
+		p.origins[strings.ToLower(o)] = true
+	}
+	return p
+}
+
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.anyOrigin || p.origins[strings.ToLower(origin)] {
+		return true
+	}
+	return p.AllowOriginFunc != nil && p.AllowOriginFunc(origin)
+}
+
+// register registers a preflight handler for a path unless the path is already handled for OPTIONS.
+// Allowed methods are read from the registry on each request, so routes added later are included.
+func (p *corsPolicy) register(routes gin.IRoutes, absolutePath string, registry *PathRegistry, relativePath string, methods []string) {
+	if p == nil || registry.Get(relativePath, http.MethodOptions) || contains(methods, http.MethodOptions) {
+		return
+	}
+	registry.Add(relativePath, http.MethodOptions)
+	p.preflights[absolutePath] = true
+	routes.OPTIONS(absolutePath, func(c *gin.Context) {
+		allowed := make([]string, 0)
+		for _, m := range registry.Methods(relativePath) {
+			if m != http.MethodOptions {
+				allowed = append(allowed, m)
+			}
+		}
+		c.Header("Allow", strings.Join(append(allowed, http.MethodOptions), ", "))
+		if isPreflight(c) {
+			p.preflight(c, allowed)
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// handle sets the CORS headers of actual requests. Preflight requests are left to the registered preflight handlers,
+// unless the route handles OPTIONS itself.
+func (p *corsPolicy) handle(c *gin.Context) {
+	origin := c.GetHeader("Origin")
+	if len(origin) == 0 {
+		return
+	}
+	if isPreflight(c) {
+		if !p.preflights[c.FullPath()] {
+			p.preflight(c, nil)
+			c.AbortWithStatus(http.StatusNoContent)
+		}
+		return
+	}
+	c.Header("Vary", "Origin")
+	if !p.allowOrigin(origin) {
+		// a group policy overrides the headers set by the engine policy
+		c.Header("Access-Control-Allow-Origin", "")
+		c.Header("Access-Control-Allow-Credentials", "")
+		c.Header("Access-Control-Expose-Headers", "")
+		return
+	}
+	p.setOrigin(c, origin)
+	if len(p.ExposeHeaders) > 0 {
+		c.Header("Access-Control-Expose-Headers", strings.Join(p.ExposeHeaders, ", "))
+	}
+}
+
+func isPreflight(c *gin.Context) bool {
+	return c.Request.Method == http.MethodOptions &&
+		len(c.GetHeader("Origin")) > 0 &&
+		len(c.GetHeader("Access-Control-Request-Method")) > 0
+}
+
+// preflight sets the headers of a preflight response. If methods is nil, the requested method is allowed.
+func (p *corsPolicy) preflight(c *gin.Context, methods []string) {
+	origin := c.GetHeader("Origin")
+	c.Header("Vary", "Origin, Access-Control-Request-Method, Access-Control-Request-Headers")
+	if !p.allowOrigin(origin) {
+		return
+	}
+	p.setOrigin(c, origin)
+	if methods == nil {
+		methods = []string{c.GetHeader("Access-Control-Request-Method")}
+	}
+	c.Header("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if len(p.AllowHeaders) > 0 {
+		c.Header("Access-Control-Allow-Headers", strings.Join(p.AllowHeaders, ", "))
+	} else if h := c.GetHeader("Access-Control-Request-Headers"); len(h) > 0 {
+		c.Header("Access-Control-Allow-Headers", h)
+	}
+	if p.MaxAge > 0 {
+		c.Header("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+	}
+}
+
+func (p *corsPolicy) setOrigin(c *gin.Context, origin string) {
+	if p.anyOrigin && !p.AllowCredentials {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if p.AllowCredentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// joinPaths joins a group base path and a relative path the way gin does, keeping a trailing slash.
+func joinPaths(absolutePath string, relativePath string) string {
+	if len(relativePath) == 0 {
+		return absolutePath
+	}
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveCORS(e Engine, method string, path string, preflightMethod string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	if len(preflightMethod) > 0 {
+		r.Header.Set("Access-Control-Request-Method", preflightMethod)
+	}
+	return serveRequest(e, r)
+}
+
+func TestCORS_Group(t *testing.T) {
+	e := New()
+	api := e.Group("/api")
+	api.CORS(CORSPolicy{AllowOrigins: []string{"https://app.example.com"}, AllowCredentials: true})
+	api.Use(func(c Context) {
+		c.RespondUnauthorized("no")
+		c.Abort()
+	})
+	api.GET("/users", func(c Context) {})
+	api.POST("/users", func(c Context) {})
+	e.GET("/public", func(c Context) {})
+	e.ExpandMethods()
+
+	w := serveCORS(e, http.MethodOptions, "/api/users", http.MethodPost)
+	if w.Code != http.StatusNoContent {
+		t.Fatal("expected preflight to skip group middleware, got", w.Code)
+	}
+	if w.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
+		t.Error("expected registered methods only, got", w.Header().Get("Access-Control-Allow-Methods"))
+	}
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Error("expected origin and credentials to be allowed, got", w.Header())
+	}
+
+	w = serveCORS(e, http.MethodGet, "/api/users", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Error("expected actual request to be allowed, got", w.Header())
+	}
+
+	w = serveCORS(e, http.MethodGet, "/public", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected routes outside the group to have no CORS headers, got", w.Header())
+	}
+}
+
+func TestCORS_Engine(t *testing.T) {
+	e := New()
+	e.CORS(CORSPolicy{AllowOrigins: []string{"*"}})
+	e.DELETE("/items/:id", func(c Context) {})
+	e.Any("/any", func(c Context) {})
+	api := e.Group("/api")
+	api.CORS(CORSPolicy{AllowOrigins: []string{"https://other.example.com"}})
+	api.PUT("/items", func(c Context) {})
+
+	w := serveCORS(e, http.MethodOptions, "/items/1", http.MethodDelete)
+	if w.Header().Get("Access-Control-Allow-Methods") != "DELETE" || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("unexpected preflight headers", w.Header())
+	}
+	w = serveCORS(e, http.MethodOptions, "/any", http.MethodPatch)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") != "PATCH" {
+		t.Error("expected middleware to answer preflight for routes handling OPTIONS, got", w.Code, w.Header())
+	}
+	w = serveCORS(e, http.MethodOptions, "/api/items", http.MethodPut)
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected group policy to override engine policy, got", w.Header())
+	}
+	w = serveCORS(e, http.MethodPut, "/api/items", "")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Error("expected group policy to override engine policy for actual requests, got", w.Header())
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVContentType is the content type of CSV responses.
+const CSVContentType = "text/csv; charset=utf-8"
+
+// CSVOption configures a CSV response.
+type CSVOption func(cfg *csvConfig)
+
+type csvConfig struct {
+	delimiter rune
+	filename  string
+	header    bool
+}
+
+// CSVDelimiter sets the field delimiter. Defaults to a comma.
+func CSVDelimiter(delimiter rune) CSVOption {
+	return func(cfg *csvConfig) {
+		cfg.delimiter = delimiter
+	}
+}
+
+// CSVFilename sends the response as download with the given filename.
+func CSVFilename(filename string) CSVOption {
+	return func(cfg *csvConfig) {
+		cfg.filename = filename
+	}
+}
+
+// CSVWithoutHeader omits the header row.
+func CSVWithoutHeader() CSVOption {
+	return func(cfg *csvConfig) {
+		cfg.header = false
+	}
+}
+
+func newCSVConfig(opts []CSVOption) *csvConfig {
+	cfg := &csvConfig{delimiter: ',', header: true}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// csvColumn is a struct field mapped to a CSV column.
+type csvColumn struct {
+	name  string
+	index []int
+}
+
+// csvColumns returns the fields of t with a csv tag in declaration order. Untagged embedded structs are flattened.
+func csvColumns(t reflect.Type) []csvColumn {
+	columns := make([]csvColumn, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := field.Tag.Lookup("csv")
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				for _, c := range csvColumns(field.Type) {
+					columns = append(columns, csvColumn{name: c.name, index: append([]int{i}, c.index...)})
+				}
+			}
+			continue
+		}
+		name = strings.Split(name, ",")[0]
+		if name == "-" {
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		columns = append(columns, csvColumn{name: name, index: []int{i}})
+	}
+	return columns
+}
+
+// csvRows iterates the rows of a CSV response. Rows are a slice, an array or a receive channel of structs,
+// pointers to structs or string slices.
+type csvRows struct {
+	rows    reflect.Value
+	columns []csvColumn
+	raw     bool
+}
+
+func newCSVRows(rows any) (*csvRows, error) {
+	v := reflect.ValueOf(rows)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+	case reflect.Chan:
+		if v.Type().ChanDir()&reflect.RecvDir == 0 {
+			return nil, fmt.Errorf("jug: CSV rows must be a receivable channel")
+		}
+	default:
+		return nil, fmt.Errorf("jug: CSV rows must be a slice, an array or a channel, got %T", rows)
+	}
+	elem := v.Type().Elem()
+	if elem.Kind() == reflect.Slice && elem.Elem().Kind() == reflect.String {
+		return &csvRows{rows: v, raw: true}, nil
+	}
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("jug: unsupported CSV row type %s", v.Type().Elem())
+	}
+	return &csvRows{rows: v, columns: csvColumns(elem)}, nil
+}
+
+func (r *csvRows) header() []string {
+	header := make([]string, 0, len(r.columns))
+	for _, c := range r.columns {
+		header = append(header, c.name)
+	}
+	return header
+}
+
+func (r *csvRows) record(row reflect.Value) ([]string, bool) {
+	if r.raw {
+		return row.Interface().([]string), true
+	}
+	for row.Kind() == reflect.Pointer {
+		if row.IsNil() {
+			return nil, false
+		}
+		row = row.Elem()
+	}
+	record := make([]string, 0, len(r.columns))
+	for _, c := range r.columns {
+		record = append(record, formatCSVValue(row.FieldByIndex(c.index)))
+	}
+	return record, true
+}
+
+// write writes all rows. Rows received from a channel are flushed one by one.
+func (r *csvRows) write(ctx context.Context, w io.Writer, flush func(), cfg *csvConfig) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = cfg.delimiter
+	if cfg.header && !r.raw {
+		if err := cw.Write(r.header()); err != nil {
+			return err
+		}
+	}
+	writeRow := func(row reflect.Value) error {
+		record, ok := r.record(row)
+		if !ok {
+			return nil
+		}
+		return cw.Write(record)
+	}
+	if r.rows.Kind() != reflect.Chan {
+		for i := 0; i < r.rows.Len(); i++ {
+			if err := writeRow(r.rows.Index(i)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: r.rows},
+	}
+	for {
+		chosen, row, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return ctx.Err()
+		}
+		if !ok {
+			cw.Flush()
+			return cw.Error()
+		}
+		if err := writeRow(row); err != nil {
+			return err
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+		flush()
+	}
+}
+
+// formatCSVValue formats a field value. Times are formatted as RFC 3339, nil pointers as empty string.
+func formatCSVValue(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+	if v.CanInterface() {
+		if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+			if text, err := m.MarshalText(); err == nil {
+				return string(text)
+			}
+		}
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits())
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// bindCSV binds the records of a CSV body with a header row to dest, a pointer to a slice of structs.
+// Columns are matched by the csv tag of the fields. Each bound record is validated.
+func bindCSV(r io.Reader, dest any, loc *time.Location) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("jug: CSV binding target must be a pointer to a slice, got %T", dest)
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("jug: unsupported CSV binding target %T", dest)
+	}
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		columns[strings.TrimSpace(name)] = i
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		elem := reflect.New(structType)
+		b := &valueBinder{
+			tag:      "csv",
+			location: loc,
+			values: func(key string) ([]string, bool) {
+				i, ok := columns[key]
+				if !ok || len(record[i]) == 0 {
+					return nil, false
+				}
+				return []string{record[i]}, true
+			},
+		}
+		if err := b.bind(elem.Interface()); err != nil {
+			return lineBindingError(line, err)
+		}
+		if err := validate(elem.Interface()); err != nil {
+			return &recordValidationError{err: lineValidationError(line, err)}
+		}
+		if elemType.Kind() == reflect.Pointer {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+}
+
+// lineBindingError prefixes the fields of a BindingError with the line number, e.g. [3].name.
+func lineBindingError(line int, err error) error {
+	be, ok := err.(*BindingError)
+	if !ok {
+		return err
+	}
+	prefixed := &BindingError{Fields: make([]FieldBindingError, 0, len(be.Fields))}
+	for _, f := range be.Fields {
+		prefixed.Fields = append(prefixed.Fields, FieldBindingError{
+			Field:   fmt.Sprintf("[%d].%s", line, f.Field),
+			Message: fmt.Sprintf("line %d: %s", line, f.Message),
+		})
+	}
+	return prefixed
+}
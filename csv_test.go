@@ -0,0 +1,129 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CSVTimestamps struct {
+	Created time.Time `csv:"created"`
+}
+
+type csvUser struct {
+	CSVTimestamps
+	ID     int     `csv:"id"`
+	Name   string  `csv:"name" validate:"required"`
+	Score  float64 `csv:"score"`
+	Email  *string `csv:"email"`
+	Secret string  `csv:"-"`
+	Note   string
+}
+
+func TestContext_RespondCSV(t *testing.T) {
+	email := "alice@example.com"
+	created := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	users := []csvUser{
+		{CSVTimestamps: CSVTimestamps{Created: created}, ID: 1, Name: "Alice", Score: 9.5, Email: &email, Secret: "x"},
+		{ID: 2, Name: "Bob, Jr.", Score: 7},
+	}
+	e := New()
+	e.GET("/users.csv", func(c Context) {
+		_ = c.RespondCSV(users, CSVFilename("users.csv"))
+	})
+	e.GET("/raw", func(c Context) {
+		_ = c.RespondCSV([][]string{{"a", "b"}, {"1", "2"}}, CSVDelimiter(';'))
+	})
+	e.GET("/stream", func(c Context) {
+		rows := make(chan *csvUser)
+		go func() {
+			defer close(rows)
+			rows <- &csvUser{ID: 3, Name: "Carol"}
+			rows <- nil
+		}()
+		_ = c.RespondCSV(rows, CSVWithoutHeader())
+	})
+	e.GET("/invalid", func(c Context) {
+		if err := c.RespondCSV(42); err == nil {
+			t.Error("expected error for unsupported rows")
+		}
+		c.RespondNoContent()
+	})
+
+	w := serve(e, http.MethodGet, "/users.csv")
+	expected := "created,id,name,score,email\n" +
+		"2023-10-01T12:00:00Z,1,Alice,9.5,alice@example.com\n" +
+		",2,\"Bob, Jr.\",7,\n"
+	if w.Code != http.StatusOK || w.Body.String() != expected {
+		t.Fatalf("unexpected CSV response %d\n%s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != CSVContentType {
+		t.Fatal("unexpected content type", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != "attachment; filename=users.csv" {
+		t.Fatal("unexpected Content-Disposition", cd)
+	}
+
+	if w := serve(e, http.MethodGet, "/raw"); w.Body.String() != "a;b\n1;2\n" {
+		t.Fatal("unexpected raw CSV", w.Body.String())
+	}
+
+	w = serve(e, http.MethodGet, "/stream")
+	if w.Body.String() != ",3,Carol,0,\n" || !w.Flushed {
+		t.Fatal("expected flushed rows without header, got", w.Body.String())
+	}
+
+	if w := serve(e, http.MethodGet, "/invalid"); w.Code != http.StatusNoContent {
+		t.Fatal("expected nothing to be written, got", w.Code)
+	}
+}
+
+func TestContext_BindCSV(t *testing.T) {
+	var users []csvUser
+	e := New()
+	e.POST("/import", func(c Context) {
+		users = nil
+		if c.BindCSV(&users) {
+			c.RespondNoContent()
+		}
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body)))
+	}
+
+	w := post("\ufeffname,id,email,created\nAlice,1,alice@example.com,2023-10-01\nBob,2,,\n")
+	if w.Code != http.StatusNoContent || len(users) != 2 {
+		t.Fatal("expected records to be bound, got", w.Code, users)
+	}
+	if users[0].Name != "Alice" || users[0].ID != 1 || *users[0].Email != "alice@example.com" || users[0].Created.Day() != 1 {
+		t.Fatal("unexpected first record", users[0])
+	}
+	if users[1].Email != nil || !users[1].Created.IsZero() {
+		t.Fatal("expected empty cells to be skipped", users[1])
+	}
+
+	w = post("name,id\nAlice,1\nBob,x\n")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"line 3: invalid value for id: strconv.ParseInt: parsing \"x\": invalid syntax","fields":[{"field":"[3].id","message":"line 3: invalid value for id: strconv.ParseInt: parsing \"x\": invalid syntax"}]}` {
+		t.Fatal("expected binding error with line, got", w.Code, w.Body.String())
+	}
+
+	w = post("name,id\n,1\n")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"line 2: Name is required"}` {
+		t.Fatal("expected validation error with line, got", w.Code, w.Body.String())
+	}
+
+	w = post("name,id\nAlice\n")
+	if w.Code != http.StatusBadRequest {
+		t.Fatal("expected malformed CSV to be rejected, got", w.Code)
+	}
+
+	if w := post(""); w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"request body is missing"}` {
+		t.Fatal("expected missing body, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// dependenciesKey is the context key holding the dependencies resolved for a request.
+const dependenciesKey = "jug.dependencies"
+
+// Constructor creates a request scoped dependency, see Engine.Provide.
+type Constructor func(c Context) (any, error)
+
+// ErrNotProvided is returned by Resolve if no constructor is provided for a key.
+var ErrNotProvided = errors.New("dependency not provided")
+
+// TypeKey returns the key Resolve uses for dependencies of type T, e.g. "*sql.Tx".
+func TypeKey[T any]() string {
+	return reflect.TypeOf((*T)(nil)).Elem().String()
+}
+
+// Resolve returns the dependency of type T provided under TypeKey[T]().
+func Resolve[T any](c Context) (T, error) {
+	return ResolveKey[T](c, TypeKey[T]())
+}
+
+// ResolveKey returns the dependency provided under key. The dependency is constructed on first use and cached on the context,
+// so each request gets its own instance. Errors of the constructor are returned and not cached.
+func ResolveKey[T any](c Context, key string) (T, error) {
+	var zero T
+	w, ok := c.(*contextWrapper)
+	if !ok {
+		return zero, fmt.Errorf("jug: %s: %w", key, ErrNotProvided)
+	}
+	v, err := w.dependencies().resolve(w, key)
+	if err != nil {
+		return zero, err
+	}
+	t, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("jug: dependency %s is %T, not %s", key, v, TypeKey[T]())
+	}
+	return t, nil
+}
+
+// MustResolve returns the dependency of type T. It panics if the dependency cannot be resolved.
+func MustResolve[T any](c Context) T {
+	t, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// requestDependencies caches the dependencies resolved for a request.
+type requestDependencies struct {
+	mu        sync.Mutex
+	values    map[string]any
+	resolving map[string]bool
+}
+
+func (w *contextWrapper) dependencies() *requestDependencies {
+	if v, ok := w.c.Get(dependenciesKey); ok {
+		return v.(*requestDependencies)
+	}
+	d := &requestDependencies{
+		values:    make(map[string]any),
+		resolving: make(map[string]bool),
+	}
+	w.c.Set(dependenciesKey, d)
+	return d
+}
+
+func (d *requestDependencies) resolve(w *contextWrapper, key string) (any, error) {
+	constructor, ok := w.config.providers[key]
+	if !ok {
+		return nil, fmt.Errorf("jug: %s: %w", key, ErrNotProvided)
+	}
+	d.mu.Lock()
+	if v, ok := d.values[key]; ok {
+		d.mu.Unlock()
+		return v, nil
+	}
+	if d.resolving[key] {
+		d.mu.Unlock()
+		return nil, fmt.Errorf("jug: dependency cycle at %s", key)
+	}
+	d.resolving[key] = true
+	d.mu.Unlock()
+
+	v, err := constructor(w)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.resolving, key)
+	if err != nil {
+		return nil, err
+	}
+	d.values[key] = v
+	return v, nil
+}
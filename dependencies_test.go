@@ -0,0 +1,89 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type testRepository struct {
+	id int
+}
+
+type testUserService struct {
+	repo *testRepository
+}
+
+func TestResolve(t *testing.T) {
+	constructed := 0
+	e := New()
+	e.Provide(TypeKey[*testRepository](), func(c Context) (any, error) {
+		constructed++
+		return &testRepository{id: constructed}, nil
+	})
+	e.Provide(TypeKey[*testUserService](), func(c Context) (any, error) {
+		repo, err := Resolve[*testRepository](c)
+		if err != nil {
+			return nil, err
+		}
+		return &testUserService{repo: repo}, nil
+	})
+	e.GET("/users", func(c Context) {
+		svc := MustResolve[*testUserService](c)
+		repo, err := Resolve[*testRepository](c)
+		if err != nil || repo != svc.repo {
+			t.Error("expected cached repository, got", repo, err)
+		}
+		c.String(http.StatusOK, "%d", svc.repo.id)
+	})
+
+	if w := serve(e, http.MethodGet, "/users"); w.Body.String() != "1" {
+		t.Fatal("expected first instance, got", w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/users"); w.Body.String() != "2" {
+		t.Fatal("expected new instance per request, got", w.Body.String())
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	failure := errors.New("connection refused")
+	e := New()
+	e.Provide("db", func(c Context) (any, error) {
+		return nil, failure
+	})
+	e.Provide("name", func(c Context) (any, error) {
+		return "jug", nil
+	})
+	e.Provide("a", func(c Context) (any, error) {
+		return ResolveKey[string](c, "b")
+	})
+	e.Provide("b", func(c Context) (any, error) {
+		return ResolveKey[string](c, "a")
+	})
+	e.GET("/", func(c Context) {
+		if _, err := Resolve[*testRepository](c); !errors.Is(err, ErrNotProvided) {
+			t.Error("expected ErrNotProvided, got", err)
+		}
+		if _, err := ResolveKey[string](c, "db"); !errors.Is(err, failure) {
+			t.Error("expected constructor error, got", err)
+		}
+		if _, err := ResolveKey[int](c, "name"); err == nil {
+			t.Error("expected type mismatch error")
+		}
+		if _, err := ResolveKey[string](c, "a"); err == nil {
+			t.Error("expected cycle to be detected")
+		}
+		c.RespondNoContent()
+	})
+	serve(e, http.MethodGet, "/")
+}
+
+func TestTypeKey(t *testing.T) {
+	if k := TypeKey[*testRepository](); k != "*jug.testRepository" {
+		t.Fatal("unexpected type key", k)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// DynamicRoutes holds routes below a prefix that can be added and removed while the engine is running,
+// e.g. for endpoints loaded by plugins. Each change builds a new router which replaces the current one atomically,
+// requests in flight finish on the router they started on.
+// Middleware of the engine applies to dynamic routes, values set by it are available to the handlers.
+type DynamicRoutes struct {
+	mu     sync.Mutex
+	prefix string
+	config *engineConfig
+	routes map[dynamicRouteKey][]HandlerFunc
+	router atomic.Pointer[gin.Engine]
+}
+
+type dynamicRouteKey struct {
+	method string
+	path   string
+}
+
+// outerContextKey stores the context of the engine in requests served by inner routers.
+type outerContextKey struct{}
+
+func (r *ginEngine) Dynamic(prefix string) *DynamicRoutes {
+	d := &DynamicRoutes{
+		prefix: joinPaths("/", prefix),
+		config: r.config,
+		routes: make(map[dynamicRouteKey][]HandlerFunc),
+	}
+	d.router.Store(newInnerRouter())
+	r.addPath(mountPattern(prefix), registryMethods...)
+	r.engine.Any(mountPattern(prefix), d.serve)
+	r.config.newRoute("/", mountPattern(prefix), registryMethods...)
+	return d
+}
+
+// AddRoute adds a route. The path is relative to the prefix of the dynamic routes.
+// It returns an error if the route is already registered or conflicts with another route.
+func (d *DynamicRoutes) AddRoute(method string, relativePath string, handlers ...HandlerFunc) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := dynamicRouteKey{method: method, path: joinPaths(d.prefix, relativePath)}
+	if _, ok := d.routes[key]; ok {
+		return fmt.Errorf("jug: %s %s is already registered", key.method, key.path)
+	}
+	d.routes[key] = handlers
+	router, err := d.rebuild()
+	if err != nil {
+		delete(d.routes, key)
+		return err
+	}
+	d.router.Store(router)
+	d.config.events.Publish(TopicRouteRegistered, RouteEvent{Methods: []string{method}, Path: key.path})
+	return nil
+}
+
+// RemoveRoute removes a route. It reports whether the route was registered.
+func (d *DynamicRoutes) RemoveRoute(method string, relativePath string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := dynamicRouteKey{method: method, path: joinPaths(d.prefix, relativePath)}
+	if _, ok := d.routes[key]; !ok {
+		return false
+	}
+	delete(d.routes, key)
+	// removing a route cannot introduce conflicts
+	router, _ := d.rebuild()
+	d.router.Store(router)
+	return true
+}
+
+// Routes returns the registered routes sorted by path and method.
+func (d *DynamicRoutes) Routes() []RouteInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	routes := make([]RouteInfo, 0, len(d.routes))
+	for key := range d.routes {
+		routes = append(routes, RouteInfo{Path: key.path, Methods: []string{key.method}})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Methods[0] < routes[j].Methods[0]
+	})
+	return routes
+}
+
+// rebuild builds a router with all routes. Conflicting routes make gin panic, the panic is returned as error.
+func (d *DynamicRoutes) rebuild() (router *gin.Engine, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("jug: %v", r)
+		}
+	}()
+	router = newInnerRouter()
+	keys := make([]dynamicRouteKey, 0, len(d.routes))
+	for key := range d.routes {
+		keys = append(keys, key)
+	}
+	// register in a stable order, so that errors don't depend on map iteration
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		return keys[i].method < keys[j].method
+	})
+	for _, key := range keys {
+		router.Handle(key.method, key.path, MapMany(d.routes[key], d.config.wrapHandler)...)
+	}
+	return router, nil
+}
+
+// newInnerRouter creates a router serving requests passed on by a route of the engine.
+func newInnerRouter() *gin.Engine {
+	router := gin.New()
+	router.ContextWithFallback = true
+	router.HandleMethodNotAllowed = true
+	router.Use(shareOuterKeys, finishResponses)
+	return router
+}
+
+// serve passes requests to the current router. Paths are not stripped, so handlers see the full path.
+func (d *DynamicRoutes) serve(c *gin.Context) {
+	serveInner(d.router.Load(), c)
+}
+
+// serveInner passes a request to an inner router.
+func serveInner(router *gin.Engine, c *gin.Context) {
+	req := c.Request.WithContext(context.WithValue(c.Request.Context(), outerContextKey{}, c))
+	router.ServeHTTP(c.Writer, req)
+}
+
+// shareOuterKeys makes the values set by the middleware of the engine available to inner routers.
+func shareOuterKeys(c *gin.Context) {
+	outer, ok := c.Request.Context().Value(outerContextKey{}).(*gin.Context)
+	if !ok {
+		return
+	}
+	if outer.Keys == nil {
+		outer.Keys = make(map[string]any)
+	}
+	c.Keys = outer.Keys
+}
@@ -0,0 +1,54 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEngine_Dynamic(t *testing.T) {
+	e := New()
+	e.Use(func(c Context) {
+		c.Set("tenant", "acme")
+	})
+	plugins := e.Dynamic("/plugins")
+
+	if w := serve(e, http.MethodGet, "/plugins/reports/1"); w.Code != http.StatusNotFound {
+		t.Fatal("expected 404 without routes, got", w.Code)
+	}
+	err := plugins.AddRoute(http.MethodGet, "/reports/:id", func(c Context) {
+		tenant, _ := c.Get("tenant")
+		gc, _ := ginContextOf(c)
+		c.String(http.StatusOK, "%s %s %s", tenant, c.Param("id"), gc.FullPath())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := serve(e, http.MethodGet, "/plugins/reports/1")
+	if w.Code != http.StatusOK || w.Body.String() != "acme 1 /plugins/reports/:id" {
+		t.Fatal("expected dynamic route, got", w.Code, w.Body.String())
+	}
+	if w := serve(e, http.MethodPost, "/plugins/reports/1"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 for other method, got", w.Code)
+	}
+
+	if err := plugins.AddRoute(http.MethodGet, "/reports/:id", func(c Context) {}); err == nil {
+		t.Error("expected duplicate route to fail")
+	}
+	if err := plugins.AddRoute(http.MethodGet, "/reports/:name/summary", func(c Context) {}); err == nil {
+		t.Error("expected conflicting route to fail")
+	}
+	if routes := plugins.Routes(); len(routes) != 1 || routes[0].Path != "/plugins/reports/:id" {
+		t.Fatal("expected failed routes not to be registered, got", routes)
+	}
+
+	if !plugins.RemoveRoute(http.MethodGet, "/reports/:id") || plugins.RemoveRoute(http.MethodGet, "/reports/:id") {
+		t.Fatal("expected route to be removed once")
+	}
+	if w := serve(e, http.MethodGet, "/plugins/reports/1"); w.Code != http.StatusNotFound {
+		t.Fatal("expected 404 after removal, got", w.Code)
+	}
+}
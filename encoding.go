@@ -0,0 +1,211 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Encoder encodes response bodies.
+type Encoder interface {
+	Encode(w io.Writer, obj any) error
+}
+
+// EncoderFunc is an adapter to allow the use of ordinary functions as Encoder.
+type EncoderFunc func(w io.Writer, obj any) error
+
+func (f EncoderFunc) Encode(w io.Writer, obj any) error {
+	return f(w, obj)
+}
+
+// JSONEncoder encodes objects as JSON.
+var JSONEncoder Encoder = EncoderFunc(func(w io.Writer, obj any) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+})
+
+// XMLEncoder encodes objects as XML.
+var XMLEncoder Encoder = EncoderFunc(func(w io.Writer, obj any) error {
+	return xml.NewEncoder(w).Encode(obj)
+})
+
+// YAMLContentType is the content type of YAML responses.
+const YAMLContentType = "application/yaml; charset=utf-8"
+
+// YAMLEncoder encodes objects as YAML.
+var YAMLEncoder Encoder = EncoderFunc(func(w io.Writer, obj any) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+})
+
+// MsgPackContentType is the content type of MessagePack responses.
+const MsgPackContentType = "application/msgpack"
+
+// MsgPackEncoder encodes objects as MessagePack.
+var MsgPackEncoder Encoder = EncoderFunc(func(w io.Writer, obj any) error {
+	return codec.NewEncoder(w, new(codec.MsgpackHandle)).Encode(obj)
+})
+
+// ProtobufContentType is the content type of Protocol Buffers responses.
+const ProtobufContentType = "application/x-protobuf"
+
+// ProtobufEncoder encodes proto messages. Other objects cannot be encoded.
+// It is not registered by default, register it for engines that only respond with proto messages.
+var ProtobufEncoder Encoder = EncoderFunc(func(w io.Writer, obj any) error {
+	msg, ok := obj.(proto.Message)
+	if !ok {
+		return fmt.Errorf("jug: protobuf encoding requires a proto.Message, got %T", obj)
+	}
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+})
+
+type encoderEntry struct {
+	mediaType string
+	header    string
+	encoder   Encoder
+}
+
+// defaultEncoders returns the built-in encoders. The first entry is used if no encoder matches.
+func defaultEncoders() []encoderEntry {
+	return []encoderEntry{
+		newEncoderEntry("application/json; charset=utf-8", JSONEncoder),
+		newEncoderEntry("application/xml; charset=utf-8", XMLEncoder),
+		newEncoderEntry("text/xml; charset=utf-8", XMLEncoder),
+		newEncoderEntry(YAMLContentType, YAMLEncoder),
+		newEncoderEntry(MsgPackContentType, MsgPackEncoder),
+		newEncoderEntry("application/x-msgpack", MsgPackEncoder),
+	}
+}
+
+func newEncoderEntry(contentType string, enc Encoder) encoderEntry {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return encoderEntry{
+		mediaType: mediaType,
+		header:    contentType,
+		encoder:   enc,
+	}
+}
+
+// registerEncoder adds an encoder or replaces the encoder for the same media type.
+func (cfg *engineConfig) registerEncoder(contentType string, enc Encoder) {
+	e := newEncoderEntry(contentType, enc)
+	for i, existing := range cfg.encoders {
+		if existing.mediaType == e.mediaType {
+			cfg.encoders[i] = e
+			return
+		}
+	}
+	cfg.encoders = append(cfg.encoders, e)
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses an Accept header into media ranges ordered by preference.
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+	return ranges
+}
+
+func specificity(mediaType string) int {
+	if mediaType == "*/*" {
+		return 0
+	}
+	if strings.HasSuffix(mediaType, "/*") {
+		return 1
+	}
+	return 2
+}
+
+func (r mediaRange) matches(mediaType string) bool {
+	if r.mediaType == "*/*" || r.mediaType == mediaType {
+		return true
+	}
+	if strings.HasSuffix(r.mediaType, "/*") {
+		return strings.HasPrefix(mediaType, strings.TrimSuffix(r.mediaType, "*"))
+	}
+	return false
+}
+
+// negotiateEncoder selects the encoder for the given Accept header. Only the most preferred media ranges of the header
+// are considered, so browsers preferring HTML get the first encoder. The first encoder wins ties and wildcards,
+// other encoders are selected if a preferred range names their media type. If no encoder matches,
+// the first encoder is returned.
+func negotiateEncoder(accept string, encoders []encoderEntry) encoderEntry {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		return encoders[0]
+	}
+	preferred := ranges
+	for i, r := range ranges {
+		if r.q < ranges[0].q {
+			preferred = ranges[:i]
+			break
+		}
+	}
+	for _, r := range preferred {
+		if r.matches(encoders[0].mediaType) {
+			return encoders[0]
+		}
+	}
+	for _, r := range preferred {
+		for _, e := range encoders[1:] {
+			if r.mediaType == e.mediaType {
+				return e
+			}
+		}
+	}
+	return encoders[0]
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNegotiateEncoder(t *testing.T) {
+	encoders := defaultEncoders()
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"text/html, application/yaml;q=0.9, */*;q=0.1", "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "application/json"},
+		{"application/yaml, application/json", "application/json"},
+		{"application/yaml, */*", "application/json"},
+		{"application/yaml, text/html;q=0.9", "application/yaml"},
+		{"application/json;q=0.5, application/xml", "application/xml"},
+		{"text/*", "application/json"},
+		{"image/png", "application/json"},
+	}
+	for _, test := range tests {
+		e := negotiateEncoder(test.accept, encoders)
+		if e.mediaType != test.expected {
+			t.Errorf("negotiateEncoder(%q) should select %s, got %s", test.accept, test.expected, e.mediaType)
+		}
+	}
+}
+
+func TestEngineConfig_RegisterEncoder(t *testing.T) {
+	cfg := newEngineConfig()
+	cfg.registerEncoder("application/json", XMLEncoder)
+	if len(cfg.encoders) != len(defaultEncoders()) {
+		t.Fatal("registering an existing media type should replace the encoder")
+	}
+	if cfg.encoders[0].header != "application/json" {
+		t.Fatal("expected the replaced encoder to use the registered content type, got", cfg.encoders[0].header)
+	}
+	cfg.registerEncoder("text/csv", JSONEncoder)
+	if e := negotiateEncoder("text/csv", cfg.encoders); e.mediaType != "text/csv" {
+		t.Fatal("expected the custom encoder to be selected, got", e.mediaType)
+	}
+}
+
+func TestContext_RespondOk_Negotiation(t *testing.T) {
+	e := New()
+	e.GET("/status", func(c Context) {
+		c.RespondOk(map[string]any{"status": "ok"})
+	})
+	get := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/status", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	w := get("text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	if w.Code != http.StatusOK || w.Body.String() != `{"status":"ok"}` {
+		t.Error("expected JSON for a browser, got", w.Code, w.Body.String())
+	}
+	w = get("application/xml")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Error("expected fallback to JSON for maps, got", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	w = get("application/yaml")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != YAMLContentType {
+		t.Error("expected YAML, got", w.Code, w.Header().Get("Content-Type"))
+	}
+}
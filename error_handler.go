@@ -0,0 +1,157 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// errorHandlerKey is the context key holding the error handler of the matched route.
+const errorHandlerKey = "jug.errorHandler"
+
+// ErrorHandler writes the response for an error passed to Context.HandleError.
+// Handlers that only map some errors should delegate the others to DefaultErrorHandler.
+// Calling Context.HandleError from an ErrorHandler recurses.
+type ErrorHandler func(c Context, err error)
+
+// DefaultErrorHandler maps errors to responses:
+// ResponseStatusError and ProblemDetails to their status, also when wrapped, ValidationError to 400, errors wrapping context.DeadlineExceeded to 504,
+// exceeded body size limits to 413 and all other errors to 500.
+//
+// Aggregated errors, i.e. errors implementing Unwrap() []error like those returned by errors.Join, are answered with the
+// status of the error with the highest status code. The messages of all errors are included in the response.
+func DefaultErrorHandler(c Context, err error) {
+	w, ok := c.(*contextWrapper)
+	if !ok {
+		c.RespondInternalServerError(err)
+		return
+	}
+	errs := flattenErrors(err)
+	if len(errs) > 1 {
+		w.respondErrors(errs)
+		return
+	} else if len(errs) == 1 {
+		err = errs[0]
+	}
+	var rse *ResponseStatusError
+	var ve *ValidationError
+	var pd *ProblemDetails
+	if errors.As(err, &rse) {
+		w.setRetryAfter(rse.RetryAfter)
+		w.respondE(rse.StatusCode, rse)
+	} else if errors.As(err, &pd) {
+		w.respondProblem(pd)
+	} else if errors.As(err, &ve) {
+		w.respondE(http.StatusBadRequest, ve)
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		w.respondE(http.StatusGatewayTimeout, err)
+	} else if tooLarge, ok := bodyTooLarge(err); ok {
+		w.respondE(http.StatusRequestEntityTooLarge, tooLarge)
+	} else if w.config.problemJSON {
+		// the message of unknown errors is not exposed
+		w.respondProblem(&ProblemDetails{Status: http.StatusInternalServerError})
+	} else {
+		w.RespondInternalServerError(err)
+	}
+}
+
+// multiError is implemented by errors aggregating several errors.
+type multiError interface {
+	Unwrap() []error
+}
+
+// flattenErrors returns the errors aggregated by err, recursively. Other errors are returned as is.
+func flattenErrors(err error) []error {
+	me, ok := err.(multiError)
+	if !ok {
+		return []error{err}
+	}
+	errs := make([]error, 0)
+	for _, e := range me.Unwrap() {
+		if e != nil {
+			errs = append(errs, flattenErrors(e)...)
+		}
+	}
+	return errs
+}
+
+// errorStatus returns the status DefaultErrorHandler answers err with and whether err is a known error.
+func errorStatus(err error) (int, bool) {
+	var rse *ResponseStatusError
+	var pd *ProblemDetails
+	var ve *ValidationError
+	if errors.As(err, &rse) {
+		return rse.StatusCode, true
+	} else if errors.As(err, &pd) {
+		if pd.Status == 0 {
+			return http.StatusInternalServerError, true
+		}
+		return pd.Status, true
+	} else if errors.As(err, &ve) {
+		return http.StatusBadRequest, true
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, true
+	} else if _, ok := bodyTooLarge(err); ok {
+		return http.StatusRequestEntityTooLarge, true
+	}
+	return http.StatusInternalServerError, false
+}
+
+// respondErrors answers aggregated errors with the status of the error with the highest status code.
+func (w *contextWrapper) respondErrors(errs []error) {
+	primary := errs[0]
+	status, known := errorStatus(primary)
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		s, k := errorStatus(err)
+		if s > status {
+			primary, status, known = err, s, k
+		}
+		if k || !w.config.problemJSON {
+			messages = append(messages, err.Error())
+		}
+	}
+	var rse *ResponseStatusError
+	if errors.As(primary, &rse) {
+		w.setRetryAfter(rse.RetryAfter)
+	}
+	if !w.config.problemJSON {
+		w.writeJSON(status, gin.H{"error": primary.Error(), "messages": messages})
+		return
+	}
+	p := &ProblemDetails{Status: status}
+	if known {
+		copied := *problemFor(status, primary)
+		p = &copied
+	}
+	extensions := map[string]any{"messages": messages}
+	for k, v := range p.Extensions {
+		extensions[k] = v
+	}
+	p.Extensions = extensions
+	w.respondProblem(p)
+}
+
+// WithErrorHandler returns a middleware that sets the error handler for subsequent handlers.
+// It overrides the error handlers of the engine and of enclosing groups.
+func WithErrorHandler(handler ErrorHandler) HandlerFunc {
+	return func(c Context) {
+		c.Set(errorHandlerKey, handler)
+	}
+}
+
+// errorHandler returns the error handler of the route, falling back to the engine's and the default error handler.
+func (w *contextWrapper) errorHandler() ErrorHandler {
+	if v, ok := w.c.Get(errorHandlerKey); ok {
+		return v.(ErrorHandler)
+	}
+	if w.config.errorHandler != nil {
+		return w.config.errorHandler
+	}
+	return DefaultErrorHandler
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestEngine_SetErrorHandler(t *testing.T) {
+	e := New()
+	e.SetErrorHandler(func(c Context, err error) {
+		if errors.Is(err, errNotFound) {
+			c.RespondNotFoundE(err)
+			return
+		}
+		DefaultErrorHandler(c, err)
+	})
+	e.GET("/missing", func(c Context) {
+		c.HandleError(errNotFound)
+	})
+	e.GET("/conflict", func(c Context) {
+		c.HandleError(NewConflictError("taken"))
+	})
+
+	if w := serve(e, http.MethodGet, "/missing"); w.Code != http.StatusNotFound {
+		t.Fatal("expected custom mapping, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/conflict"); w.Code != http.StatusConflict {
+		t.Fatal("expected default mapping, got", w.Code)
+	}
+}
+
+func TestRouterGroup_SetErrorHandler(t *testing.T) {
+	e := New()
+	e.SetErrorHandler(func(c Context, err error) {
+		c.RespondBadRequestE(err)
+	})
+	failing := func(c Context) {
+		c.HandleError(errNotFound)
+	}
+	e.GET("/engine", failing)
+	g := e.Group("/group")
+	g.SetErrorHandler(func(c Context, err error) {
+		c.RespondNotFoundE(err)
+	})
+	g.GET("/default", failing)
+	g.GET("/route", WithErrorHandler(func(c Context, err error) {
+		c.RespondConflictE(err)
+	}), failing)
+
+	tests := map[string]int{
+		"/engine":        http.StatusBadRequest,
+		"/group/default": http.StatusNotFound,
+		"/group/route":   http.StatusConflict,
+	}
+	for path, expected := range tests {
+		if w := serve(e, http.MethodGet, path); w.Code != expected {
+			t.Fatalf("expected %d for %s, got %d", expected, path, w.Code)
+		}
+	}
+}
+
+func TestContext_Errors(t *testing.T) {
+	e := New()
+	errFlush := errors.New("flush failed")
+	var recorded []error
+	var published []error
+	e.Events().Subscribe(TopicRequestCompleted, func(ev BusEvent) {
+		published = ev.Payload.(RequestEvent).Errors
+	})
+	e.Use(func(c Context) {
+		c.Next()
+		recorded = c.Errors()
+	})
+	e.GET("/", func(c Context) {
+		c.HandleError(errNotFound)
+		c.Error(nil)
+		c.Error(errFlush)
+	})
+
+	serve(e, http.MethodGet, "/")
+	if len(recorded) != 2 || recorded[0] != errNotFound || recorded[1] != errFlush {
+		t.Fatal("expected recorded errors, got", recorded)
+	}
+	if len(published) != 2 {
+		t.Fatal("expected errors in request event, got", published)
+	}
+}
+
+// joinedErrors aggregates errors like errors.Join.
+type joinedErrors []error
+
+func (e joinedErrors) Error() string {
+	return "joined"
+}
+
+func (e joinedErrors) Unwrap() []error {
+	return e
+}
+
+func TestDefaultErrorHandler_MultiError(t *testing.T) {
+	e := New()
+	e.GET("/joined", func(c Context) {
+		c.HandleError(joinedErrors{
+			NewBadRequestError("name is required"),
+			joinedErrors{NewNotFoundError("customer not found"), nil},
+		})
+	})
+	e.GET("/unknown", func(c Context) {
+		c.HandleError(joinedErrors{NewBadRequestError("name is required"), errors.New("connection refused")})
+	})
+
+	w := serve(e, http.MethodGet, "/joined")
+	if w.Code != http.StatusNotFound {
+		t.Fatal("expected status of highest priority error, got", w.Code)
+	}
+	if w.Body.String() != `{"error":"customer not found","messages":["name is required","customer not found"]}` {
+		t.Fatal("expected all messages, got", w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/unknown"); w.Code != http.StatusInternalServerError {
+		t.Fatal("expected unknown errors to take precedence, got", w.Code)
+	}
+
+	e.UseProblemJSON()
+	w = serve(e, http.MethodGet, "/unknown")
+	if w.Body.String() != `{"instance":"/unknown","messages":["name is required"],"status":500,"title":"Internal Server Error","type":"about:blank"}` {
+		t.Fatal("expected problem without unknown messages, got", w.Body.String())
+	}
+}
+
+func TestDefaultErrorHandler_WrappedErrors(t *testing.T) {
+	e := New()
+	e.GET("/wrapped", func(c Context) {
+		c.HandleError(fmt.Errorf("wrap: %w", NewNotFoundError("customer not found")))
+	})
+	e.GET("/single", func(c Context) {
+		// like errors.Join with a single error, which requires Go 1.20
+		c.HandleError(joinedErrors{NewNotFoundError("customer not found")})
+	})
+
+	for _, path := range []string{"/wrapped", "/single"} {
+		w := serve(e, http.MethodGet, path)
+		if w.Code != http.StatusNotFound || w.Body.String() != `{"error":"customer not found"}` {
+			t.Error("expected 404 for", path, "got", w.Code, w.Body.String())
+		}
+	}
+}
@@ -4,11 +4,21 @@
 
 package jug
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+	"time"
+)
 
 type ResponseStatusError struct {
 	StatusCode int
 	Message    string
+	// Code is an optional machine-readable error code. It is included in the error response.
+	Code string
+	// RetryAfter sets the Retry-After header of the error response if positive.
+	RetryAfter time.Duration
+	// Err is the optional underlying error.
+	Err error
 }
 
 func NewResponseStatusError(statusCode int, message string) *ResponseStatusError {
@@ -18,6 +28,17 @@ func NewResponseStatusError(statusCode int, message string) *ResponseStatusError
 	}
 }
 
+// NewStatusError creates a ResponseStatusError with the given status code that wraps err.
+// The message of err is used as the error message.
+func NewStatusError(statusCode int, err error) *ResponseStatusError {
+	e := NewResponseStatusError(statusCode, http.StatusText(statusCode))
+	if err != nil {
+		e.Message = err.Error()
+		e.Err = err
+	}
+	return e
+}
+
 func NewBadRequestError(message string) *ResponseStatusError {
 	return NewResponseStatusError(http.StatusBadRequest, message)
 }
@@ -38,6 +59,51 @@ func NewConflictError(message string) *ResponseStatusError {
 	return NewResponseStatusError(http.StatusConflict, message)
 }
 
+func NewUnprocessableEntityError(message string) *ResponseStatusError {
+	return NewResponseStatusError(http.StatusUnprocessableEntity, message)
+}
+
+// NewTooManyRequestsError creates a 429 error. A positive retryAfter is sent in the Retry-After header.
+func NewTooManyRequestsError(message string, retryAfter time.Duration) *ResponseStatusError {
+	return NewResponseStatusError(http.StatusTooManyRequests, message).WithRetryAfter(retryAfter)
+}
+
+func NewInternalServerError(message string) *ResponseStatusError {
+	return NewResponseStatusError(http.StatusInternalServerError, message)
+}
+
+func NewNotImplementedError(message string) *ResponseStatusError {
+	return NewResponseStatusError(http.StatusNotImplemented, message)
+}
+
+func NewServiceUnavailableError(message string) *ResponseStatusError {
+	return NewResponseStatusError(http.StatusServiceUnavailable, message)
+}
+
+// WithCode sets the machine-readable error code and returns the error.
+func (e *ResponseStatusError) WithCode(code string) *ResponseStatusError {
+	e.Code = code
+	return e
+}
+
+// WithRetryAfter sets the duration sent in the Retry-After header and returns the error.
+func (e *ResponseStatusError) WithRetryAfter(d time.Duration) *ResponseStatusError {
+	e.RetryAfter = d
+	return e
+}
+
 func (e *ResponseStatusError) Error() string {
 	return e.Message
 }
+
+func (e *ResponseStatusError) Unwrap() error {
+	return e.Err
+}
+
+// isConfigurationError reports whether err is caused by a misconfiguration, e.g. an invalid default tag.
+// Configuration errors are passed to the error handler instead of being answered with 400.
+func isConfigurationError(err error) bool {
+	var de *invalidDefaultError
+	var ce *unknownColumnError
+	return errors.As(err, &de) || errors.As(err, &ce)
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestResponseStatusError_Constructors(t *testing.T) {
+	tests := []struct {
+		err    *ResponseStatusError
+		status int
+	}{
+		{NewUnprocessableEntityError("invalid"), http.StatusUnprocessableEntity},
+		{NewTooManyRequestsError("slow down", time.Minute), http.StatusTooManyRequests},
+		{NewInternalServerError("broken"), http.StatusInternalServerError},
+		{NewNotImplementedError("later"), http.StatusNotImplemented},
+		{NewServiceUnavailableError("maintenance"), http.StatusServiceUnavailable},
+		{NewStatusError(http.StatusBadGateway, nil), http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		if tt.err.StatusCode != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.err.Message, tt.status, tt.err.StatusCode)
+		}
+	}
+
+	cause := errors.New("upstream failed")
+	err := NewStatusError(http.StatusBadGateway, cause)
+	if err.Message != "upstream failed" || !errors.Is(err, cause) {
+		t.Fatal("expected wrapped error, got", err)
+	}
+	if NewStatusError(http.StatusBadGateway, nil).Message != "Bad Gateway" {
+		t.Fatal("expected status text as message")
+	}
+}
+
+func TestResponseStatusError_HandleError(t *testing.T) {
+	e := New()
+	e.GET("/coded", func(c Context) {
+		c.HandleError(NewUnprocessableEntityError("order is closed").WithCode("order_closed"))
+	})
+	e.GET("/limited", func(c Context) {
+		c.HandleError(NewTooManyRequestsError("slow down", 30*time.Second))
+	})
+
+	w := serve(e, http.MethodGet, "/coded")
+	if w.Code != http.StatusUnprocessableEntity || w.Body.String() != `{"code":"order_closed","error":"order is closed"}` {
+		t.Fatal("expected coded error response, got", w.Code, w.Body.String())
+	}
+	w = serve(e, http.MethodGet, "/limited")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "30" {
+		t.Fatal("expected 429 with Retry-After, got", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	e.UseProblemJSON()
+	w = serve(e, http.MethodGet, "/coded")
+	if w.Body.String() != `{"code":"order_closed","detail":"order is closed","instance":"/coded","status":422,"title":"Unprocessable Entity","type":"about:blank"}` {
+		t.Fatal("expected code extension in problem, got", w.Body.String())
+	}
+}
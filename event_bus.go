@@ -0,0 +1,129 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gin-gonic/gin"
+	"sync"
+	"time"
+)
+
+// Topics of the events published by the engine.
+const (
+	// TopicRequestCompleted is published after each request with a RequestEvent payload.
+	TopicRequestCompleted = "request.completed"
+	// TopicRequestFailed is published after each request answered with a 5xx status with a RequestEvent payload.
+	TopicRequestFailed = "request.failed"
+	// TopicRouteRegistered is published for each registered route with a RouteEvent payload.
+	TopicRouteRegistered = "route.registered"
+)
+
+// BusEvent is an event published on an EventBus.
+type BusEvent struct {
+	Topic   string
+	Payload any
+}
+
+// RequestEvent describes a handled request.
+type RequestEvent struct {
+	Method   string
+	Route    string
+	Path     string
+	Status   int
+	Duration time.Duration
+	ClientIP string
+	// Errors are the errors recorded on the request, see Context.Errors.
+	Errors []error
+}
+
+// RouteEvent describes a registered route.
+type RouteEvent struct {
+	Methods []string
+	Path    string
+}
+
+// EventBus delivers events to subscribers synchronously, in the order they subscribed.
+// Subscribers run on the publishing goroutine and should return quickly.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]*subscription
+}
+
+type subscription struct {
+	handler func(e BusEvent)
+}
+
+// NewEventBus creates an event bus.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[string][]*subscription),
+	}
+}
+
+// Subscribe subscribes a handler to a topic. The returned function cancels the subscription.
+func (b *EventBus) Subscribe(topic string, handler func(e BusEvent)) func() {
+	s := &subscription{handler: handler}
+	b.mu.Lock()
+	b.subscribers[topic] = append(b.subscribers[topic], s)
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subscribers[topic]
+		for i, e := range subs {
+			if e == s {
+				b.subscribers[topic] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers an event to all subscribers of the topic.
+func (b *EventBus) Publish(topic string, payload any) {
+	b.mu.RLock()
+	subs := b.subscribers[topic]
+	b.mu.RUnlock()
+	e := BusEvent{Topic: topic, Payload: payload}
+	for _, s := range subs {
+		s.handler(e)
+	}
+}
+
+// HasSubscribers reports whether a topic has subscribers.
+func (b *EventBus) HasSubscribers(topic string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscribers[topic]) > 0
+}
+
+// publishRequestEvents publishes the request events of the engine.
+func publishRequestEvents(config *engineConfig) gin.HandlerFunc {
+	bus := config.events
+	return func(c *gin.Context) {
+		completed := bus.HasSubscribers(TopicRequestCompleted)
+		failed := bus.HasSubscribers(TopicRequestFailed)
+		if !completed && !failed {
+			return
+		}
+		start := time.Now()
+		c.Next()
+		e := RequestEvent{
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Path:     c.Request.URL.Path,
+			Status:   c.Writer.Status(),
+			Duration: time.Since(start),
+			ClientIP: config.clientIP(c),
+			Errors:   requestErrors(c),
+		}
+		if completed {
+			bus.Publish(TopicRequestCompleted, e)
+		}
+		if failed && e.Status >= 500 {
+			bus.Publish(TopicRequestFailed, e)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	b := NewEventBus()
+	received := make([]any, 0)
+	unsubscribe := b.Subscribe("a", func(e BusEvent) {
+		received = append(received, e.Payload)
+	})
+	b.Publish("a", 1)
+	b.Publish("b", 2)
+	unsubscribe()
+	b.Publish("a", 3)
+	if len(received) != 1 || received[0] != 1 {
+		t.Fatal("expected a single event, got", received)
+	}
+}
+
+func TestEngine_Events(t *testing.T) {
+	e := New()
+	routes := make([]RouteEvent, 0)
+	requests := make([]RequestEvent, 0)
+	failures := 0
+	e.Events().Subscribe(TopicRouteRegistered, func(ev BusEvent) {
+		routes = append(routes, ev.Payload.(RouteEvent))
+	})
+	e.Events().Subscribe(TopicRequestCompleted, func(ev BusEvent) {
+		requests = append(requests, ev.Payload.(RequestEvent))
+	})
+	e.Events().Subscribe(TopicRequestFailed, func(ev BusEvent) {
+		failures++
+	})
+	e.Group("/api").GET("/items/:id", func(c Context) {
+		c.RespondInternalServerError("boom")
+	})
+
+	serve(e, http.MethodGet, "/api/items/1")
+	if len(routes) != 1 || routes[0].Path != "/api/items/:id" {
+		t.Fatal("expected route event, got", routes)
+	}
+	if len(requests) != 1 || requests[0].Route != "/api/items/:id" || requests[0].Status != http.StatusInternalServerError {
+		t.Fatal("expected request event, got", requests)
+	}
+	if failures != 1 {
+		t.Fatal("expected a failure event, got", failures)
+	}
+}
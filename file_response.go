@@ -0,0 +1,76 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+// serveFile serves a file opened from a file system. Range requests are supported if the file is seekable.
+func (w *contextWrapper) serveFile(f fs.File, name string) {
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		w.HandleError(err)
+		return
+	}
+	if info.IsDir() {
+		w.HandleError(NewNotFoundError("file not found"))
+		return
+	}
+	if rs, ok := f.(io.ReadSeeker); ok {
+		w.Content(name, info.ModTime(), rs)
+		return
+	}
+	w.streamContent(name, info.Size(), info.ModTime(), f)
+}
+
+// streamContent copies r to the response. It is used for content that cannot seek and therefore does not support ranges.
+func (w *contextWrapper) streamContent(name string, size int64, modtime time.Time, r io.Reader) {
+	header := w.c.Writer.Header()
+	if len(header.Get("Content-Type")) == 0 {
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if len(contentType) == 0 {
+			contentType = "application/octet-stream"
+		}
+		header.Set("Content-Type", contentType)
+	}
+	if size >= 0 {
+		header.Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	if !modtime.IsZero() {
+		header.Set("Last-Modified", modtime.UTC().Format(http.TimeFormat))
+	}
+	w.c.Status(http.StatusOK)
+	if w.c.Request.Method == http.MethodHead {
+		return
+	}
+	_, _ = io.Copy(w.c.Writer, r)
+}
+
+// openFailed answers an error returned when opening a file to serve.
+func (w *contextWrapper) openFailed(err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		w.HandleError(NewNotFoundError("file not found"))
+		return
+	}
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, fs.ErrInvalid) {
+		w.HandleError(NewForbiddenError("access denied"))
+		return
+	}
+	w.HandleError(err)
+}
+
+// attachmentDisposition formats a Content-Disposition header for a download. Non ASCII names are encoded as per RFC 2231.
+func attachmentDisposition(filename string) string {
+	return mime.FormatMediaType("attachment", map[string]string{"filename": filename})
+}
@@ -0,0 +1,194 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"testing/iotest"
+	"time"
+)
+
+func TestContext_File(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "hello.txt")
+	if err := os.WriteFile(file, []byte("hello world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	e.GET("/file", func(c Context) { c.File(file) })
+	e.GET("/missing", func(c Context) { c.File(filepath.Join(dir, "missing.txt")) })
+
+	w := serve(e, http.MethodGet, "/file")
+	if w.Code != http.StatusOK || w.Body.String() != "hello world" {
+		t.Fatal("expected file content, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Fatal("expected text content type, got", ct)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/file", nil)
+	r.Header.Set("Range", "bytes=6-")
+	w = serveRequest(e, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "world" {
+		t.Fatal("expected partial content, got", w.Code, w.Body.String())
+	}
+
+	if w := serve(e, http.MethodGet, "/missing"); w.Code != http.StatusNotFound {
+		t.Fatal("expected 404 for missing file, got", w.Code)
+	}
+}
+
+func TestContext_FileFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/readme.md": &fstest.MapFile{Data: []byte("# readme")},
+	}
+	e := New()
+	e.GET("/files/*name", func(c Context) { c.FileFromFS(c.Param("name"), fsys) })
+
+	w := serve(e, http.MethodGet, "/files/docs/readme.md")
+	if w.Code != http.StatusOK || w.Body.String() != "# readme" {
+		t.Fatal("expected file content, got", w.Code, w.Body.String())
+	}
+	for _, p := range []string{"/files/docs", "/files/missing.md", "/files/docs/../../etc/passwd"} {
+		if w := serve(e, http.MethodGet, p); w.Code != http.StatusNotFound {
+			t.Fatal("expected 404 for", p, "got", w.Code)
+		}
+	}
+}
+
+func TestContext_Attachment(t *testing.T) {
+	e := New()
+	e.GET("/seeker", func(c Context) {
+		c.Attachment(bytes.NewReader([]byte("a,b\n1,2\n")), "report.csv", "")
+	})
+	e.GET("/stream", func(c Context) {
+		c.Attachment(strings.NewReader("data"), "Übersicht.bin", "application/x-custom")
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/seeker", nil)
+	r.Header.Set("Range", "bytes=0-2")
+	w := serveRequest(e, r)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "a,b" {
+		t.Fatal("expected partial content, got", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename=report.csv` {
+		t.Fatal("unexpected Content-Disposition", cd)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatal("expected content type from filename, got", ct)
+	}
+
+	w = serve(e, http.MethodGet, "/stream")
+	if w.Code != http.StatusOK || w.Body.String() != "data" {
+		t.Fatal("expected streamed content, got", w.Code, w.Body.String())
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename*=utf-8''%C3%9Cbersicht.bin` {
+		t.Fatal("unexpected Content-Disposition", cd)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-custom" {
+		t.Fatal("expected given content type, got", ct)
+	}
+}
+
+func TestContext_Content(t *testing.T) {
+	modtime := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	e := New()
+	handler := func(c Context) {
+		c.Content("video.bin", modtime, strings.NewReader("0123456789"))
+	}
+	e.GET("/content", handler)
+	e.HEAD("/content", handler)
+
+	request := func(method string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/content", nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		return serveRequest(e, r)
+	}
+
+	w := request(http.MethodGet, nil)
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatal("expected full content, got", w.Code, w.Body.String())
+	}
+	if ar := w.Header().Get("Accept-Ranges"); ar != "bytes" {
+		t.Fatal("expected Accept-Ranges bytes, got", ar)
+	}
+	lastModified := w.Header().Get("Last-Modified")
+	if lastModified != modtime.Format(http.TimeFormat) {
+		t.Fatal("unexpected Last-Modified", lastModified)
+	}
+
+	w = request(http.MethodGet, map[string]string{"Range": "bytes=4-7"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "4567" {
+		t.Fatal("expected partial content, got", w.Code, w.Body.String())
+	}
+	if cr := w.Header().Get("Content-Range"); cr != "bytes 4-7/10" {
+		t.Fatal("unexpected Content-Range", cr)
+	}
+
+	w = request(http.MethodGet, map[string]string{"Range": "bytes=4-7", "If-Range": lastModified})
+	if w.Code != http.StatusPartialContent {
+		t.Fatal("expected partial content for matching If-Range, got", w.Code)
+	}
+	w = request(http.MethodGet, map[string]string{"Range": "bytes=4-7", "If-Range": modtime.Add(-time.Hour).Format(http.TimeFormat)})
+	if w.Code != http.StatusOK || w.Body.String() != "0123456789" {
+		t.Fatal("expected full content for stale If-Range, got", w.Code, w.Body.String())
+	}
+
+	w = request(http.MethodGet, map[string]string{"Range": "bytes=20-"})
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatal("expected 416, got", w.Code)
+	}
+
+	w = request(http.MethodHead, nil)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Fatal("expected empty HEAD response, got", w.Code, w.Body.String())
+	}
+	if cl := w.Header().Get("Content-Length"); cl != "10" {
+		t.Fatal("expected Content-Length for HEAD, got", cl)
+	}
+}
+
+func TestContext_RespondOkReader(t *testing.T) {
+	e := New()
+	e.GET("/known", func(c Context) {
+		c.RespondOkReader("text/csv", 11, iotest.OneByteReader(strings.NewReader("id,name\n1,a")))
+	})
+	e.GET("/unknown", func(c Context) {
+		pr, pw := io.Pipe()
+		go func() {
+			for _, line := range []string{"id,name\n", "1,a\n", "2,b\n"} {
+				_, _ = pw.Write([]byte(line))
+			}
+			_ = pw.Close()
+		}()
+		c.RespondOkReader("text/csv", -1, pr)
+	})
+
+	w := serve(e, http.MethodGet, "/known")
+	if w.Code != http.StatusOK || w.Body.String() != "id,name\n1,a" {
+		t.Fatal("expected the streamed body, got", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Length") != "11" || w.Header().Get("Content-Type") != "text/csv" {
+		t.Error("expected content headers, got", w.Header())
+	}
+
+	w = serve(e, http.MethodGet, "/unknown")
+	if w.Body.String() != "id,name\n1,a\n2,b\n" {
+		t.Fatal("expected the streamed body, got", w.Body.String())
+	}
+	if _, ok := w.Header()["Content-Length"]; ok {
+		t.Error("expected no Content-Length for unknown lengths, got", w.Header())
+	}
+}
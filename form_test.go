@@ -0,0 +1,104 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type formTestSignup struct {
+	Name  string `form:"name" validate:"required"`
+	Age   int    `form:"age"`
+	Terms bool   `form:"terms"`
+}
+
+func TestContext_MustBindForm(t *testing.T) {
+	e := New()
+	e.POST("/signup", func(c Context) {
+		var form formTestSignup
+		if c.MustBindForm(&form) {
+			c.String(http.StatusOK, "%s %d %t %s", form.Name, form.Age, form.Terms, c.FormValue("source"))
+		}
+	})
+	post := func(values url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return serveRequest(e, r)
+	}
+
+	w := post(url.Values{"name": {"jug"}, "age": {"3"}, "terms": {"true"}, "source": {"ad"}})
+	if w.Code != http.StatusOK || w.Body.String() != "jug 3 true ad" {
+		t.Fatal("expected bound form, got", w.Code, w.Body.String())
+	}
+	if w := post(url.Values{"name": {"jug"}, "age": {"three"}}); w.Code != http.StatusBadRequest {
+		t.Fatal("expected 400 for invalid values, got", w.Code)
+	}
+	if w := post(url.Values{"age": {"3"}}); w.Code != http.StatusBadRequest {
+		t.Fatal("expected 400 for failed validation, got", w.Code)
+	}
+}
+
+func multipartRequest(t *testing.T, fields map[string]string, files map[string]string) *http.Request {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for k, v := range fields {
+		_ = mw.WriteField(k, v)
+	}
+	for name, content := range files {
+		fw, err := mw.CreateFormFile(name, name+".txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte(content))
+	}
+	_ = mw.Close()
+	r := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestContext_FormFile(t *testing.T) {
+	dir := t.TempDir()
+	e := New()
+	e.POST("/upload", func(c Context) {
+		file, err := c.FormFile("document")
+		if errors.Is(err, http.ErrMissingFile) {
+			c.HandleError(NewBadRequestError("document is required"))
+			return
+		}
+		if err != nil {
+			c.HandleError(err)
+			return
+		}
+		if err := c.SaveUploadedFile(file, filepath.Join(dir, file.Filename)); err != nil {
+			c.HandleError(err)
+			return
+		}
+		c.String(http.StatusCreated, "%s %d %s", file.Filename, file.Size, c.FormValue("title"))
+	})
+
+	w := serveRequest(e, multipartRequest(t, map[string]string{"title": "report"}, map[string]string{"document": "hello"}))
+	if w.Code != http.StatusCreated || w.Body.String() != "document.txt 5 report" {
+		t.Fatal("expected the uploaded file, got", w.Code, w.Body.String())
+	}
+	saved, err := os.ReadFile(filepath.Join(dir, "document.txt"))
+	if err != nil || string(saved) != "hello" {
+		t.Fatal("expected the file to be saved, got", string(saved), err)
+	}
+
+	w = serveRequest(e, multipartRequest(t, map[string]string{"title": "report"}, nil))
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"document is required"}` {
+		t.Fatal("expected 400 for a missing file, got", w.Code, w.Body.String())
+	}
+}
@@ -5,36 +5,121 @@
 package jug
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/protobuf/proto"
+	"html/template"
 	"io"
+	"io/fs"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// engineConfig holds engine wide settings. It is shared by all routers and contexts of an engine.
+type engineConfig struct {
+	encoders         []encoderEntry
+	location         *time.Location
+	htmlRenderer     Renderer
+	queryParsingMode QueryParsingMode
+	shutdown         *shutdownSignal
+	shutdownEvent    *Event
+	preflights       map[string]bool
+	cachePolicies    map[string]CachePolicy
+	namedRoutes      map[string]*ginRoute
+	routes           []*ginRoute
+	mounts           []engineMount
+	errorHandler     ErrorHandler
+	problemJSON      bool
+	trustedProxies   []*net.IPNet
+	clientIPStrategy ClientIPStrategy
+	metrics          Metrics
+	events           *EventBus
+	maxBodySize      int64
+	jsonCodec        JSONCodec
+	providers        map[string]Constructor
+	tasks            *taskPool
+	hosts            []*virtualHost
+}
+
+func newEngineConfig() *engineConfig {
+	return &engineConfig{
+		encoders:      defaultEncoders(),
+		location:      time.UTC,
+		shutdown:      newShutdownSignal(),
+		preflights:    make(map[string]bool),
+		cachePolicies: make(map[string]CachePolicy),
+		namedRoutes:   make(map[string]*ginRoute),
+		events:        NewEventBus(),
+		jsonCodec:     StdJSONCodec,
+		providers:     make(map[string]Constructor),
+		tasks:         newTaskPool(defaultTaskWorkers, defaultTaskQueueSize),
+	}
+}
+
 type ginEngine struct {
 	engine       *gin.Engine
+	config       *engineConfig
 	pathRegistry *PathRegistry
 	groups       []*ginRouterGroup
-}
-
-func defaultGinEngine() Engine {
-	return &ginEngine{
-		engine:       gin.Default(),
-		pathRegistry: NewPathRegistry(),
-		groups:       make([]*ginRouterGroup, 0),
-	}
-}
-
-func newGinEngine() Engine {
+	cors         *corsPolicy
+	plugins      map[string]bool
+	health       *Health
+	lifecycle    lifecycle
+	serverLock   sync.Mutex
+	servers      []gracefulServer
+	serverConfig serverConfig
+	http2        HTTP2Config
+	// middleware holds the middleware registered with Use, it applies to virtual hosts created afterwards.
+	middleware []HandlerFunc
+}
+
+func defaultGinEngine(opts []Option) Engine {
+	engine := gin.New()
+	engine.Use(gin.Logger())
+	r := newGinEngineWith(engine, opts)
+	r.Use(Recovery(nil), SecureHeaders(DefaultSecureConfig()))
+	return r
+}
+
+func newGinEngine(opts []Option) Engine {
 	gin.SetMode(gin.ReleaseMode)
+	return newGinEngineWith(gin.New(), opts)
+}
+
+func newGinEngineWith(engine *gin.Engine, opts []Option) *ginEngine {
+	// the Context's Deadline, Done, Err and Value methods use the request context
+	engine.ContextWithFallback = true
+	// gin trusts forwarding headers of all peers by default
+	_ = engine.SetTrustedProxies(nil)
+	config := newEngineConfig()
+	engine.Use(suppressBodies, limitBodies(config), applyCachePolicies(config), publishRequestEvents(config), finishResponses, routeHosts(config))
+	server := serverConfig{}
+	for _, opt := range opts {
+		opt(&server)
+	}
 	return &ginEngine{
-		engine:       gin.New(),
+		engine:       engine,
+		config:       config,
+		serverConfig: server,
 		pathRegistry: NewPathRegistry(),
 		groups:       make([]*ginRouterGroup, 0),
+		plugins:      make(map[string]bool),
 	}
 }
 
@@ -43,233 +128,883 @@ func (r *ginEngine) EnableDebugMode() {
 }
 
 func (r *ginEngine) Use(middleware ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.engine.Use(MapMany(middleware, wrapHandler)...)}
+	r.middleware = append(r.middleware, middleware...)
+	return &ginRoutesRouter{routes: r.engine.Use(MapMany(middleware, r.config.wrapHandler)...), config: r.config, addPath: r.addPath}
 }
 
 func (r *ginEngine) Group(relativePath string, handlers ...HandlerFunc) RouterGroup {
-	g := newGinRouterGroup(r.engine.Group(relativePath, MapMany(handlers, wrapHandler)...))
+	g := newGinRouterGroup(r.engine, r.engine.Group(relativePath, MapMany(handlers, r.config.wrapHandler)...), r.config, r.cors)
 	r.groups = append(r.groups, g)
 	return g
 }
 
-func (r *ginEngine) Any(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD")
-	return &ginRoutesRouter{routes: r.engine.Any(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) CORS(policy CORSPolicy) {
+	r.cors = newCORSPolicy(policy, r.config)
+	r.engine.Use(r.cors.handle)
+}
+
+// addPath records a path in the registry and registers a preflight handler if a CORS policy is set.
+// It panics if a method is already registered for the path.
+func (r *ginEngine) addPath(relativePath string, methods ...string) {
+	r.pathRegistry.checkDuplicates(relativePath, relativePath, methods)
+	r.cors.register(r.engine, relativePath, r.pathRegistry, relativePath, methods)
+	r.pathRegistry.Add(relativePath, methods...)
+}
+
+func (r *ginEngine) Any(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD")
+	route := r.config.newRoute("/", relativePath, registryMethods...)
+	return &ginRoutesRouter{routes: r.engine.Any(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) GET(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "GET")
+	route := r.config.newRoute("/", relativePath, "GET")
+	return &ginRoutesRouter{routes: r.engine.GET(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) POST(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "POST")
+	route := r.config.newRoute("/", relativePath, "POST")
+	return &ginRoutesRouter{routes: r.engine.POST(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) PUT(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PUT")
+	route := r.config.newRoute("/", relativePath, "PUT")
+	return &ginRoutesRouter{routes: r.engine.PUT(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) DELETE(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "DELETE")
+	route := r.config.newRoute("/", relativePath, "DELETE")
+	return &ginRoutesRouter{routes: r.engine.DELETE(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) PATCH(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PATCH")
+	route := r.config.newRoute("/", relativePath, "PATCH")
+	return &ginRoutesRouter{routes: r.engine.PATCH(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) OPTIONS(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "OPTIONS")
+	route := r.config.newRoute("/", relativePath, "OPTIONS")
+	return &ginRoutesRouter{routes: r.engine.OPTIONS(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) HEAD(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "HEAD")
+	route := r.config.newRoute("/", relativePath, "HEAD")
+	return &ginRoutesRouter{routes: r.engine.HEAD(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginEngine) Static(relativePath string, root string) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.engine.Static(relativePath, root), config: r.config, addPath: r.addPath, route: r.config.newRoute("/", staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginEngine) GET(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "GET")
-	return &ginRoutesRouter{routes: r.engine.GET(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) StaticFS(relativePath string, fsys fs.FS) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.engine.StaticFS(relativePath, http.FS(fsys)), config: r.config, addPath: r.addPath, route: r.config.newRoute("/", staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginEngine) POST(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "POST")
-	return &ginRoutesRouter{routes: r.engine.POST(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) SPA(relativePath string, fsys fs.FS, index string) Route {
+	if path.Clean("/"+relativePath) == "/" {
+		// a catch-all at the root would conflict with all other routes
+		r.engine.NoRoute(spaHandler(fsys, index))
+		return &ginRoutesRouter{routes: r.engine, config: r.config, addPath: r.addPath}
+	}
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: registerSPA(r.engine, relativePath, fsys, index), config: r.config, addPath: r.addPath, route: r.config.newRoute("/", staticPattern(relativePath), "GET", "HEAD")}
+}
+
+func (r *ginEngine) SetTrustedProxies(cidrs []string) error {
+	trusted := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		n, err := parseNetwork(cidr)
+		if err != nil {
+			return err
+		}
+		trusted = append(trusted, n)
+	}
+	if err := r.engine.SetTrustedProxies(cidrs); err != nil {
+		return err
+	}
+	r.config.trustedProxies = trusted
+	return nil
+}
+
+func (r *ginEngine) SetClientIPStrategy(strategy ClientIPStrategy) {
+	r.config.clientIPStrategy = strategy
 }
 
-func (r *ginEngine) PUT(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "PUT")
-	return &ginRoutesRouter{routes: r.engine.PUT(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) UseProblemJSON() {
+	r.config.problemJSON = true
 }
 
-func (r *ginEngine) DELETE(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "DELETE")
-	return &ginRoutesRouter{routes: r.engine.DELETE(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) SetErrorHandler(handler ErrorHandler) {
+	r.config.errorHandler = handler
 }
 
-func (r *ginEngine) PATCH(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "PATCH")
-	return &ginRoutesRouter{routes: r.engine.PATCH(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) Mount(prefix string, h http.Handler) {
+	r.addPath(mountPattern(prefix), registryMethods...)
+	r.config.mount(r.engine, "/", prefix, h)
 }
 
-func (r *ginEngine) OPTIONS(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "OPTIONS")
-	return &ginRoutesRouter{routes: r.engine.OPTIONS(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.engine.ServeHTTP(w, req)
 }
 
-func (r *ginEngine) HEAD(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "HEAD")
-	return &ginRoutesRouter{routes: r.engine.HEAD(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginEngine) Route(name string) (RouteInfo, bool) {
+	return r.config.routeInfo(name)
+}
+
+func (r *ginEngine) Routes() []RouteInfo {
+	return r.config.routeInfos()
+}
+
+func (r *ginEngine) URL(name string, params ...string) (string, error) {
+	return r.config.buildURL(name, params...)
 }
 
 func (r *ginEngine) NoMethod(handlers ...HandlerFunc) {
 	r.engine.HandleMethodNotAllowed = true
-	r.engine.NoMethod(MapMany(handlers, wrapHandler)...)
+	r.engine.NoMethod(MapMany(handlers, r.config.wrapHandler)...)
 }
 
 func (r *ginEngine) NoRoute(handlers ...HandlerFunc) {
-	r.engine.NoRoute(MapMany(handlers, wrapHandler)...)
+	r.engine.NoRoute(MapMany(handlers, r.config.wrapHandler)...)
 }
 
 func (r *ginEngine) ExpandMethods() {
-	expandMethods(r, r.pathRegistry)
+	expandMethods(r.engine, r.pathRegistry, r.config)
 	for _, g := range r.groups {
 		g.expandMethods()
 	}
+	r.config.expandMounts()
+}
+
+func (r *ginEngine) Version(version string, opts ...VersionOption) RouterGroup {
+	return r.Versioned(VersioningConfig{}).Version(version, opts...)
+}
+
+func (r *ginEngine) Versioned(cfg VersioningConfig) *APIVersions {
+	return newAPIVersions(r, "/", r.config, cfg)
+}
+
+func (r *ginEngine) CheckRoutes() []RouteIssue {
+	return r.checkRoutes()
+}
+
+func (r *ginEngine) RegisterEncoder(contentType string, enc Encoder) {
+	r.config.registerEncoder(contentType, enc)
+}
+
+func (r *ginEngine) SetJSONCodec(codec JSONCodec) {
+	r.config.jsonCodec = codec
+	r.config.registerEncoder("application/json; charset=utf-8", jsonCodecEncoder(codec))
+}
+
+func (r *ginEngine) SetLocation(loc *time.Location) {
+	r.config.location = loc
+}
+
+func (r *ginEngine) SetQueryParsingMode(mode QueryParsingMode) {
+	r.config.queryParsingMode = mode
+}
+
+func (r *ginEngine) LoadHTMLGlob(pattern string) error {
+	t, err := template.ParseGlob(pattern)
+	if err != nil {
+		return err
+	}
+	r.SetHTMLRenderer(NewTemplateRenderer(t))
+	return nil
+}
+
+func (r *ginEngine) LoadHTMLFS(fsys fs.FS, patterns ...string) error {
+	t, err := template.ParseFS(fsys, patterns...)
+	if err != nil {
+		return err
+	}
+	r.SetHTMLRenderer(NewTemplateRenderer(t))
+	return nil
+}
+
+func (r *ginEngine) SetHTMLRenderer(renderer Renderer) {
+	r.config.htmlRenderer = renderer
+}
+
+func (r *ginEngine) Install(plugins ...Plugin) error {
+	return installPlugins(r, r.plugins, plugins)
+}
+
+func (r *ginEngine) Events() *EventBus {
+	return r.config.events
+}
+
+func (r *ginEngine) Health() *Health {
+	if r.health == nil {
+		r.health = newHealth(r.config.shutdown)
+		r.health.mount(r)
+	}
+	return r.health
+}
+
+func (r *ginEngine) SetMaxBodySize(n int64) {
+	r.config.maxBodySize = n
+}
+
+func (r *ginEngine) Provide(key string, constructor Constructor) {
+	r.config.providers[key] = constructor
+}
+
+func (r *ginEngine) SetTaskPool(workers int, queueSize int) {
+	r.config.tasks = newTaskPool(workers, queueSize)
+}
+
+func (r *ginEngine) SetMetrics(metrics Metrics) {
+	r.config.metrics = metrics
+}
+
+func (r *ginEngine) SetShutdownEvent(event *Event) {
+	r.config.shutdownEvent = event
+}
+
+func (r *ginEngine) OnStart(hook LifecycleHook) {
+	r.lifecycle.onStart(hook)
+}
+
+func (r *ginEngine) OnStop(hook LifecycleHook) {
+	r.lifecycle.onStop(hook)
 }
 
 func (r *ginEngine) Run(addr ...string) error {
-	return r.engine.Run(addr...)
+	if err := r.lifecycle.runStart(context.Background()); err != nil {
+		return err
+	}
+	listeners := make([]net.Listener, 0, len(addr))
+	for _, a := range resolveAddresses(addr) {
+		l, err := net.Listen("tcp", a)
+		if err != nil {
+			closeListeners(listeners)
+			return r.stopOnError(err)
+		}
+		listeners = append(listeners, l)
+	}
+	return r.serve(listeners, r.engine)
+}
+
+func (r *ginEngine) RunListener(listeners ...net.Listener) error {
+	if len(listeners) == 0 {
+		panic("jug: RunListener needs at least one listener")
+	}
+	if err := r.lifecycle.runStart(context.Background()); err != nil {
+		return err
+	}
+	return r.serve(listeners, r.engine)
+}
+
+func (r *ginEngine) RunUnix(path string) error {
+	if err := r.lifecycle.runStart(context.Background()); err != nil {
+		return err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return r.stopOnError(err)
+	}
+	defer os.Remove(path)
+	return r.serve([]net.Listener{l}, r.engine)
+}
+
+// stopOnError runs the stop hooks when the engine fails to listen or serve after the start hooks succeeded.
+// It returns err, followed by the errors of failed stop hooks. If err is nil, the engine was shut down by Shutdown,
+// which runs the stop hooks.
+func (r *ginEngine) stopOnError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if sErr := r.lifecycle.runStop(context.Background()); sErr != nil {
+		return fmt.Errorf("%w; %v", err, sErr)
+	}
+	return err
+}
+
+// serve serves requests on all listeners until the engine is shut down or one of the listeners fails.
+// A failing listener closes the others.
+func (r *ginEngine) serve(listeners []net.Listener, handler http.Handler) error {
+	server := r.serverConfig.newServer(listeners[0].Addr().String(), handler)
+	r.addServer(server)
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			errs <- server.Serve(l)
+		}(l)
+	}
+	var err error
+	for range listeners {
+		if sErr := <-errs; !errors.Is(sErr, http.ErrServerClosed) && err == nil {
+			err = sErr
+			_ = server.Close()
+		}
+	}
+	return r.stopOnError(err)
+}
+
+// gracefulServer is a server stopped by Shutdown.
+type gracefulServer interface {
+	Shutdown(ctx context.Context) error
+}
+
+func (r *ginEngine) addServer(server gracefulServer) {
+	r.serverLock.Lock()
+	defer r.serverLock.Unlock()
+	r.servers = append(r.servers, server)
+}
+
+func closeListeners(listeners []net.Listener) {
+	for _, l := range listeners {
+		_ = l.Close()
+	}
+}
+
+func (r *ginEngine) Shutdown(ctx context.Context) error {
+	r.config.shutdown.trigger()
+	r.serverLock.Lock()
+	servers := append([]gracefulServer(nil), r.servers...)
+	r.serverLock.Unlock()
+	var err error
+	for _, server := range servers {
+		if sErr := server.Shutdown(ctx); err == nil {
+			err = sErr
+		}
+	}
+	if wErr := r.config.shutdown.wait(ctx); err == nil {
+		err = wErr
+	}
+	if tErr := r.config.tasks.close(ctx); err == nil {
+		err = tErr
+	}
+	if hErr := r.lifecycle.runStop(ctx); err == nil {
+		err = hErr
+	}
+	return err
+}
+
+func resolveAddresses(addr []string) []string {
+	if len(addr) > 0 {
+		return addr
+	}
+	if port := os.Getenv("PORT"); len(port) > 0 {
+		return []string{":" + port}
+	}
+	return []string{":8080"}
 }
 
 type ginRoutesRouter struct {
 	routes gin.IRoutes
+	config *engineConfig
+	// addPath records paths in the registry of the engine or group the routes are registered on.
+	addPath func(relativePath string, methods ...string)
+	// route is the route registered by the method returning the router. It is nil for routers returned by Use.
+	route *ginRoute
+}
+
+// ginRoute identifies a registered route.
+type ginRoute struct {
+	path    string
+	methods []string
+	name    string
+	meta    map[string]any
+	// handler is the last handler of the route. It is nil for static routes.
+	handler HandlerFunc
+	// canary splits requests between handler and a canary handler, see Route.Canary.
+	canary  *canarySplit
+	budget  RouteBudget
+	headers []HeaderRequirement
+	// enforce enforces the header requirements and the budget before the handlers of the route.
+	// It is nil if the route has neither.
+	enforce HandlerFunc
+}
+
+// newRoute creates a route and publishes its registration.
+func (cfg *engineConfig) newRoute(basePath string, relativePath string, methods ...string) *ginRoute {
+	route := &ginRoute{
+		path:    joinPaths(basePath, relativePath),
+		methods: methods,
+	}
+	cfg.routes = append(cfg.routes, route)
+	cfg.events.Publish(TopicRouteRegistered, RouteEvent{Methods: methods, Path: route.path})
+	return route
+}
+
+// routeHandlers maps the handlers of a route. The last handler is served by the route, so Route.Canary can split it.
+// The handlers are preceded by the enforcement of the route's header requirements and budget.
+func (cfg *engineConfig) routeHandlers(route *ginRoute, handlers []HandlerFunc) []gin.HandlerFunc {
+	mapped := MapMany(handlers, cfg.wrapHandler)
+	if n := len(handlers); n > 0 {
+		route.handler = handlers[n-1]
+		mapped[n-1] = cfg.wrapHandler(route.serve)
+		mapped = append([]gin.HandlerFunc{cfg.enforceRoute(route)}, mapped...)
+	}
+	return mapped
+}
+
+// enforceRoute enforces the header requirements and the budget of a route before its handlers. They are declared
+// after registration, so the handler is part of every route.
+func (cfg *engineConfig) enforceRoute(route *ginRoute) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if route.enforce != nil {
+			route.enforce(wrapContext(c, cfg))
+		}
+	}
+}
+
+// updateEnforcement rebuilds the handler enforcing the header requirements and the budget of the route.
+func (route *ginRoute) updateEnforcement() {
+	checks := make([]HandlerFunc, len(route.headers))
+	for i, h := range route.headers {
+		checks[i] = RequireHeader(h.Name, h.pattern)
+	}
+	budget := route.budget.middleware()
+	route.enforce = func(c Context) {
+		gc, ok := ginContextOf(c)
+		for _, check := range checks {
+			if check(c); ok && gc.IsAborted() {
+				return
+			}
+		}
+		budget(c)
+	}
+}
+
+// serve serves a request with the last handler of the route, or its canary.
+func (route *ginRoute) serve(c Context) {
+	if route.canary == nil {
+		route.handler(c)
+		return
+	}
+	route.canary.serve(c, route.handler)
+}
+
+// basePath returns the base path of the group routes were registered on.
+func basePath(routes gin.IRoutes) string {
+	if g, ok := routes.(interface{ BasePath() string }); ok {
+		return g.BasePath()
+	}
+	return "/"
+}
+
+func (r *ginRoutesRouter) Cache(maxAge time.Duration, public bool) Route {
+	if r.route == nil {
+		panic("jug: Cache must be called on a route")
+	}
+	r.config.setCachePolicy(r.route, CachePolicy{MaxAge: maxAge, Public: public})
+	return r
+}
+
+func (r *ginRoutesRouter) Name(name string) Route {
+	if r.route == nil {
+		panic("jug: Name must be called on a route")
+	}
+	r.config.nameRoute(r.route, name)
+	return r
+}
+
+func (r *ginRoutesRouter) Meta(key string, value any) Route {
+	if r.route == nil {
+		panic("jug: Meta must be called on a route")
+	}
+	if r.route.meta == nil {
+		r.route.meta = make(map[string]any)
+	}
+	r.route.meta[key] = value
+	return r
+}
+
+func (r *ginRoutesRouter) Budget(budget RouteBudget) Route {
+	if r.route == nil || r.route.handler == nil {
+		panic("jug: Budget requires a route with handlers")
+	}
+	r.route.budget = budget
+	r.route.updateEnforcement()
+	return r
+}
+
+func (r *ginRoutesRouter) RequireHeader(name string, pattern *regexp.Regexp) Route {
+	if r.route == nil || r.route.handler == nil {
+		panic("jug: RequireHeader requires a route with handlers")
+	}
+	r.route.headers = append(r.route.headers, newHeaderRequirement(name, pattern))
+	r.route.updateEnforcement()
+	return r
+}
+
+func (r *ginRoutesRouter) Canary(handler HandlerFunc, percent float64) Route {
+	return r.CanaryWith(handler, CanaryConfig{Percent: percent})
+}
+
+func (r *ginRoutesRouter) CanaryWith(handler HandlerFunc, config CanaryConfig) Route {
+	if r.route == nil || r.route.handler == nil {
+		panic("jug: Canary requires a route with handlers")
+	}
+	r.route.canary = newCanarySplit(handler, config)
+	return r
 }
 
 func (r *ginRoutesRouter) Use(middleware ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.Use(MapMany(middleware, wrapHandler)...)}
+	return &ginRoutesRouter{routes: r.routes.Use(MapMany(middleware, r.config.wrapHandler)...), config: r.config, addPath: r.addPath}
+}
+
+func (r *ginRoutesRouter) Any(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, registryMethods...)
+	route := r.config.newRoute(basePath(r.routes), relativePath, registryMethods...)
+	return &ginRoutesRouter{routes: r.routes.Any(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRoutesRouter) GET(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "GET")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "GET")
+	return &ginRoutesRouter{routes: r.routes.GET(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRoutesRouter) POST(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "POST")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "POST")
+	return &ginRoutesRouter{routes: r.routes.POST(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) Any(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.Any(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) PUT(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PUT")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "PUT")
+	return &ginRoutesRouter{routes: r.routes.PUT(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) GET(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.GET(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) DELETE(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "DELETE")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "DELETE")
+	return &ginRoutesRouter{routes: r.routes.DELETE(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) POST(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.POST(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) PATCH(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PATCH")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "PATCH")
+	return &ginRoutesRouter{routes: r.routes.PATCH(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) PUT(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.PUT(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) OPTIONS(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "OPTIONS")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "OPTIONS")
+	return &ginRoutesRouter{routes: r.routes.OPTIONS(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) DELETE(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.DELETE(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) HEAD(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "HEAD")
+	route := r.config.newRoute(basePath(r.routes), relativePath, "HEAD")
+	return &ginRoutesRouter{routes: r.routes.HEAD(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRoutesRouter) PATCH(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.PATCH(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) Static(relativePath string, root string) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.routes.Static(relativePath, root), config: r.config, addPath: r.addPath, route: r.config.newRoute(basePath(r.routes), staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginRoutesRouter) OPTIONS(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.OPTIONS(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) StaticFS(relativePath string, fsys fs.FS) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.routes.StaticFS(relativePath, http.FS(fsys)), config: r.config, addPath: r.addPath, route: r.config.newRoute(basePath(r.routes), staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginRoutesRouter) HEAD(relativePath string, handlers ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.routes.HEAD(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRoutesRouter) SPA(relativePath string, fsys fs.FS, index string) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: registerSPA(r.routes, relativePath, fsys, index), config: r.config, addPath: r.addPath, route: r.config.newRoute(basePath(r.routes), staticPattern(relativePath), "GET", "HEAD")}
 }
 
 type ginRouterGroup struct {
+	engine       *gin.Engine
 	group        *gin.RouterGroup
+	config       *engineConfig
 	pathRegistry *PathRegistry
 	groups       []*ginRouterGroup
+	cors         *corsPolicy
+	// onAdd is notified of the paths added to groups served by an inner router, see APIVersions.
+	onAdd func(relativePath string, methods ...string)
 }
 
-func newGinRouterGroup(group *gin.RouterGroup) *ginRouterGroup {
+func newGinRouterGroup(engine *gin.Engine, group *gin.RouterGroup, config *engineConfig, cors *corsPolicy) *ginRouterGroup {
 	return &ginRouterGroup{
+		engine:       engine,
 		group:        group,
+		config:       config,
 		pathRegistry: NewPathRegistry(),
 		groups:       make([]*ginRouterGroup, 0),
+		cors:         cors,
 	}
 }
 
 func (r *ginRouterGroup) Use(middleware ...HandlerFunc) Router {
-	return &ginRoutesRouter{routes: r.group.Use(MapMany(middleware, wrapHandler)...)}
+	return &ginRoutesRouter{routes: r.group.Use(MapMany(middleware, r.config.wrapHandler)...), config: r.config, addPath: r.addPath}
 }
 
 func (r *ginRouterGroup) Group(relativePath string, handlers ...HandlerFunc) RouterGroup {
-	g := newGinRouterGroup(r.group.Group(relativePath, MapMany(handlers, wrapHandler)...))
+	g := newGinRouterGroup(r.engine, r.group.Group(relativePath, MapMany(handlers, r.config.wrapHandler)...), r.config, r.cors)
+	if r.onAdd != nil {
+		g.onAdd = func(p string, methods ...string) {
+			r.onAdd(joinPaths(relativePath, p), methods...)
+		}
+	}
 	r.groups = append(r.groups, g)
 	return g
 }
 
-func (r *ginRouterGroup) Any(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD")
-	return &ginRoutesRouter{routes: r.group.Any(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) Versioned(cfg VersioningConfig) *APIVersions {
+	return newAPIVersions(r, r.group.BasePath(), r.config, cfg)
+}
+
+func (r *ginRouterGroup) CORS(policy CORSPolicy) {
+	r.cors = newCORSPolicy(policy, r.config)
+	r.group.Use(r.cors.handle)
 }
 
-func (r *ginRouterGroup) GET(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "GET")
-	return &ginRoutesRouter{routes: r.group.GET(relativePath, MapMany(handlers, wrapHandler)...)}
+// addPath records a path in the registry and registers a preflight handler if a CORS policy is set.
+// Preflight handlers are registered on the engine, so that group middleware like authentication is skipped.
+// It panics if a method is already registered for the path.
+func (r *ginRouterGroup) addPath(relativePath string, methods ...string) {
+	r.pathRegistry.checkDuplicates(joinPaths(r.group.BasePath(), relativePath), relativePath, methods)
+	r.cors.register(r.engine, joinPaths(r.group.BasePath(), relativePath), r.pathRegistry, relativePath, methods)
+	r.pathRegistry.Add(relativePath, methods...)
+	if r.onAdd != nil {
+		r.onAdd(relativePath, methods...)
+	}
+}
+
+func (r *ginRouterGroup) Any(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, registryMethods...)
+	return &ginRoutesRouter{routes: r.group.Any(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) GET(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "GET")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "GET")
+	return &ginRoutesRouter{routes: r.group.GET(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) POST(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "POST")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "POST")
+	return &ginRoutesRouter{routes: r.group.POST(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) PUT(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PUT")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "PUT")
+	return &ginRoutesRouter{routes: r.group.PUT(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "DELETE")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "DELETE")
+	return &ginRoutesRouter{routes: r.group.DELETE(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "PATCH")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "PATCH")
+	return &ginRoutesRouter{routes: r.group.PATCH(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
+}
+
+func (r *ginRouterGroup) OPTIONS(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "OPTIONS")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "OPTIONS")
+	return &ginRoutesRouter{routes: r.group.OPTIONS(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRouterGroup) POST(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "POST")
-	return &ginRoutesRouter{routes: r.group.POST(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) Route {
+	r.addPath(relativePath, "HEAD")
+	route := r.config.newRoute(r.group.BasePath(), relativePath, "HEAD")
+	return &ginRoutesRouter{routes: r.group.HEAD(relativePath, r.config.routeHandlers(route, handlers)...), config: r.config, addPath: r.addPath, route: route}
 }
 
-func (r *ginRouterGroup) PUT(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "PUT")
-	return &ginRoutesRouter{routes: r.group.PUT(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) Static(relativePath string, root string) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.group.Static(relativePath, root), config: r.config, addPath: r.addPath, route: r.config.newRoute(r.group.BasePath(), staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginRouterGroup) DELETE(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "DELETE")
-	return &ginRoutesRouter{routes: r.group.DELETE(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) StaticFS(relativePath string, fsys fs.FS) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: r.group.StaticFS(relativePath, http.FS(fsys)), config: r.config, addPath: r.addPath, route: r.config.newRoute(r.group.BasePath(), staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginRouterGroup) PATCH(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "PATCH")
-	return &ginRoutesRouter{routes: r.group.PATCH(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) SPA(relativePath string, fsys fs.FS, index string) Route {
+	r.addPath(staticPattern(relativePath), "GET", "HEAD")
+	return &ginRoutesRouter{routes: registerSPA(r.group, relativePath, fsys, index), config: r.config, addPath: r.addPath, route: r.config.newRoute(r.group.BasePath(), staticPattern(relativePath), "GET", "HEAD")}
 }
 
-func (r *ginRouterGroup) OPTIONS(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "OPTIONS")
-	return &ginRoutesRouter{routes: r.group.OPTIONS(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) SetErrorHandler(handler ErrorHandler) {
+	r.group.Use(r.config.wrapHandler(WithErrorHandler(handler)))
 }
 
-func (r *ginRouterGroup) HEAD(relativePath string, handlers ...HandlerFunc) Router {
-	r.pathRegistry.Add(relativePath, "HEAD")
-	return &ginRoutesRouter{routes: r.group.HEAD(relativePath, MapMany(handlers, wrapHandler)...)}
+func (r *ginRouterGroup) Mount(prefix string, h http.Handler) {
+	r.addPath(mountPattern(prefix), registryMethods...)
+	r.config.mount(r.group, r.group.BasePath(), prefix, h)
 }
 
 func (r *ginRouterGroup) expandMethods() {
-	expandMethods(r, r.pathRegistry)
+	expandMethods(r.group, r.pathRegistry, r.config)
 	for _, g := range r.groups {
 		g.expandMethods()
 	}
 }
 
-func expandMethods(router Router, registry *PathRegistry) {
+// expandMethods registers MethodNotAllowed for all methods without a handler and answers OPTIONS requests.
+// Both responses carry an Allow header listing the handled methods.
+// The methods are marked as expanded in the registry, so they are not reported as handled.
+func expandMethods(routes gin.IRoutes, registry *PathRegistry, config *engineConfig) {
 	for _, p := range registry.Paths() {
-		if !registry.Get(p, "GET") {
-			router.GET(p, MethodNotAllowed)
-		}
-		if !registry.Get(p, "POST") {
-			router.POST(p, MethodNotAllowed)
+		for _, m := range registryMethods {
+			if registry.Get(p, m) {
+				continue
+			}
+			registry.addExpanded(p, m)
+			if m == http.MethodOptions {
+				routes.Handle(m, p, config.wrapHandler(allowedMethodsHandler(registry, p)))
+			} else {
+				routes.Handle(m, p, config.wrapHandler(methodNotAllowedHandler(registry, p)))
+			}
 		}
-		if !registry.Get(p, "PUT") {
-			router.PUT(p, MethodNotAllowed)
+	}
+}
+
+// methodNotAllowedHandler responds with 405 and the methods allowed for a path.
+func methodNotAllowedHandler(registry *PathRegistry, relativePath string) HandlerFunc {
+	return func(c Context) {
+		c.SetHeader("Allow", allowHeader(registry, relativePath))
+		MethodNotAllowed(c)
+	}
+}
+
+// allowedMethodsHandler answers OPTIONS requests with 204 and the methods allowed for a path.
+func allowedMethodsHandler(registry *PathRegistry, relativePath string) HandlerFunc {
+	return func(c Context) {
+		c.SetHeader("Allow", allowHeader(registry, relativePath))
+		c.RespondNoContent()
+	}
+}
+
+// allowHeader lists the methods handled for a path. OPTIONS is always included, since ExpandMethods answers it.
+// The registry is read on each request, so routes added later are included.
+func allowHeader(registry *PathRegistry, relativePath string) string {
+	methods := registry.Methods(relativePath)
+	if !contains(methods, http.MethodOptions) {
+		methods = append(methods, http.MethodOptions)
+	}
+	return strings.Join(methods, ", ")
+}
+
+func staticPattern(relativePath string) string {
+	return path.Join(relativePath, "/*filepath")
+}
+
+func registerSPA(routes gin.IRoutes, relativePath string, fsys fs.FS, index string) gin.IRoutes {
+	handler := spaHandler(fsys, index)
+	pattern := staticPattern(relativePath)
+	routes.GET(pattern, handler)
+	return routes.HEAD(pattern, handler)
+}
+
+// spaHandler serves files from fsys. Page requests for files that cannot be found are answered with the index file.
+// Other requests, e.g. for missing assets or by API clients, are answered with 404.
+func spaHandler(fsys fs.FS, index string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Status(http.StatusNotFound)
+			return
 		}
-		if !registry.Get(p, "DELETE") {
-			router.DELETE(p, MethodNotAllowed)
+		name := c.Param("filepath")
+		if len(c.FullPath()) == 0 {
+			name = c.Request.URL.Path
 		}
-		if !registry.Get(p, "PATCH") {
-			router.PATCH(p, MethodNotAllowed)
+		name = strings.TrimPrefix(path.Clean("/"+name), "/")
+		if stat, err := fs.Stat(fsys, name); err == nil && !stat.IsDir() {
+			serveFSFile(c, fsys, name)
+			return
 		}
-		if !registry.Get(p, "OPTIONS") {
-			router.OPTIONS(p, MethodNotAllowed)
+		if !isPageRequest(c.Request, name) {
+			c.Status(http.StatusNotFound)
+			return
 		}
-		if !registry.Get(p, "HEAD") {
-			router.HEAD(p, MethodNotAllowed)
+		serveFSFile(c, fsys, index)
+	}
+}
+
+// isPageRequest reports whether a request is a browser navigation, i.e. accepts HTML and is not for a file with extension.
+func isPageRequest(r *http.Request, name string) bool {
+	return len(path.Ext(name)) == 0 && strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+func serveFSFile(c *gin.Context, fsys fs.FS, name string) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil || stat.IsDir() {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(f)
+		if err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
 		}
+		content = bytes.NewReader(data)
 	}
+	http.ServeContent(c.Writer, c.Request, stat.Name(), stat.ModTime(), content)
 }
 
 type handlerFuncWrapper struct {
-	f HandlerFunc
+	f      HandlerFunc
+	config *engineConfig
 }
 
-func wrapHandler(f HandlerFunc) gin.HandlerFunc {
+func (cfg *engineConfig) wrapHandler(f HandlerFunc) gin.HandlerFunc {
 	wrapper := &handlerFuncWrapper{
-		f: f,
+		f:      f,
+		config: cfg,
 	}
 	return wrapper.handle
 }
 
 func (w *handlerFuncWrapper) handle(c *gin.Context) {
-	w.f(wrapContext(c))
+	w.f(wrapContext(c, w.config))
 }
 
 type contextWrapper struct {
-	c *gin.Context
+	c      *gin.Context
+	config *engineConfig
 }
 
-func wrapContext(c *gin.Context) Context {
-	return &contextWrapper{c: c}
+func wrapContext(c *gin.Context, config *engineConfig) Context {
+	return &contextWrapper{c: c, config: config}
+}
+
+func (w *contextWrapper) ginContext() *gin.Context {
+	return w.c
+}
+
+// ginContextOf returns the gin context of a context created by the engine.
+// It allows built-in middleware to access the response writer.
+func ginContextOf(c Context) (*gin.Context, bool) {
+	w, ok := c.(interface{ ginContext() *gin.Context })
+	if !ok {
+		return nil, false
+	}
+	return w.ginContext(), true
 }
 
 func (w *contextWrapper) Get(name string) (any, bool) {
@@ -293,29 +1028,45 @@ func (w *contextWrapper) QueryArray(key string) []string {
 }
 
 func (w *contextWrapper) IntQuery(key string) (int, error) {
-	val := w.c.Query(key)
-	if len(val) == 0 {
-		return 0, nil
-	}
-	return strconv.Atoi(val)
+	return w.DefaultIntQuery(key, 0)
+}
+
+func (w *contextWrapper) Int64Query(key string) (int64, error) {
+	return w.DefaultInt64Query(key, 0)
+}
+
+func (w *contextWrapper) UIntQuery(key string) (uint, error) {
+	return w.DefaultUIntQuery(key, 0)
+}
+
+func (w *contextWrapper) UInt64Query(key string) (uint64, error) {
+	return w.DefaultUInt64Query(key, 0)
+}
+
+func (w *contextWrapper) Float64Query(key string) (float64, error) {
+	return w.DefaultFloat64Query(key, 0)
+}
+
+func (w *contextWrapper) DurationQuery(key string) (time.Duration, error) {
+	return w.DefaultDurationQuery(key, 0)
 }
 
 func (w *contextWrapper) BoolQuery(key string) (bool, error) {
-	val := w.c.Query(key)
-	if len(val) == 0 {
-		return false, nil
-	}
-	return strconv.ParseBool(val)
+	return w.DefaultBoolQuery(key, false)
 }
 
 func (w *contextWrapper) Iso8601DateQuery(key string) (*time.Time, error) {
+	return w.Iso8601DateQueryIn(key, w.config.location)
+}
+
+func (w *contextWrapper) Iso8601DateQueryIn(key string, loc *time.Location) (*time.Time, error) {
 	val := w.c.Query(key)
 	if len(val) == 0 {
 		return nil, nil
 	}
-	t, err := time.Parse("2006-01-02", val)
+	t, err := time.ParseInLocation("2006-01-02", val, loc)
 	if err != nil {
-		return nil, err
+		return nil, w.checkQuery(key, err)
 	}
 	return &t, nil
 }
@@ -327,17 +1078,22 @@ func (w *contextWrapper) Iso8601DateTimeQuery(key string) (*time.Time, error) {
 	}
 	t, err := time.Parse(time.RFC3339, val)
 	if err != nil {
-		return nil, err
+		return nil, w.checkQuery(key, err)
 	}
 	return &t, nil
 }
 
-func (w *contextWrapper) StringQuery(key string) (string, error) {
+func (w *contextWrapper) PeriodQuery(key string) (*Period, error) {
 	val := w.c.Query(key)
 	if len(val) == 0 {
-		return "", nil
+		return nil, nil
 	}
-	return url.QueryUnescape(val)
+	p, err := ParsePeriod(val, w.config.location)
+	return p, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) StringQuery(key string) (string, error) {
+	return w.DefaultStringQuery(key, "")
 }
 
 func (w *contextWrapper) DefaultQuery(key string, defaultValue string) string {
@@ -349,31 +1105,178 @@ func (w *contextWrapper) DefaultIntQuery(key string, defaultValue int) (int, err
 	if len(val) == 0 {
 		return defaultValue, nil
 	}
-	return strconv.Atoi(val)
+	i, err := strconv.Atoi(val)
+	return i, w.checkQuery(key, err)
 }
 
-func (w *contextWrapper) DefaultBoolQuery(key string, defaultValue bool) (bool, error) {
+func (w *contextWrapper) DefaultInt64Query(key string, defaultValue int64) (int64, error) {
 	val := w.c.Query(key)
 	if len(val) == 0 {
 		return defaultValue, nil
 	}
-	return strconv.ParseBool(val)
+	i, err := strconv.ParseInt(val, 10, 64)
+	return i, w.checkQuery(key, err)
 }
 
-func (w *contextWrapper) DefaultStringQuery(key string, defaultValue string) (string, error) {
+func (w *contextWrapper) DefaultUIntQuery(key string, defaultValue uint) (uint, error) {
 	val := w.c.Query(key)
 	if len(val) == 0 {
 		return defaultValue, nil
 	}
-	return url.QueryUnescape(val)
-}
-
-func (w *contextWrapper) GetHeader(key string) string {
-	return w.c.GetHeader(key)
+	i, err := strconv.ParseUint(val, 10, strconv.IntSize)
+	return uint(i), w.checkQuery(key, err)
 }
 
-func (w *contextWrapper) Param(key string) string {
-	return w.c.Param(key)
+func (w *contextWrapper) DefaultUInt64Query(key string, defaultValue uint64) (uint64, error) {
+	val := w.c.Query(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseUint(val, 10, 64)
+	return i, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) DefaultFloat64Query(key string, defaultValue float64) (float64, error) {
+	val := w.c.Query(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	return f, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) DefaultDurationQuery(key string, defaultValue time.Duration) (time.Duration, error) {
+	val := w.c.Query(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(val)
+	return d, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) DefaultBoolQuery(key string, defaultValue bool) (bool, error) {
+	val := w.c.Query(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(val)
+	return b, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) DefaultStringQuery(key string, defaultValue string) (string, error) {
+	val := w.c.Query(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	str, err := url.QueryUnescape(val)
+	return str, w.checkQuery(key, err)
+}
+
+func (w *contextWrapper) Pagination(defaults PaginationDefaults) (Page, error) {
+	page, key, err := parsePage(w.c.Request.URL.Query(), defaults)
+	if err != nil {
+		return page, w.checkQuery(key, NewBadRequestError(fmt.Sprintf("invalid value for query parameter %s", key)))
+	}
+	return page, nil
+}
+
+// checkQuery responds with 400 and aborts the request if err is not nil and strict query parsing is enabled.
+// It returns err unchanged.
+func (w *contextWrapper) checkQuery(key string, err error) error {
+	if err == nil {
+		return nil
+	}
+	mode := w.config.queryParsingMode
+	if v, ok := w.c.Get(queryParsingModeKey); ok {
+		mode = v.(QueryParsingMode)
+	}
+	if mode == StrictQueryParsing {
+		w.RespondBadRequestE(fmt.Errorf("invalid value for query parameter %s", key))
+		w.c.Abort()
+	}
+	return err
+}
+
+func (w *contextWrapper) GetHeader(key string) string {
+	return w.c.GetHeader(key)
+}
+
+func (w *contextWrapper) ClientIP() string {
+	return w.config.clientIP(w.c)
+}
+
+func (w *contextWrapper) BasicAuth() (string, string, bool) {
+	return w.c.Request.BasicAuth()
+}
+
+func (w *contextWrapper) CachePolicy() (CachePolicy, bool) {
+	v, ok := w.c.Get(cachePolicyKey)
+	if !ok {
+		return CachePolicy{}, false
+	}
+	return v.(CachePolicy), true
+}
+
+func (w *contextWrapper) SetCacheControl(policy CachePolicy) {
+	w.SetHeader("Cache-Control", policy.String())
+}
+
+func (w *contextWrapper) ConnInfo() ConnInfo {
+	return connInfo(w.c.Request)
+}
+
+func (w *contextWrapper) Claims() (Claims, bool) {
+	v, ok := w.c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := v.(Claims)
+	return claims, ok
+}
+
+func (w *contextWrapper) Param(key string) string {
+	return w.c.Param(key)
+}
+
+func (w *contextWrapper) IntParam(key string) (int, error) {
+	return strconv.Atoi(w.c.Param(key))
+}
+
+func (w *contextWrapper) Int64Param(key string) (int64, error) {
+	return strconv.ParseInt(w.c.Param(key), 10, 64)
+}
+
+func (w *contextWrapper) UUIDParam(key string) (string, error) {
+	val := w.c.Param(key)
+	if !uuidRegex.MatchString(val) {
+		return "", fmt.Errorf("invalid UUID %q", val)
+	}
+	return strings.ToLower(val), nil
+}
+
+func (w *contextWrapper) MustIntParam(key string) (int, bool) {
+	i, err := w.IntParam(key)
+	return i, w.checkParam(key, err)
+}
+
+func (w *contextWrapper) MustInt64Param(key string) (int64, bool) {
+	i, err := w.Int64Param(key)
+	return i, w.checkParam(key, err)
+}
+
+func (w *contextWrapper) MustUUIDParam(key string) (string, bool) {
+	id, err := w.UUIDParam(key)
+	return id, w.checkParam(key, err)
+}
+
+// checkParam responds with 400 and aborts the request if err is not nil. It returns whether err is nil.
+func (w *contextWrapper) checkParam(key string, err error) bool {
+	if err == nil {
+		return true
+	}
+	w.RespondBadRequestE(fmt.Errorf("invalid value for path parameter %s", key))
+	w.c.Abort()
+	return false
 }
 
 func (w *contextWrapper) GetRawData() ([]byte, error) {
@@ -381,63 +1284,259 @@ func (w *contextWrapper) GetRawData() ([]byte, error) {
 }
 
 func (w *contextWrapper) MayBindJSON(obj any) bool {
-	return w.MayBindJSONV(obj, func() error {
-		val, ok := obj.(Validatable)
-		if ok {
-			return val.Validate()
-		}
-		return nil
+	return w.mayBindWith(obj, jsonBinding{codec: w.config.jsonCodec}, func() error {
+		return w.validate(obj)
 	})
 }
 
 func (w *contextWrapper) MayBindJSONV(obj any, validator func() error) bool {
-	if err := w.c.ShouldBindJSON(obj); err != nil {
+	return w.mayBindWith(obj, jsonBinding{codec: w.config.jsonCodec}, validator)
+}
+
+func (w *contextWrapper) MustBindJSON(obj any) bool {
+	return w.mustBindWith(obj, jsonBinding{codec: w.config.jsonCodec}, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindJSONStrict(obj any) bool {
+	return w.mustBindWith(obj, jsonBinding{codec: w.config.jsonCodec, strict: true}, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindJSONV(obj any, validator func() error) bool {
+	return w.mustBindWith(obj, jsonBinding{codec: w.config.jsonCodec}, func() error {
+		if err := validator(); err != nil {
+			return err
+		}
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) BindNDJSONEach(fn func(dec Decoder) error) bool {
+	err := eachNDJSONLine(w.c.Request.Body, w.config.jsonCodec, fn)
+	if err == nil {
+		return true
+	}
+	var nve *recordValidationError
+	var le *NDJSONLineError
+	if errors.As(err, &nve) {
+		w.validationFailed(nve.err)
+	} else if errors.As(err, &le) {
+		w.bindingFailed(err)
+	} else if _, ok := bodyTooLarge(err); ok {
+		w.bindingFailed(err)
+	} else {
+		w.HandleError(err)
+	}
+	return false
+}
+
+func (w *contextWrapper) BindCSV(dest any) bool {
+	err := bindCSV(w.c.Request.Body, dest, w.config.location)
+	if err == nil {
+		return true
+	}
+	var rve *recordValidationError
+	var pe *csv.ParseError
+	var be *BindingError
+	if err == io.EOF {
+		w.RespondMissingRequestBody()
+	} else if errors.As(err, &rve) {
+		w.validationFailed(rve.err)
+	} else if errors.As(err, &pe) || errors.As(err, &be) {
+		w.bindingFailed(err)
+	} else if _, ok := bodyTooLarge(err); ok {
+		w.bindingFailed(err)
+	} else {
+		w.HandleError(err)
+	}
+	return false
+}
+
+func (w *contextWrapper) MayBindXML(obj any) bool {
+	return w.mayBindWith(obj, binding.XML, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindXML(obj any) bool {
+	return w.mustBindWith(obj, binding.XML, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MayBindYAML(obj any) bool {
+	return w.mayBindWith(obj, binding.YAML, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindYAML(obj any) bool {
+	return w.mustBindWith(obj, binding.YAML, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindProto(msg proto.Message) bool {
+	return w.mustBindWith(msg, binding.ProtoBuf, func() error {
+		return w.validate(msg)
+	})
+}
+
+func (w *contextWrapper) MustBindMsgPack(obj any) bool {
+	return w.mustBindWith(obj, binding.MsgPack, func() error {
+		return w.validate(obj)
+	})
+}
+
+func (w *contextWrapper) MustBindBody(obj any) bool {
+	b, ok := w.bodyBinding(obj)
+	if !ok {
+		w.respondE(http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %s", w.c.ContentType()))
+		w.c.Abort()
+		return false
+	}
+	return w.mustBindWith(obj, b, func() error {
+		return w.validate(obj)
+	})
+}
+
+// bodyBinding selects the binding for the content type of the request.
+func (w *contextWrapper) bodyBinding(obj any) (binding.Binding, bool) {
+	contentType := w.c.ContentType()
+	switch {
+	case len(contentType) == 0, contentType == "application/json", strings.HasSuffix(contentType, "+json"):
+		return jsonBinding{codec: w.config.jsonCodec}, true
+	case contentType == "application/xml", contentType == "text/xml", strings.HasSuffix(contentType, "+xml"):
+		return binding.XML, true
+	case contentType == "application/yaml", contentType == "application/x-yaml", contentType == "text/yaml":
+		return binding.YAML, true
+	case contentType == MsgPackContentType, contentType == "application/x-msgpack":
+		return binding.MsgPack, true
+	case contentType == ProtobufContentType, contentType == "application/protobuf":
+		_, ok := obj.(proto.Message)
+		return binding.ProtoBuf, ok
+	}
+	return nil, false
+}
+
+func (w *contextWrapper) mayBindWith(obj any, b binding.Binding, validator func() error) bool {
+	if err := w.c.ShouldBindWith(obj, b); err != nil {
 		if err == io.EOF {
 			return true
 		}
-		w.RespondBadRequestE(err)
+		w.bindingFailed(err)
 		return false
 	}
 	if err := validator(); err != nil {
-		w.RespondBadRequestE(err)
+		w.validationFailed(err)
 		return false
 	}
 	return true
 }
 
-func (w *contextWrapper) MustBindJSON(obj any) bool {
-	return w.MustBindJSONV(obj, func() error {
-		val, ok := obj.(Validatable)
-		if ok {
-			return val.Validate()
-		}
-		return nil
-	})
-}
-
-func (w *contextWrapper) MustBindJSONV(obj any, validator func() error) bool {
-	if err := w.c.ShouldBindJSON(obj); err != nil {
+func (w *contextWrapper) mustBindWith(obj any, b binding.Binding, validator func() error) bool {
+	if err := w.c.ShouldBindWith(obj, b); err != nil {
 		if err == io.EOF {
 			w.RespondMissingRequestBody()
 			return false
 		}
-		w.RespondBadRequestE(err)
+		w.bindingFailed(err)
 		return false
 	}
 	if err := validator(); err != nil {
-		w.RespondBadRequestE(err)
+		w.validationFailed(err)
 		return false
 	}
-	val, ok := obj.(Validatable)
-	if ok {
-		if err := val.Validate(); err != nil {
-			w.RespondBadRequestE(err)
+	return true
+}
+
+func (w *contextWrapper) MayBindQuery(obj any) bool {
+	if len(w.c.Request.URL.Query()) == 0 {
+		// only sets default values
+		if err := w.queryBinder(true).bind(obj); err != nil {
+			w.bindingFailed(err)
 			return false
 		}
+		return true
+	}
+	return w.MustBindQuery(obj)
+}
+
+func (w *contextWrapper) MustBindQuery(obj any) bool {
+	err := w.queryBinder(false).bind(obj)
+	if err != nil {
+		w.bindingFailed(err)
+		return false
+	}
+	if err := w.validate(obj); err != nil {
+		w.validationFailed(err)
+		return false
+	}
+	return true
+}
+
+// queryBinder creates a binder for the query parameters. Optional binders skip required checks.
+func (w *contextWrapper) queryBinder(optional bool) *valueBinder {
+	query := w.c.Request.URL.Query()
+	return &valueBinder{
+		tag:      "query",
+		location: w.config.location,
+		values: func(key string) ([]string, bool) {
+			v, ok := query[key]
+			return v, ok
+		},
+		optional: optional,
+	}
+}
+
+func (w *contextWrapper) MustBindForm(obj any) bool {
+	if err := w.parseForm(); err != nil {
+		w.bindingFailed(err)
+		return false
+	}
+	form := w.c.Request.PostForm
+	b := &valueBinder{
+		tag:      "form",
+		location: w.config.location,
+		values: func(key string) ([]string, bool) {
+			v, ok := form[key]
+			return v, ok
+		},
+	}
+	err := b.bind(obj)
+	if err != nil {
+		w.bindingFailed(err)
+		return false
+	}
+	if err := w.validate(obj); err != nil {
+		w.validationFailed(err)
+		return false
 	}
 	return true
 }
 
+func (w *contextWrapper) parseForm() error {
+	if _, err := w.c.MultipartForm(); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return err
+	}
+	return w.c.Request.ParseForm()
+}
+
+func (w *contextWrapper) FormValue(key string) string {
+	return w.c.PostForm(key)
+}
+
+func (w *contextWrapper) FormFile(name string) (*multipart.FileHeader, error) {
+	return w.c.FormFile(name)
+}
+
+func (w *contextWrapper) SaveUploadedFile(file *multipart.FileHeader, dst string) error {
+	return w.c.SaveUploadedFile(file, dst)
+}
+
 func (w *contextWrapper) Status(code int) Context {
 	w.c.Status(code)
 	return w
@@ -452,6 +1551,83 @@ func (w *contextWrapper) SetHeader(key string, value string) {
 	w.c.Writer.Header().Set(key, value)
 }
 
+func (w *contextWrapper) Flush() error {
+	if _, ok := unwrapResponseWriter(w.c.Writer).(http.Flusher); !ok {
+		return http.ErrNotSupported
+	}
+	w.c.Writer.Flush()
+	return nil
+}
+
+func (w *contextWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if _, ok := unwrapResponseWriter(w.c.Writer).(http.Hijacker); !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return w.c.Writer.Hijack()
+}
+
+func (w *contextWrapper) Push(target string, opts *http.PushOptions) error {
+	pusher := w.c.Writer.Pusher()
+	if pusher == nil {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
+
+func (w *contextWrapper) OnResponse(hook func(r *ResponseInfo)) {
+	iw := interceptWriterOf(w.c)
+	iw.hooks = append(iw.hooks, hook)
+}
+
+func (w *contextWrapper) BufferResponse() {
+	if w.c.Writer.Written() {
+		return
+	}
+	interceptWriterOf(w.c).buffered = true
+}
+
+func (w *contextWrapper) Locale() string {
+	return localeKey.GetOr(w, "")
+}
+
+func (w *contextWrapper) T(key string, args ...any) string {
+	if bundle, ok := bundleKey.Get(w); ok {
+		if message, ok := bundle.Translate(w.Locale(), key, args...); ok {
+			return message
+		}
+	}
+	return key
+}
+
+func (w *contextWrapper) NewValidator() *Validator {
+	v := NewValidator()
+	if bundle, ok := bundleKey.Get(w); ok {
+		v.Localize(bundle, w.Locale())
+	}
+	return v
+}
+
+// validate validates obj with a validator localized to the request locale.
+func (w *contextWrapper) validate(obj any) error {
+	return validateWith(w.NewValidator(), obj)
+}
+
+func (w *contextWrapper) SetTrailer(key string, value string) {
+	w.c.Writer.Header().Set(http.TrailerPrefix+key, value)
+}
+
+func (w *contextWrapper) EarlyHints(links ...string) {
+	if w.c.Writer.Written() {
+		return
+	}
+	header := w.c.Writer.Header()
+	for _, link := range links {
+		header.Add("Link", link)
+	}
+	// gin writers hold back the status until the body is written, informational responses are sent directly
+	unwrapResponseWriter(w.c.Writer).WriteHeader(http.StatusEarlyHints)
+}
+
 func (w *contextWrapper) SetContentType(value string) {
 	w.SetHeader("Content-Type", value)
 }
@@ -468,22 +1644,297 @@ func (w *contextWrapper) SetCookie(name string, value string, maxAge int, path s
 	w.c.SetCookie(name, value, maxAge, path, domain, secure, httpOnly)
 }
 
+func (w *contextWrapper) SetHTTPCookie(cookie *http.Cookie) {
+	http.SetCookie(w.c.Writer, cookie)
+}
+
+func (w *contextWrapper) Session() *Session {
+	v, ok := w.c.Get(sessionKey)
+	if !ok {
+		panic("jug: Sessions middleware is not installed")
+	}
+	s := v.(*Session)
+	// the session may be accessed from a different wrapper than the one created by the middleware
+	s.c = w
+	return s
+}
+
 func (w *contextWrapper) Stream(step func(w io.Writer) bool) bool {
+	if w.c.Request.Method == http.MethodHead {
+		w.c.Writer.WriteHeaderNow()
+		return false
+	}
 	return w.c.Stream(step)
 }
 
+func (w *contextWrapper) RespondJSONStream(items <-chan any) error {
+	return w.streamJSON("application/json; charset=utf-8", items, "[", ",", "]")
+}
+
+func (w *contextWrapper) RespondNDJSON(items <-chan any) error {
+	return w.streamJSON(NDJSONContentType, items, "", "", "")
+}
+
+// streamJSON writes the items received from items as JSON between start and end, separated by separator.
+// Newline delimited JSON items are terminated by a newline instead.
+func (w *contextWrapper) streamJSON(contentType string, items <-chan any, start string, separator string, end string) error {
+	w.c.Writer.Header().Set("Content-Type", contentType)
+	w.c.Status(http.StatusOK)
+	if w.c.Request.Method == http.MethodHead {
+		w.c.Writer.WriteHeaderNow()
+		return nil
+	}
+	if _, err := io.WriteString(w.c.Writer, start); err != nil {
+		return err
+	}
+	first := true
+	for {
+		select {
+		case <-w.c.Request.Context().Done():
+			return w.c.Request.Context().Err()
+		case item, ok := <-items:
+			if !ok {
+				_, err := io.WriteString(w.c.Writer, end)
+				return err
+			}
+			data, err := w.config.jsonCodec.Marshal(item)
+			if err != nil {
+				// the status is sent already, the response is left incomplete so that clients detect the failure
+				w.Error(err)
+				return err
+			}
+			if !first {
+				data = append([]byte(separator), data...)
+			}
+			first = false
+			if contentType == NDJSONContentType {
+				data = append(data, '\n')
+			}
+			if _, err := w.c.Writer.Write(data); err != nil {
+				return err
+			}
+			w.c.Writer.Flush()
+		}
+	}
+}
+
+func (w *contextWrapper) RespondCSV(rows any, opts ...CSVOption) error {
+	r, err := newCSVRows(rows)
+	if err != nil {
+		return err
+	}
+	cfg := newCSVConfig(opts)
+	w.c.Writer.Header().Set("Content-Type", CSVContentType)
+	if len(cfg.filename) > 0 {
+		w.c.Writer.Header().Set("Content-Disposition", attachmentDisposition(cfg.filename))
+	}
+	w.c.Status(http.StatusOK)
+	if w.c.Request.Method == http.MethodHead {
+		w.c.Writer.WriteHeaderNow()
+		return nil
+	}
+	return r.write(w.c.Request.Context(), w.c.Writer, w.c.Writer.Flush, cfg)
+}
+
 func (w *contextWrapper) SSEvent(name string, message any) {
 	w.c.SSEvent(name, message)
 }
 
+func (w *contextWrapper) EventStream(ctx context.Context, source <-chan Event) error {
+	header := w.c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.c.Status(http.StatusOK)
+	w.c.Writer.Flush()
+	if w.c.Request.Method == http.MethodHead {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.c.Request.Context().Done():
+			return w.c.Request.Context().Err()
+		case <-w.config.shutdown.Done():
+			if w.config.shutdownEvent != nil {
+				_ = writeEvent(w.c.Writer, *w.config.shutdownEvent)
+				w.c.Writer.Flush()
+			}
+			return ErrShuttingDown
+		case e, ok := <-source:
+			if !ok {
+				return nil
+			}
+			if err := writeEvent(w.c.Writer, e); err != nil {
+				return err
+			}
+			w.c.Writer.Flush()
+		}
+	}
+}
+
+func (w *contextWrapper) Go(task Task) error {
+	return w.config.tasks.submit(task)
+}
+
+func (w *contextWrapper) ShuttingDown() <-chan struct{} {
+	return w.config.shutdown.Done()
+}
+
+func (w *contextWrapper) LastEventID() string {
+	return w.c.GetHeader("Last-Event-ID")
+}
+
+func (w *contextWrapper) Proxy(target *url.URL, opts ...ProxyOption) {
+	proxy := newReverseProxy(target, func(err error) {
+		handleProxyError(w, err)
+	}, opts...)
+	proxy.ServeHTTP(proxyWriter{w.c.Writer}, w.c.Request)
+}
+
+func (w *contextWrapper) UpgradeWebSocket(opts ...WSOption) (WSConn, error) {
+	return upgradeWebSocket(w.c.Writer, w.c.Request, w.config.shutdown, opts...)
+}
+
 func (w *contextWrapper) Data(code int, contentType string, data []byte) {
 	w.c.Data(code, contentType, data)
 }
 
+func (w *contextWrapper) HTML(code int, name string, data any) {
+	if w.config.htmlRenderer == nil {
+		w.respondE(http.StatusInternalServerError, fmt.Errorf("no HTML renderer configured"))
+		return
+	}
+	var buf bytes.Buffer
+	if err := w.config.htmlRenderer.Render(&buf, name, data); err != nil {
+		w.respondE(http.StatusInternalServerError, err)
+		return
+	}
+	w.c.Data(code, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (w *contextWrapper) Respond(status int, obj any) {
+	w.respond(status, obj)
+}
+
 func (w *contextWrapper) RespondOk(obj any) {
 	w.respond(http.StatusOK, obj)
 }
 
+func (w *contextWrapper) RespondPage(items any, total int64, page Page) {
+	w.c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	if links := pageLinks(w.c.Request.URL, page, total); len(links) > 0 {
+		w.c.Header("Link", links)
+	}
+	w.RespondOk(PageResponse{
+		Items:  items,
+		Total:  total,
+		Page:   page.Number(),
+		Size:   page.Limit,
+		Offset: page.Offset,
+	})
+}
+
+func (w *contextWrapper) RespondOkData(contentType string, data []byte) {
+	w.c.Data(http.StatusOK, contentType, data)
+}
+
+func (w *contextWrapper) RespondOkReader(contentType string, length int64, r io.Reader) {
+	w.c.DataFromReader(http.StatusOK, length, contentType, r, nil)
+}
+
+func (w *contextWrapper) Content(name string, modtime time.Time, content io.ReadSeeker) {
+	http.ServeContent(w.c.Writer, w.c.Request, name, modtime, content)
+}
+
+func (w *contextWrapper) File(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		w.openFailed(err)
+		return
+	}
+	w.serveFile(f, filepath.Base(path))
+}
+
+func (w *contextWrapper) FileFromFS(name string, fsys fs.FS) {
+	name = strings.TrimPrefix(name, "/")
+	if !fs.ValidPath(name) {
+		w.HandleError(NewNotFoundError("file not found"))
+		return
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		w.openFailed(err)
+		return
+	}
+	w.serveFile(f, name)
+}
+
+func (w *contextWrapper) Attachment(reader io.Reader, filename string, contentType string) {
+	header := w.c.Writer.Header()
+	header.Set("Content-Disposition", attachmentDisposition(filename))
+	if len(contentType) > 0 {
+		header.Set("Content-Type", contentType)
+	}
+	if rs, ok := reader.(io.ReadSeeker); ok {
+		w.Content(filename, time.Time{}, rs)
+		return
+	}
+	w.streamContent(filename, -1, time.Time{}, reader)
+}
+
+func (w *contextWrapper) RespondOkXML(obj any) {
+	w.XML(http.StatusOK, obj)
+}
+
+func (w *contextWrapper) XML(code int, obj any) {
+	if obj == nil {
+		w.c.Status(code)
+	} else {
+		w.c.XML(code, obj)
+	}
+}
+
+func (w *contextWrapper) RespondProto(code int, msg proto.Message) {
+	if msg == nil {
+		w.c.Status(code)
+		return
+	}
+	w.encode(code, ProtobufContentType, ProtobufEncoder, msg)
+}
+
+func (w *contextWrapper) RespondMsgPack(code int, obj any) {
+	if obj == nil {
+		w.c.Status(code)
+		return
+	}
+	w.encode(code, MsgPackContentType, MsgPackEncoder, obj)
+}
+
+func (w *contextWrapper) RespondOkYAML(obj any) {
+	w.YAML(http.StatusOK, obj)
+}
+
+func (w *contextWrapper) YAML(code int, obj any) {
+	if obj == nil {
+		w.c.Status(code)
+		return
+	}
+	w.encode(code, YAMLContentType, YAMLEncoder, obj)
+}
+
+// encode writes obj with the given encoder.
+func (w *contextWrapper) encode(code int, contentType string, enc Encoder, obj any) {
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, obj); err != nil {
+		w.respondE(http.StatusInternalServerError, err)
+		return
+	}
+	w.c.Data(code, contentType, buf.Bytes())
+}
+
 func (w *contextWrapper) RespondNoContent() {
 	w.c.Status(http.StatusNoContent)
 }
@@ -492,6 +1943,22 @@ func (w *contextWrapper) RespondCreated(obj any) {
 	w.respond(http.StatusCreated, obj)
 }
 
+func (w *contextWrapper) RespondAccepted(obj any) {
+	w.respond(http.StatusAccepted, obj)
+}
+
+func (w *contextWrapper) RespondPartialContent(obj any) {
+	w.respond(http.StatusPartialContent, obj)
+}
+
+func (w *contextWrapper) RespondMovedPermanently(location string) {
+	w.c.Redirect(http.StatusMovedPermanently, location)
+}
+
+func (w *contextWrapper) RespondFound(location string) {
+	w.c.Redirect(http.StatusFound, location)
+}
+
 func (w *contextWrapper) RespondForbidden(obj any) {
 	w.respond(http.StatusForbidden, obj)
 }
@@ -532,6 +1999,38 @@ func (w *contextWrapper) RespondConflictE(err error) {
 	w.respondE(http.StatusConflict, err)
 }
 
+func (w *contextWrapper) RespondGone(obj any) {
+	w.respond(http.StatusGone, obj)
+}
+
+func (w *contextWrapper) RespondGoneE(err error) {
+	w.respondE(http.StatusGone, err)
+}
+
+func (w *contextWrapper) RespondUnprocessableEntity(obj any) {
+	w.respond(http.StatusUnprocessableEntity, obj)
+}
+
+func (w *contextWrapper) RespondUnprocessableEntityE(err error) {
+	w.respondE(http.StatusUnprocessableEntity, err)
+}
+
+func (w *contextWrapper) RespondTooManyRequests(retryAfter time.Duration, obj any) {
+	w.setRetryAfter(retryAfter)
+	w.respond(http.StatusTooManyRequests, obj)
+}
+
+func (w *contextWrapper) RespondTooManyRequestsE(retryAfter time.Duration, err error) {
+	w.setRetryAfter(retryAfter)
+	w.respondE(http.StatusTooManyRequests, err)
+}
+
+func (w *contextWrapper) setRetryAfter(d time.Duration) {
+	if d > 0 {
+		w.c.Header("Retry-After", strconv.Itoa(seconds(d)))
+	}
+}
+
 func (w *contextWrapper) RespondInternalServerError(obj any) {
 	w.respond(http.StatusInternalServerError, obj)
 }
@@ -540,6 +2039,14 @@ func (w *contextWrapper) RespondInternalServerErrorE(err error) {
 	w.respondE(http.StatusInternalServerError, err)
 }
 
+func (w *contextWrapper) RespondServiceUnavailable(obj any) {
+	w.respond(http.StatusServiceUnavailable, obj)
+}
+
+func (w *contextWrapper) RespondServiceUnavailableE(err error) {
+	w.respondE(http.StatusServiceUnavailable, err)
+}
+
 func (w *contextWrapper) RespondMissingRequestBody() {
 	w.RespondBadRequestE(fmt.Errorf("request body is missing"))
 }
@@ -547,13 +2054,45 @@ func (w *contextWrapper) RespondMissingRequestBody() {
 func (w *contextWrapper) respond(status int, obj any) {
 	if obj == nil {
 		w.c.Status(status)
-	} else {
-		w.c.JSON(status, obj)
+		return
+	}
+	e := negotiateEncoder(w.c.GetHeader("Accept"), w.config.encoders)
+	var buf bytes.Buffer
+	err := e.encoder.Encode(&buf, obj)
+	if err != nil && e.mediaType != w.config.encoders[0].mediaType {
+		// objects like maps cannot be encoded in every format, fall back to the first encoder
+		e = w.config.encoders[0]
+		buf.Reset()
+		err = e.encoder.Encode(&buf, obj)
 	}
+	if err != nil {
+		w.respondE(http.StatusInternalServerError, err)
+		return
+	}
+	w.c.Data(status, e.header, buf.Bytes())
 }
 
 func (w *contextWrapper) respondE(status int, err error) {
-	w.c.JSON(status, gin.H{"error": err.Error()})
+	if w.config.problemJSON {
+		w.respondProblem(problemFor(status, err))
+		return
+	}
+	var be *BindingError
+	if errors.As(err, &be) {
+		w.writeJSON(status, gin.H{"error": be.Error(), "fields": be.Fields})
+		return
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		w.writeJSON(status, ve)
+		return
+	}
+	var rse *ResponseStatusError
+	if errors.As(err, &rse) && len(rse.Code) > 0 {
+		w.writeJSON(status, gin.H{"error": err.Error(), "code": rse.Code})
+		return
+	}
+	w.writeJSON(status, gin.H{"error": err.Error()})
 }
 
 func (w *contextWrapper) Abort() {
@@ -562,6 +2101,9 @@ func (w *contextWrapper) Abort() {
 
 func (w *contextWrapper) AbortWithError(code int, error error) {
 	_ = w.c.AbortWithError(code, error)
+	if w.config.problemJSON {
+		w.respondProblem(problemFor(code, error))
+	}
 }
 
 func (w *contextWrapper) Next() {
@@ -569,11 +2111,26 @@ func (w *contextWrapper) Next() {
 }
 
 func (w *contextWrapper) HandleError(err error) {
-	if e, ok := err.(*ResponseStatusError); ok {
-		w.c.JSON(e.StatusCode, gin.H{"error": e.Message})
-	} else {
-		w.RespondInternalServerError(err)
+	w.Error(err)
+	w.errorHandler()(w, err)
+}
+
+func (w *contextWrapper) Error(err error) {
+	if err != nil {
+		_ = w.c.Error(err)
+	}
+}
+
+func (w *contextWrapper) Errors() []error {
+	return requestErrors(w.c)
+}
+
+func requestErrors(c *gin.Context) []error {
+	errs := make([]error, len(c.Errors))
+	for i, e := range c.Errors {
+		errs[i] = e.Err
 	}
+	return errs
 }
 
 func (w *contextWrapper) Deadline() (deadline time.Time, ok bool) {
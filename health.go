@@ -0,0 +1,167 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Health status values.
+const (
+	HealthStatusOK   = "ok"
+	HealthStatusFail = "fail"
+)
+
+// HealthCheck checks a dependency. It returns an error if the dependency is unhealthy.
+type HealthCheck func(ctx context.Context) error
+
+// Health registers health checks. Its endpoints are mounted at /healthz (liveness) and /readyz (readiness).
+// Checks run concurrently on each request. The endpoints respond with 200 if all checks pass and with 503 otherwise.
+type Health struct {
+	mu        sync.RWMutex
+	timeout   time.Duration
+	liveness  []namedCheck
+	readiness []namedCheck
+	shutdown  *shutdownSignal
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+// HealthReport is the response of a health endpoint.
+type HealthReport struct {
+	Status string                       `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks"`
+}
+
+// HealthCheckResult is the result of a single check.
+type HealthCheckResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+func newHealth(shutdown *shutdownSignal) *Health {
+	return &Health{
+		timeout:  5 * time.Second,
+		shutdown: shutdown,
+	}
+}
+
+// Timeout sets the time a check may take before it fails. Defaults to 5 seconds.
+func (h *Health) Timeout(d time.Duration) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeout = d
+	return h
+}
+
+// Liveness adds a check to the liveness endpoint. Failing liveness checks signal that the process must be restarted.
+func (h *Health) Liveness(name string, check HealthCheck) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.liveness = append(h.liveness, namedCheck{name: name, check: check})
+	return h
+}
+
+// Readiness adds a check to the readiness endpoint. Failing readiness checks signal that the process
+// should not receive traffic. Readiness also fails once the engine is shutting down.
+func (h *Health) Readiness(name string, check HealthCheck) *Health {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.readiness = append(h.readiness, namedCheck{name: name, check: check})
+	return h
+}
+
+func (h *Health) mount(r Router) {
+	r.GET("/healthz", h.handler(false))
+	r.GET("/readyz", h.handler(true))
+}
+
+func (h *Health) handler(readiness bool) HandlerFunc {
+	return func(c Context) {
+		h.mu.RLock()
+		checks := h.liveness
+		if readiness {
+			checks = append([]namedCheck{{name: "shutdown", check: h.checkShutdown}}, h.readiness...)
+		}
+		timeout := h.timeout
+		h.mu.RUnlock()
+		report := runHealthChecks(c, checks, timeout)
+		status := http.StatusOK
+		if report.Status != HealthStatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		gc.Header("Cache-Control", "no-store")
+		gc.JSON(status, report)
+	}
+}
+
+func (h *Health) checkShutdown(context.Context) error {
+	select {
+	case <-h.shutdown.Done():
+		return ErrShuttingDown
+	default:
+		return nil
+	}
+}
+
+func runHealthChecks(ctx context.Context, checks []namedCheck, timeout time.Duration) HealthReport {
+	report := HealthReport{
+		Status: HealthStatusOK,
+		Checks: make(map[string]HealthCheckResult, len(checks)),
+	}
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			results[i] = runHealthCheck(ctx, check, timeout)
+		}(i, nc.check)
+	}
+	wg.Wait()
+	for i, nc := range checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status != HealthStatusOK {
+			report.Status = HealthStatusFail
+		}
+	}
+	return report
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck, timeout time.Duration) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result := HealthCheckResult{
+		Status:   HealthStatusOK,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
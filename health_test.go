@@ -0,0 +1,74 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEngine_Health(t *testing.T) {
+	e := New()
+	dbErr := errors.New("connection refused")
+	e.Health().
+		Timeout(20*time.Millisecond).
+		Liveness("ping", func(ctx context.Context) error { return nil }).
+		Readiness("db", func(ctx context.Context) error { return dbErr }).
+		Readiness("slow", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	w := serve(e, http.MethodGet, "/healthz")
+	if w.Code != http.StatusOK {
+		t.Fatal("expected liveness to pass, got", w.Code)
+	}
+	var report HealthReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != HealthStatusOK || report.Checks["ping"].Status != HealthStatusOK || len(report.Checks["ping"].Duration) == 0 {
+		t.Fatal("unexpected liveness report", w.Body.String())
+	}
+
+	w = serve(e, http.MethodGet, "/readyz")
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected readiness to fail, got", w.Code)
+	}
+	report = HealthReport{}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != HealthStatusFail || report.Checks["db"].Error != dbErr.Error() {
+		t.Fatal("expected db check to fail, got", w.Body.String())
+	}
+	if report.Checks["slow"].Error != context.DeadlineExceeded.Error() {
+		t.Fatal("expected slow check to time out, got", report.Checks["slow"])
+	}
+	if report.Checks["shutdown"].Status != HealthStatusOK {
+		t.Fatal("expected shutdown check to pass, got", report.Checks["shutdown"])
+	}
+}
+
+func TestEngine_Health_ShuttingDown(t *testing.T) {
+	e := New()
+	e.Health()
+	if w := serve(e, http.MethodGet, "/readyz"); w.Code != http.StatusOK {
+		t.Fatal("expected readiness to pass, got", w.Code)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if w := serve(e, http.MethodGet, "/readyz"); w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected readiness to fail while shutting down, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/healthz"); w.Code != http.StatusOK {
+		t.Fatal("expected liveness to pass while shutting down, got", w.Code)
+	}
+}
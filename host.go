@@ -0,0 +1,78 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gin-gonic/gin"
+	"net"
+	"strings"
+)
+
+// virtualHost serves the routes of a host pattern.
+type virtualHost struct {
+	pattern string
+	router  *gin.Engine
+}
+
+// matches reports whether a host matches the pattern. A leading "*." matches one or more subdomains.
+func (h *virtualHost) matches(host string) bool {
+	if strings.HasPrefix(h.pattern, "*.") {
+		suffix := h.pattern[1:]
+		return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+	}
+	return host == h.pattern
+}
+
+func (r *ginEngine) Host(pattern string, handlers ...HandlerFunc) RouterGroup {
+	pattern = strings.ToLower(pattern)
+	var host *virtualHost
+	for _, h := range r.config.hosts {
+		if h.pattern == pattern {
+			host = h
+		}
+	}
+	if host == nil {
+		host = &virtualHost{pattern: pattern, router: newInnerRouter()}
+		r.config.hosts = append(r.config.hosts, host)
+	}
+	middleware := MapMany(append(append([]HandlerFunc(nil), r.middleware...), handlers...), r.config.wrapHandler)
+	g := newGinRouterGroup(host.router, host.router.Group("/", middleware...), r.config, nil)
+	r.groups = append(r.groups, g)
+	return g
+}
+
+// routeHosts passes requests for virtual hosts on to their routes. Exact host patterns take precedence over
+// wildcard patterns, otherwise patterns are matched in order of registration.
+func routeHosts(config *engineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(config.hosts) == 0 {
+			return
+		}
+		host := requestHost(c.Request.Host)
+		var match *virtualHost
+		for _, h := range config.hosts {
+			if h.pattern == host {
+				match = h
+				break
+			}
+			if match == nil && h.matches(host) {
+				match = h
+			}
+		}
+		if match == nil {
+			return
+		}
+		serveInner(match.router, c)
+		c.Abort()
+	}
+}
+
+// requestHost returns the lower case host name without port.
+func requestHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveHost(e Engine, method string, host string, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = host
+	return serveRequest(e, req)
+}
+
+func TestEngine_Host(t *testing.T) {
+	e := New()
+	e.Use(func(c Context) {
+		c.SetHeader("X-Engine", "true")
+	})
+	respond := func(body string) HandlerFunc {
+		return func(c Context) {
+			c.String(http.StatusOK, body)
+		}
+	}
+	e.GET("/users", respond("main"))
+	e.Host("api.example.com", func(c Context) {
+		c.SetHeader("X-Host", "api")
+	}).GET("/users", respond("api"))
+	e.Host("*.example.com").GET("/users", respond("tenant"))
+	e.Host("admin.example.com").Group("/admin").GET("/users", respond("admin"))
+	e.ExpandMethods()
+
+	w := serveHost(e, http.MethodGet, "API.example.com:8080", "/users")
+	if w.Body.String() != "api" || w.Header().Get("X-Host") != "api" || w.Header().Get("X-Engine") != "true" {
+		t.Fatal("expected api host with middleware, got", w.Body.String(), w.Header())
+	}
+	if w := serveHost(e, http.MethodGet, "acme.example.com", "/users"); w.Body.String() != "tenant" {
+		t.Fatal("expected wildcard host, got", w.Body.String())
+	}
+	if w := serveHost(e, http.MethodGet, "admin.example.com", "/admin/users"); w.Body.String() != "admin" {
+		t.Fatal("expected exact host to take precedence over wildcard, got", w.Body.String())
+	}
+	if w := serveHost(e, http.MethodGet, "example.com", "/users"); w.Body.String() != "main" {
+		t.Fatal("expected engine routes for other hosts, got", w.Body.String())
+	}
+	if w := serveHost(e, http.MethodDelete, "api.example.com", "/users"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected expanded methods on host, got", w.Code)
+	}
+	if w := serveHost(e, http.MethodGet, "api.example.com", "/orders"); w.Code != http.StatusNotFound {
+		t.Fatal("expected 404 on host, got", w.Code)
+	}
+}
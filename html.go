@@ -0,0 +1,28 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"html/template"
+	"io"
+)
+
+// Renderer renders named HTML templates.
+type Renderer interface {
+	Render(w io.Writer, name string, data any) error
+}
+
+type templateRenderer struct {
+	t *template.Template
+}
+
+// NewTemplateRenderer creates a Renderer backed by the given templates.
+func NewTemplateRenderer(t *template.Template) Renderer {
+	return &templateRenderer{t: t}
+}
+
+func (r *templateRenderer) Render(w io.Writer, name string, data any) error {
+	return r.t.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+)
+
+func TestContext_HTML(t *testing.T) {
+	templates := fstest.MapFS{
+		"templates/layout.html": {Data: []byte(`{{define "layout.html"}}<h1>{{.Title}}</h1>{{template "body" .}}{{end}}`)},
+		"templates/page.html":   {Data: []byte(`{{define "body"}}<p>{{.Text}}</p>{{end}}`)},
+	}
+	e := New()
+	if err := e.LoadHTMLFS(templates, "templates/*.html"); err != nil {
+		t.Fatal(err)
+	}
+	e.GET("/", func(c Context) {
+		c.HTML(http.StatusOK, "layout.html", map[string]string{"Title": "jug", "Text": "<b>escaped</b>"})
+	})
+	e.GET("/missing", func(c Context) {
+		c.HTML(http.StatusOK, "missing.html", nil)
+	})
+
+	w := serve(e, http.MethodGet, "/")
+	if w.Code != http.StatusOK || w.Body.String() != `<h1>jug</h1><p>&lt;b&gt;escaped&lt;/b&gt;</p>` {
+		t.Fatal("expected the rendered template, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Error("expected an HTML content type, got", ct)
+	}
+	if w := serve(e, http.MethodGet, "/missing"); w.Code != http.StatusInternalServerError {
+		t.Error("expected 500 for a missing template, got", w.Code)
+	}
+}
+
+func TestContext_HTML_NoRenderer(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		c.HTML(http.StatusOK, "page.html", nil)
+	})
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusInternalServerError {
+		t.Error("expected 500 without templates, got", w.Code)
+	}
+}
+
+func TestEngine_LoadHTMLFS_NoMatch(t *testing.T) {
+	if err := New().LoadHTMLFS(fstest.MapFS{}, "*.html"); err == nil {
+		t.Error("expected an error for patterns without templates")
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HTTP2Config configures HTTP/2 connections served by RunH2C.
+type HTTP2Config struct {
+	// MaxConcurrentStreams limits the concurrent streams of a connection. Zero uses the default of 250.
+	MaxConcurrentStreams uint32
+	// IdleTimeout closes connections without streams after the given time. Zero keeps them open.
+	IdleTimeout time.Duration
+}
+
+func (r *ginEngine) SetHTTP2Config(cfg HTTP2Config) {
+	r.http2 = cfg
+}
+
+func (r *ginEngine) RunH2C(addr ...string) error {
+	if err := r.lifecycle.runStart(context.Background()); err != nil {
+		return err
+	}
+	listeners := make([]net.Listener, 0, len(addr))
+	for _, a := range resolveAddresses(addr) {
+		l, err := net.Listen("tcp", a)
+		if err != nil {
+			closeListeners(listeners)
+			return r.stopOnError(err)
+		}
+		listeners = append(listeners, l)
+	}
+	return r.serve(listeners, r.h2cHandler())
+}
+
+// h2cHandler serves HTTP/2 without TLS in addition to HTTP/1.
+func (r *ginEngine) h2cHandler() http.Handler {
+	return h2c.NewHandler(r.engine, &http2.Server{
+		MaxConcurrentStreams: r.http2.MaxConcurrentStreams,
+		IdleTimeout:          r.http2.IdleTimeout,
+	})
+}
+
+// HTTP3Server serves HTTP/3 over QUIC, e.g. *http3.Server of github.com/quic-go/quic-go configured
+// with the engine as handler.
+type HTTP3Server interface {
+	ListenAndServe() error
+	Close() error
+}
+
+// http3Server stops an HTTP/3 server on Shutdown.
+type http3Server struct {
+	server HTTP3Server
+}
+
+func (s http3Server) Shutdown(context.Context) error {
+	return s.server.Close()
+}
+
+func (r *ginEngine) RunHTTP3(server HTTP3Server) error {
+	r.addServer(http3Server{server: server})
+	if err := r.lifecycle.runStart(context.Background()); err != nil {
+		return err
+	}
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, net.ErrClosed) {
+		return r.stopOnError(err)
+	}
+	return nil
+}
+
+// AdvertiseHTTP3 returns a middleware announcing an HTTP/3 endpoint on the given UDP port with the Alt-Svc header,
+// so clients connected over TCP can switch to HTTP/3. Clients cache the announcement for maxAge.
+func AdvertiseHTTP3(port int, maxAge time.Duration) HandlerFunc {
+	value := "h3=\":" + strconv.Itoa(port) + "\"; ma=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+	return func(c Context) {
+		if gc, ok := ginContextOf(c); ok && gc.Request.ProtoMajor < 3 {
+			gc.Header("Alt-Svc", value)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"crypto/tls"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestEngine_RunH2C(t *testing.T) {
+	e := New()
+	e.SetHTTP2Config(HTTP2Config{MaxConcurrentStreams: 10, IdleTimeout: time.Minute})
+	e.GET("/proto", func(c Context) {
+		gc, _ := ginContextOf(c)
+		c.String(http.StatusOK, gc.Request.Proto)
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunH2C(addr)
+	}()
+
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network string, addr string, _ *tls.Config) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	for i := 0; i < 100; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if body := get(t, client, "http://"+addr+"/proto"); body != "HTTP/2.0" {
+		t.Fatal("expected HTTP/2 request, got", body)
+	}
+	if body := get(t, http.DefaultClient, "http://"+addr+"/proto"); body != "HTTP/1.1" {
+		t.Fatal("expected HTTP/1 request, got", body)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected RunH2C to return nil, got", err)
+	}
+}
+
+type fakeHTTP3Server struct {
+	closed chan struct{}
+}
+
+func (s *fakeHTTP3Server) ListenAndServe() error {
+	<-s.closed
+	return http.ErrServerClosed
+}
+
+func (s *fakeHTTP3Server) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func TestEngine_RunHTTP3(t *testing.T) {
+	started := make(chan struct{})
+	e := New()
+	e.OnStart(func(ctx context.Context) error {
+		close(started)
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunHTTP3(&fakeHTTP3Server{closed: make(chan struct{})})
+	}()
+	<-started
+	time.Sleep(10 * time.Millisecond)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected RunHTTP3 to return nil, got", err)
+	}
+}
+
+func TestAdvertiseHTTP3(t *testing.T) {
+	e := New()
+	e.GET("/", AdvertiseHTTP3(443, 24*time.Hour), func(c Context) {
+		c.RespondNoContent()
+	})
+	if w := serve(e, http.MethodGet, "/"); w.Header().Get("Alt-Svc") != `h3=":443"; ma=86400` {
+		t.Fatal("expected Alt-Svc header, got", w.Header().Get("Alt-Svc"))
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bundle provides translated messages.
+type Bundle interface {
+	// Locales returns the supported locales. The first locale is the default.
+	Locales() []string
+	// Translate returns the message for key in the given locale, formatted with args.
+	// It returns false if the bundle has no message for key.
+	Translate(locale string, key string, args ...any) (string, bool)
+}
+
+var (
+	localeKey = Key[string]("jug.locale")
+	bundleKey = Key[Bundle]("jug.bundle")
+)
+
+// I18n returns a middleware selecting the locale of a request from the Accept-Language header.
+// Requests without a supported language get the default locale of the bundle.
+// The locale is sent in the Content-Language header, see Context.Locale and Context.T.
+// It panics if the bundle has no locales.
+func I18n(bundle Bundle) HandlerFunc {
+	locales := bundle.Locales()
+	if len(locales) == 0 {
+		panic("jug: i18n bundle has no locales")
+	}
+	return func(c Context) {
+		locale := negotiateLocale(c.GetHeader("Accept-Language"), locales)
+		localeKey.Set(c, locale)
+		bundleKey.Set(c, bundle)
+		if gc, ok := ginContextOf(c); ok {
+			gc.Writer.Header().Add("Vary", "Accept-Language")
+		}
+		c.SetHeader("Content-Language", locale)
+	}
+}
+
+// negotiateLocale selects the locale best matching the given Accept-Language header.
+// Languages match locales with the same base language, e.g. de-AT matches de.
+// If no locale matches, the first locale is returned.
+func negotiateLocale(acceptLanguage string, locales []string) string {
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		if tag == "*" {
+			break
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(locale, tag) {
+				return locale
+			}
+		}
+		for _, locale := range locales {
+			if strings.EqualFold(baseLanguage(locale), baseLanguage(tag)) {
+				return locale
+			}
+		}
+	}
+	return locales[0]
+}
+
+// parseAcceptLanguage parses an Accept-Language header into language tags ordered by preference.
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	tags := make([]weighted, 0)
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		q := 1.0
+		if v := strings.TrimSpace(params); strings.HasPrefix(v, "q=") {
+			if f, err := strconv.ParseFloat(v[2:], 64); err == nil {
+				q = f
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+	return result
+}
+
+func baseLanguage(tag string) string {
+	base, _, _ := strings.Cut(strings.ReplaceAll(tag, "_", "-"), "-")
+	return base
+}
+
+// MessageBundle is a Bundle holding messages in memory. Messages are formatted with fmt.Sprintf.
+// Missing messages fall back to the base language, e.g. de-AT to de, and then to the default locale.
+// Messages must be added before the bundle is used.
+type MessageBundle struct {
+	locales  []string
+	messages map[string]map[string]string
+}
+
+// NewMessageBundle creates an empty bundle with the given default locale.
+func NewMessageBundle(defaultLocale string) *MessageBundle {
+	return &MessageBundle{
+		locales:  []string{defaultLocale},
+		messages: map[string]map[string]string{defaultLocale: {}},
+	}
+}
+
+// Add adds messages for a locale. The locale is added to the supported locales.
+func (b *MessageBundle) Add(locale string, messages map[string]string) *MessageBundle {
+	m, ok := b.messages[locale]
+	if !ok {
+		m = make(map[string]string, len(messages))
+		b.messages[locale] = m
+		b.locales = append(b.locales, locale)
+	}
+	for key, message := range messages {
+		m[key] = message
+	}
+	return b
+}
+
+// AddYAML adds messages for a locale from a YAML document. Keys of nested maps are joined with a dot.
+func (b *MessageBundle) AddYAML(locale string, data []byte) error {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("unable to parse messages for %s: %w", locale, err)
+	}
+	messages := make(map[string]string)
+	flattenMessages(messages, "", doc)
+	b.Add(locale, messages)
+	return nil
+}
+
+func flattenMessages(messages map[string]string, prefix string, doc map[string]any) {
+	for key, value := range doc {
+		if m, ok := value.(map[string]any); ok {
+			flattenMessages(messages, prefix+key+".", m)
+			continue
+		}
+		messages[prefix+key] = fmt.Sprint(value)
+	}
+}
+
+// Locales returns the locales in the order they were added, starting with the default locale.
+func (b *MessageBundle) Locales() []string {
+	return b.locales
+}
+
+func (b *MessageBundle) Translate(locale string, key string, args ...any) (string, bool) {
+	for _, l := range []string{locale, baseLanguage(locale), b.locales[0]} {
+		if message, ok := b.messages[l][key]; ok {
+			if len(args) == 0 {
+				return message, true
+			}
+			return fmt.Sprintf(message, args...), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testBundle(t *testing.T) *MessageBundle {
+	b := NewMessageBundle("en").
+		Add("en", map[string]string{"greeting": "Hello %s", "farewell": "Goodbye"})
+	err := b.AddYAML("de", []byte("greeting: Hallo %s\nvalidation:\n  required: '%s ist erforderlich'\n  max_length: '%s darf höchstens %s Zeichen haben'\nname.invalid: Ungültiger Name\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func serveLanguage(e Engine, method string, path string, body string, acceptLanguage string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if len(acceptLanguage) > 0 {
+		r.Header.Set("Accept-Language", acceptLanguage)
+	}
+	return serveRequest(e, r)
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	locales := []string{"en", "de", "fr-CA"}
+	tests := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{"", "en"},
+		{"de", "de"},
+		{"de-AT,en;q=0.5", "de"},
+		{"it,fr;q=0.8", "fr-CA"},
+		{"en;q=0.2,de;q=0.9", "de"},
+		{"it", "en"},
+		{"de;q=0", "en"},
+	}
+	for _, tt := range tests {
+		if actual := negotiateLocale(tt.acceptLanguage, locales); actual != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.acceptLanguage, tt.expected, actual)
+		}
+	}
+}
+
+func TestI18n(t *testing.T) {
+	e := New()
+	e.Use(I18n(testBundle(t)))
+	e.GET("/greet", func(c Context) {
+		c.String(http.StatusOK, "%s %s|%s|%s", c.Locale(), c.T("greeting", "Jane"), c.T("farewell"), c.T("missing"))
+	})
+
+	w := serveLanguage(e, http.MethodGet, "/greet", "", "de-DE,de;q=0.9")
+	if w.Body.String() != "de Hallo Jane|Goodbye|missing" {
+		t.Error("expected german messages with fallback, got", w.Body.String())
+	}
+	if w.Header().Get("Content-Language") != "de" || w.Header().Get("Vary") != "Accept-Language" {
+		t.Error("expected Content-Language and Vary headers, got", w.Header())
+	}
+	w = serveLanguage(e, http.MethodGet, "/greet", "", "")
+	if w.Body.String() != "en Hello Jane|Goodbye|missing" {
+		t.Error("expected default locale, got", w.Body.String())
+	}
+}
+
+func TestI18nValidation(t *testing.T) {
+	type person struct {
+		Name string `json:"name" validate:"required,max=3"`
+	}
+	e := New()
+	e.Use(I18n(testBundle(t)))
+	e.POST("/people", func(c Context) {
+		var p person
+		if c.MustBindJSON(&p) {
+			c.RespondNoContent()
+		}
+	})
+	e.POST("/names", func(c Context) {
+		err := c.NewValidator().Structured().Field("name").Require(false, "name.invalid").Validate()
+		c.RespondBadRequestE(err)
+	})
+
+	w := serveLanguage(e, http.MethodPost, "/people", `{}`, "de")
+	if !strings.Contains(w.Body.String(), "name ist erforderlich") {
+		t.Error("expected localized required message, got", w.Body.String())
+	}
+	w = serveLanguage(e, http.MethodPost, "/people", `{"name":"Johanna"}`, "de")
+	if !strings.Contains(w.Body.String(), "name darf höchstens 3 Zeichen haben") {
+		t.Error("expected localized max length message, got", w.Body.String())
+	}
+	w = serveLanguage(e, http.MethodPost, "/people", `{}`, "en")
+	if !strings.Contains(w.Body.String(), "name is required") {
+		t.Error("expected default message, got", w.Body.String())
+	}
+	w = serveLanguage(e, http.MethodPost, "/names", ``, "de")
+	if !strings.Contains(w.Body.String(), "Ungültiger Name") {
+		t.Error("expected translated rule message, got", w.Body.String())
+	}
+}
+
+func TestI18nQueryValidation(t *testing.T) {
+	type search struct {
+		Term string `query:"term" json:"term" validate:"max=3"`
+	}
+	e := New()
+	e.Use(I18n(testBundle(t)))
+	e.GET("/search", func(c Context) {
+		var s search
+		if c.MustBindQuery(&s) {
+			c.RespondNoContent()
+		}
+	})
+
+	w := serveLanguage(e, http.MethodGet, "/search?term=long", "", "de")
+	if !strings.Contains(w.Body.String(), "term darf höchstens 3 Zeichen haben") {
+		t.Error("expected localized query validation message, got", w.Body.String())
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is a request stored by the Idempotency middleware.
+type IdempotencyRecord struct {
+	// Fingerprint is a hash of the method, path and body of the request.
+	Fingerprint string
+	// Response is the stored response, nil while the request is in progress.
+	Response *CachedResponse
+}
+
+// IdempotencyStore stores idempotency records. Implement it to share the records between instances.
+type IdempotencyStore interface {
+	// Get gets a record. Expired records are not returned.
+	Get(key string) (*IdempotencyRecord, bool)
+	// Reserve atomically stores a record for the given time, unless a record with the key exists.
+	// It reports whether the record was stored.
+	Reserve(key string, record *IdempotencyRecord, ttl time.Duration) bool
+	// Set stores a record for the given time.
+	Set(key string, record *IdempotencyRecord, ttl time.Duration)
+	// Delete deletes a record.
+	Delete(key string)
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Store stores the records. Defaults to a MemoryIdempotencyStore.
+	Store IdempotencyStore
+	// TTL is the time a response is replayed. Defaults to 24 hours.
+	TTL time.Duration
+	// Header is the request header holding the key. Defaults to Idempotency-Key.
+	Header string
+	// Required rejects unsafe requests without a key with 400.
+	Required bool
+	// Scope derives a scope from the request, e.g. the authenticated user, so that keys of different clients don't collide.
+	Scope KeyFunc
+	// MaxBodySize limits the request bodies read to fingerprint requests. Larger bodies are answered with 413.
+	// Defaults to 1 MiB.
+	MaxBodySize int64
+}
+
+const defaultIdempotencyMaxBodySize = 1 << 20
+
+// Idempotency returns a middleware that makes POST, PUT, PATCH and DELETE requests carrying an Idempotency-Key
+// header safe to retry. The first response for a key is stored, requests repeating the key replay it with the
+// Idempotent-Replayed header instead of calling the handlers again.
+// Requests repeating a key while the first request is in progress are answered with 409, requests reusing a key
+// with a different method, path or body are answered with 422.
+// Responses with status 5xx are not stored, so the request can be retried.
+func Idempotency(cfg IdempotencyConfig) HandlerFunc {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if len(cfg.Header) == 0 {
+		cfg.Header = "Idempotency-Key"
+	}
+	if cfg.MaxBodySize <= 0 {
+		cfg.MaxBodySize = defaultIdempotencyMaxBodySize
+	}
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok || !unsafeMethod(gc.Request.Method) {
+			return
+		}
+		key := gc.GetHeader(cfg.Header)
+		if len(key) == 0 {
+			if cfg.Required {
+				c.HandleError(NewBadRequestError("missing " + cfg.Header + " header"))
+				c.Abort()
+			}
+			return
+		}
+		if len(key) > 255 {
+			c.HandleError(NewBadRequestError(cfg.Header + " header is too long"))
+			c.Abort()
+			return
+		}
+		if cfg.Scope != nil {
+			key = cfg.Scope(c) + "|" + key
+		}
+		fingerprint, err := requestFingerprint(gc.Request, cfg.MaxBodySize)
+		if err != nil {
+			c.HandleError(err)
+			c.Abort()
+			return
+		}
+
+		if !cfg.Store.Reserve(key, &IdempotencyRecord{Fingerprint: fingerprint}, cfg.TTL) {
+			record, ok := cfg.Store.Get(key)
+			switch {
+			case !ok || record.Response == nil:
+				c.HandleError(NewConflictError("a request with this " + cfg.Header + " is in progress").WithRetryAfter(time.Second))
+			case record.Fingerprint != fingerprint:
+				c.HandleError(NewUnprocessableEntityError(cfg.Header + " was used for a different request"))
+			default:
+				replayResponse(gc, record.Response)
+			}
+			c.Abort()
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				cfg.Store.Delete(key)
+			}
+		}()
+		w := &captureWriter{ResponseWriter: gc.Writer}
+		gc.Writer = w
+		c.Next()
+		gc.Writer = w.ResponseWriter
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+		cfg.Store.Set(key, &IdempotencyRecord{
+			Fingerprint: fingerprint,
+			Response: &CachedResponse{
+				Status: w.Status(),
+				Header: w.Header().Clone(),
+				Body:   w.body.Bytes(),
+				Stored: time.Now(),
+			},
+		}, cfg.TTL)
+		completed = true
+	}
+}
+
+func unsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// requestFingerprint hashes method, path and body of the request. The body is restored for the handlers.
+// Bodies larger than maxBodySize, or exceeding a limit set before, result in a 413 error.
+func requestFingerprint(r *http.Request, maxBodySize int64) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	if r.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+		if err == nil && int64(len(body)) > maxBodySize {
+			err = &http.MaxBytesError{Limit: maxBodySize}
+		}
+		if tooLarge, ok := bodyTooLarge(err); ok {
+			return "", NewStatusError(http.StatusRequestEntityTooLarge, tooLarge)
+		}
+		if err != nil {
+			return "", err
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replayResponse(c *gin.Context, res *CachedResponse) {
+	header := c.Writer.Header()
+	for k, v := range res.Header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set("Idempotent-Replayed", "true")
+	c.Status(res.Status)
+	_, _ = c.Writer.Write(res.Body)
+}
+
+// MemoryIdempotencyStore is an in-memory IdempotencyStore. Expired records are removed when records are stored.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryIdempotencyEntry
+	swept   time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	record  *IdempotencyRecord
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory IdempotencyStore.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Get(key string) (*IdempotencyRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.record, true
+}
+
+func (s *MemoryIdempotencyStore) Reserve(key string, record *IdempotencyRecord, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if e, ok := s.entries[key]; ok && now.Before(e.expires) {
+		return false
+	}
+	s.set(key, record, now.Add(ttl))
+	return true
+}
+
+func (s *MemoryIdempotencyStore) Set(key string, record *IdempotencyRecord, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, record, time.Now().Add(ttl))
+}
+
+func (s *MemoryIdempotencyStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+func (s *MemoryIdempotencyStore) set(key string, record *IdempotencyRecord, expires time.Time) {
+	now := time.Now()
+	if now.Sub(s.swept) > time.Minute {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.swept = now
+	}
+	s.entries[key] = memoryIdempotencyEntry{record: record, expires: expires}
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveIdempotent(e Engine, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/payments", strings.NewReader(body))
+	if len(key) > 0 {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	return serveRequest(e, req)
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+	e := New()
+	e.POST("/payments", Idempotency(IdempotencyConfig{}), func(c Context) {
+		calls++
+		c.SetHeader("Location", "/payments/1")
+		c.String(http.StatusCreated, "payment #%d", calls)
+	})
+
+	w := serveIdempotent(e, "k1", `{"amount":10}`)
+	if w.Code != http.StatusCreated || w.Body.String() != "payment #1" || len(w.Header().Get("Idempotent-Replayed")) > 0 {
+		t.Fatal("expected first request to run, got", w.Code, w.Body.String())
+	}
+	w = serveIdempotent(e, "k1", `{"amount":10}`)
+	if w.Code != http.StatusCreated || w.Body.String() != "payment #1" || w.Header().Get("Idempotent-Replayed") != "true" || w.Header().Get("Location") != "/payments/1" {
+		t.Fatal("expected response to be replayed, got", w.Code, w.Body.String(), w.Header())
+	}
+	if w := serveIdempotent(e, "k1", `{"amount":20}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatal("expected key reuse with different body to be rejected, got", w.Code)
+	}
+	if w := serveIdempotent(e, "k2", `{"amount":10}`); w.Body.String() != "payment #2" {
+		t.Fatal("expected new key to run, got", w.Body.String())
+	}
+	if w := serveIdempotent(e, "", `{"amount":10}`); w.Body.String() != "payment #3" {
+		t.Fatal("expected request without key to run, got", w.Body.String())
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	var nested int
+	e := New()
+	e.POST("/payments", Idempotency(IdempotencyConfig{Store: store}), func(c Context) {
+		nested = serveIdempotent(e, "k1", `{}`).Code
+		c.RespondNoContent()
+	})
+
+	if w := serveIdempotent(e, "k1", `{}`); w.Code != http.StatusNoContent {
+		t.Fatal("expected first request to run, got", w.Code)
+	}
+	if nested != http.StatusConflict {
+		t.Fatal("expected concurrent request to be rejected with 409, got", nested)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	calls := 0
+	e := New()
+	e.POST("/payments", Idempotency(IdempotencyConfig{Required: true}), func(c Context) {
+		calls++
+		if calls == 1 {
+			c.RespondInternalServerError(nil)
+			return
+		}
+		c.RespondNoContent()
+	})
+
+	if w := serveIdempotent(e, "k1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatal("expected failing request, got", w.Code)
+	}
+	if w := serveIdempotent(e, "k1", `{}`); w.Code != http.StatusNoContent || calls != 2 {
+		t.Fatal("expected retry to run, got", w.Code, calls)
+	}
+	if w := serveIdempotent(e, "", `{}`); w.Code != http.StatusBadRequest {
+		t.Fatal("expected missing key to be rejected, got", w.Code)
+	}
+}
+
+func TestIdempotency_MaxBodySize(t *testing.T) {
+	e := New()
+	e.POST("/payments", Idempotency(IdempotencyConfig{MaxBodySize: 8}), func(c Context) {
+		c.RespondNoContent()
+	})
+
+	if w := serveIdempotent(e, "k1", `{"a":1}`); w.Code != http.StatusNoContent {
+		t.Error("expected body within the limit to pass, got", w.Code)
+	}
+	w := serveIdempotent(e, "k2", `{"amount":10}`)
+	if w.Code != http.StatusRequestEntityTooLarge || w.Body.String() != `{"error":"request body exceeds 8 bytes"}` {
+		t.Error("expected 413 for a large body, got", w.Code, w.Body.String())
+	}
+
+	e = New()
+	e.SetMaxBodySize(4)
+	e.POST("/payments", Idempotency(IdempotencyConfig{}), func(c Context) {
+		c.RespondNoContent()
+	})
+	if w := serveIdempotent(e, "k1", `{"amount":10}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected 413 for a body exceeding the engine limit, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IPFilterConfig configures the IPFilter middleware. Lists contain CIDRs or single IP addresses.
+type IPFilterConfig struct {
+	// Allow lists the networks allowed to access the routes. If empty, all networks not denied are allowed.
+	Allow []string
+	// Deny lists the networks denied access. Deny takes precedence over Allow.
+	Deny []string
+	// TrustedProxies lists the proxies whose X-Forwarded-For header is used to resolve the client IP.
+	// If empty, the client IP is resolved by Context.ClientIP, which is the peer address unless the engine trusts proxies.
+	TrustedProxies []string
+	// OnReject writes the response for rejected requests. Defaults to 403.
+	OnReject HandlerFunc
+}
+
+// IPFilter returns a middleware that allows or denies requests by client IP address.
+// It panics if a list contains an invalid CIDR or IP address.
+func IPFilter(config IPFilterConfig) HandlerFunc {
+	allow := mustParseNetworks(config.Allow)
+	deny := mustParseNetworks(config.Deny)
+	trusted := mustParseNetworks(config.TrustedProxies)
+	reject := config.OnReject
+	if reject == nil {
+		reject = func(c Context) {
+			c.HandleError(NewForbiddenError("forbidden"))
+		}
+	}
+	return func(c Context) {
+		var ip net.IP
+		if len(trusted) > 0 {
+			if gc, ok := ginContextOf(c); ok {
+				ip = forwardedClientIP(gc.Request, trusted)
+			}
+		} else {
+			ip = net.ParseIP(c.ClientIP())
+		}
+		if ip == nil || containsIP(deny, ip) || (len(allow) > 0 && !containsIP(allow, ip)) {
+			reject(c)
+			c.Abort()
+		}
+	}
+}
+
+func mustParseNetworks(list []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		n, err := parseNetwork(s)
+		if err != nil {
+			panic(fmt.Sprintf("jug: %v", err))
+		}
+		networks = append(networks, n)
+	}
+	return networks
+}
+
+// parseNetwork parses a CIDR or a single IP address.
+func parseNetwork(s string) (*net.IPNet, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %s", s)
+		}
+		return n, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %s", s)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, n := range networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP address of the direct peer.
+func remoteIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// forwardedClientIP resolves the client IP from X-Forwarded-For if the peer is a trusted proxy.
+func forwardedClientIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	ip := remoteIP(r)
+	if ip == nil || !containsIP(trusted, ip) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := range hops {
+		hops[i] = strings.TrimSpace(hops[i])
+	}
+	return rightmostUntrusted(hops, trusted, ip)
+}
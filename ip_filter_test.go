@@ -0,0 +1,84 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func serveFrom(e Engine, remoteAddr string, forwardedFor string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	r.RemoteAddr = remoteAddr
+	if len(forwardedFor) > 0 {
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	return serveRequest(e, r)
+}
+
+func TestIPFilter(t *testing.T) {
+	e := New()
+	e.GET("/admin", IPFilter(IPFilterConfig{
+		Allow:          []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:           []string{"10.0.0.13"},
+		TrustedProxies: []string{"192.168.0.1"},
+	}), func(c Context) {
+		c.RespondNoContent()
+	})
+
+	tests := []struct {
+		remoteAddr   string
+		forwardedFor string
+		expected     int
+	}{
+		{"10.1.2.3:1234", "", http.StatusNoContent},
+		{"[2001:db8::1]:1234", "", http.StatusNoContent},
+		{"10.0.0.13:1234", "", http.StatusForbidden},
+		{"172.16.0.1:1234", "", http.StatusForbidden},
+		{"172.16.0.1:1234", "10.1.2.3", http.StatusForbidden},
+		{"192.168.0.1:1234", "10.1.2.3", http.StatusNoContent},
+		{"192.168.0.1:1234", "10.1.2.3, 172.16.0.1", http.StatusForbidden},
+		{"192.168.0.1:1234", "172.16.0.1, 10.1.2.3", http.StatusNoContent},
+		{"192.168.0.1:1234", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if w := serveFrom(e, tt.remoteAddr, tt.forwardedFor); w.Code != tt.expected {
+			t.Errorf("expected %d for %s forwarded for %q, got %d", tt.expected, tt.remoteAddr, tt.forwardedFor, w.Code)
+		}
+	}
+}
+
+func TestIPFilter_IgnoresSpoofedHeaders(t *testing.T) {
+	e := New()
+	e.GET("/admin", IPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/8"}}), func(c Context) {
+		c.RespondNoContent()
+	})
+	if w := serveFrom(e, "172.16.0.1:1234", "10.1.2.3"); w.Code != http.StatusForbidden {
+		t.Error("expected a spoofed X-Forwarded-For to be ignored, got", w.Code)
+	}
+}
+
+func TestIPFilter_OnReject(t *testing.T) {
+	e := New()
+	e.GET("/admin", IPFilter(IPFilterConfig{
+		Deny: []string{"0.0.0.0/0"},
+		OnReject: func(c Context) {
+			c.RespondNotFound(nil)
+		},
+	}), func(c Context) {})
+	if w := serveFrom(e, "10.1.2.3:1234", ""); w.Code != http.StatusNotFound {
+		t.Fatal("expected custom rejection, got", w.Code)
+	}
+}
+
+func TestIPFilter_InvalidNetwork(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected invalid CIDR to panic")
+		}
+	}()
+	IPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/33"}})
+}
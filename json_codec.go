@@ -0,0 +1,110 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin/binding"
+	"io"
+	"net/http"
+)
+
+// JSONCodec marshals and unmarshals JSON. Implement it to use an alternative JSON library, see Engine.SetJSONCodec.
+type JSONCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+	NewEncoder(w io.Writer) JSONStreamEncoder
+}
+
+// JSONStreamEncoder writes JSON values to a stream. Each value is followed by a newline.
+type JSONStreamEncoder interface {
+	Encode(v any) error
+}
+
+// StdJSONCodec is the JSONCodec based on encoding/json. It is used by default.
+var StdJSONCodec JSONCodec = stdJSONCodec{}
+
+type stdJSONCodec struct{}
+
+func (stdJSONCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (stdJSONCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (stdJSONCodec) NewEncoder(w io.Writer) JSONStreamEncoder {
+	return json.NewEncoder(w)
+}
+
+// jsonCodecEncoder encodes response bodies with a JSONCodec.
+func jsonCodecEncoder(codec JSONCodec) Encoder {
+	return EncoderFunc(func(w io.Writer, obj any) error {
+		data, err := codec.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
+
+// jsonBinding binds request bodies with a JSONCodec. An empty body results in io.EOF.
+// Strict bindings reject keys not matching a field.
+type jsonBinding struct {
+	codec  JSONCodec
+	strict bool
+}
+
+func (jsonBinding) Name() string {
+	return "json"
+}
+
+func (b jsonBinding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("invalid request")
+	}
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return io.EOF
+	}
+	if u, ok := obj.(codecUnmarshaler); ok {
+		err = u.unmarshalJSONWith(b.codec, data)
+	} else {
+		err = b.codec.Unmarshal(data, obj)
+	}
+	if err != nil {
+		return err
+	}
+	if b.strict {
+		if err := rejectUnknownJSONFields(b.codec, data, obj); err != nil {
+			return err
+		}
+	}
+	if err := applyJSONBindingTags(b.codec, data, obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return bindingValidationError(obj, binding.Validator.ValidateStruct(obj))
+}
+
+// writeJSON writes obj as JSON with the JSON codec of the engine.
+func (w *contextWrapper) writeJSON(status int, obj any) {
+	data, err := w.config.jsonCodec.Marshal(obj)
+	if err != nil {
+		_ = w.c.Error(err)
+		w.c.Status(http.StatusInternalServerError)
+		return
+	}
+	w.c.Data(status, "application/json; charset=utf-8", data)
+}
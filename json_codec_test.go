@@ -0,0 +1,69 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// countingCodec wraps StdJSONCodec and counts its calls.
+type countingCodec struct {
+	marshals   int
+	unmarshals int
+}
+
+func (c *countingCodec) Marshal(v any) ([]byte, error) {
+	c.marshals++
+	return StdJSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v any) error {
+	c.unmarshals++
+	return StdJSONCodec.Unmarshal(data, v)
+}
+
+func (c *countingCodec) NewEncoder(w io.Writer) JSONStreamEncoder {
+	return StdJSONCodec.NewEncoder(w)
+}
+
+func TestEngine_SetJSONCodec(t *testing.T) {
+	codec := &countingCodec{}
+	e := New()
+	e.SetJSONCodec(codec)
+	e.POST("/echo", func(c Context) {
+		var body map[string]string
+		if !c.MustBindJSON(&body) {
+			return
+		}
+		c.RespondOk(body)
+	})
+	e.GET("/error", func(c Context) {
+		c.HandleError(NewConflictError("failed"))
+	})
+
+	w := serveRequest(e, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"a":"b"}`)))
+	if w.Code != http.StatusOK || w.Body.String() != `{"a":"b"}` {
+		t.Fatal("expected echoed body, got", w.Code, w.Body.String())
+	}
+	if codec.unmarshals != 1 || codec.marshals != 1 {
+		t.Fatal("expected codec to bind and respond, got", codec.unmarshals, codec.marshals)
+	}
+
+	if w := serve(e, http.MethodGet, "/error"); w.Body.String() != `{"error":"failed"}` {
+		t.Fatal("unexpected error response", w.Body.String())
+	}
+	if codec.marshals != 2 {
+		t.Fatal("expected codec to write error responses, got", codec.marshals)
+	}
+
+	w = serveRequest(e, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(" ")))
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"request body is missing"}` {
+		t.Fatal("expected missing body response, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContext_RespondJSONStream(t *testing.T) {
+	e := New()
+	e.GET("/items", func(c Context) {
+		items := make(chan any)
+		go func() {
+			defer close(items)
+			for i := 0; i < 3; i++ {
+				items <- map[string]int{"id": i}
+			}
+		}()
+		_ = c.RespondJSONStream(items)
+	})
+	e.GET("/empty", func(c Context) {
+		items := make(chan any)
+		close(items)
+		_ = c.RespondJSONStream(items)
+	})
+
+	w := serve(e, http.MethodGet, "/items")
+	if w.Code != http.StatusOK || w.Body.String() != `[{"id":0},{"id":1},{"id":2}]` {
+		t.Fatal("expected JSON array, got", w.Code, w.Body.String())
+	}
+	if !w.Flushed {
+		t.Fatal("expected items to be flushed")
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Fatal("unexpected content type", ct)
+	}
+	if w := serve(e, http.MethodGet, "/empty"); w.Body.String() != `[]` {
+		t.Fatal("expected empty array, got", w.Body.String())
+	}
+}
+
+func TestContext_RespondJSONStream_ClientGone(t *testing.T) {
+	var result error
+	e := New()
+	e.GET("/items", func(c Context) {
+		result = c.RespondJSONStream(make(chan any))
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	serveRequest(e, httptest.NewRequest(http.MethodGet, "/items", nil).WithContext(ctx))
+	if !errors.Is(result, context.Canceled) {
+		t.Fatal("expected context.Canceled, got", result)
+	}
+}
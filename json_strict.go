@@ -0,0 +1,122 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var (
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// rejectUnknownJSONFields returns a *BindingError listing all keys in data not matching a field of obj.
+// Keys of nested objects and of objects in arrays are reported with their path, e.g. items[0].nmae.
+func rejectUnknownJSONFields(codec JSONCodec, data []byte, obj any) error {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	e := &BindingError{}
+	findUnknownJSONFields(codec, data, t, "", e)
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+func findUnknownJSONFields(codec JSONCodec, data []byte, t reflect.Type, path string, e *BindingError) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType || t == rawMessageType:
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		var items []json.RawMessage
+		if err := codec.Unmarshal(data, &items); err != nil {
+			return
+		}
+		for i, item := range items {
+			findUnknownJSONFields(codec, item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), e)
+		}
+	case t.Kind() == reflect.Struct:
+		if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+			return
+		}
+		var present map[string]json.RawMessage
+		if err := codec.Unmarshal(data, &present); err != nil {
+			return
+		}
+		fields := jsonFieldTypes(t)
+		keys := make([]string, 0, len(present))
+		for key := range present {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field := key
+			if len(path) > 0 {
+				field = path + "." + key
+			}
+			_, ft, ok := lookupJSONField(fields, key)
+			if !ok {
+				e.Fields = append(e.Fields, FieldBindingError{
+					Field:   field,
+					Message: fmt.Sprintf("unknown field %s", field),
+				})
+				continue
+			}
+			findUnknownJSONFields(codec, present[key], ft, field, e)
+		}
+	}
+}
+
+// jsonFieldTypes returns the types of the fields of a struct by their JSON name, including promoted fields.
+func jsonFieldTypes(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && len(tag) == 0 && ft.Kind() == reflect.Struct {
+			for name, nested := range jsonFieldTypes(ft) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = nested
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		fields[jsonFieldName(f)] = f.Type
+	}
+	return fields
+}
+
+// lookupJSONField looks up a field like encoding/json matches keys to fields, preferring an exact match.
+// It returns the JSON name and the type of the field.
+func lookupJSONField(fields map[string]reflect.Type, key string) (string, reflect.Type, bool) {
+	if t, ok := fields[key]; ok {
+		return key, t, true
+	}
+	for name, t := range fields {
+		if strings.EqualFold(name, key) {
+			return name, t, true
+		}
+	}
+	return "", nil, false
+}
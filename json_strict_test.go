@@ -0,0 +1,58 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type strictTestBase struct {
+	ID string `json:"id"`
+}
+
+type strictTestItem struct {
+	Name string `json:"name"`
+}
+
+type strictTestBody struct {
+	strictTestBase
+	Email    string            `json:"email"`
+	Created  time.Time         `json:"created"`
+	Items    []strictTestItem  `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Extra    json.RawMessage   `json:"extra"`
+	Internal string            `json:"-"`
+}
+
+func TestMustBindJSONStrict(t *testing.T) {
+	e := New()
+	e.POST("/strict", func(c Context) {
+		var body strictTestBody
+		if c.MustBindJSONStrict(&body) {
+			c.RespondNoContent()
+		}
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, "/strict", strings.NewReader(body)))
+	}
+
+	valid := `{"id":"1","Email":"a@b.c","created":"2023-01-01T00:00:00Z","items":[{"name":"a"}],"labels":{"any":"x"},"extra":{"free":1}}`
+	if w := post(valid); w.Code != http.StatusNoContent {
+		t.Error("expected known fields to pass, got", w.Code, w.Body.String())
+	}
+	w := post(`{"emial":"a@b.c","items":[{"name":"a"},{"nmae":"b"}],"Internal":"x"}`)
+	expected := `{"error":"unknown field Internal, unknown field emial, unknown field items[1].nmae","fields":[` +
+		`{"field":"Internal","message":"unknown field Internal"},` +
+		`{"field":"emial","message":"unknown field emial"},` +
+		`{"field":"items[1].nmae","message":"unknown field items[1].nmae"}]}`
+	if w.Code != http.StatusBadRequest || w.Body.String() != expected {
+		t.Error("expected unknown fields to be listed, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jugtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cfichtmueller/jug"
+)
+
+// Client sends requests to an engine in-process.
+type Client struct {
+	engine  jug.Engine
+	headers http.Header
+}
+
+// NewClient creates a client for e.
+func NewClient(e jug.Engine) *Client {
+	return &Client{
+		engine:  e,
+		headers: make(http.Header),
+	}
+}
+
+// WithHeader sets a header sent with every request of the client.
+func (c *Client) WithHeader(key string, value string) *Client {
+	c.headers.Set(key, value)
+	return c
+}
+
+func (c *Client) GET(path string) *Request {
+	return c.Request(http.MethodGet, path)
+}
+
+func (c *Client) POST(path string) *Request {
+	return c.Request(http.MethodPost, path)
+}
+
+func (c *Client) PUT(path string) *Request {
+	return c.Request(http.MethodPut, path)
+}
+
+func (c *Client) PATCH(path string) *Request {
+	return c.Request(http.MethodPatch, path)
+}
+
+func (c *Client) DELETE(path string) *Request {
+	return c.Request(http.MethodDelete, path)
+}
+
+func (c *Client) HEAD(path string) *Request {
+	return c.Request(http.MethodHead, path)
+}
+
+func (c *Client) OPTIONS(path string) *Request {
+	return c.Request(http.MethodOptions, path)
+}
+
+// Request starts building a request.
+func (c *Client) Request(method string, path string) *Request {
+	return &Request{
+		client:  c,
+		method:  method,
+		path:    path,
+		query:   make(url.Values),
+		headers: c.headers.Clone(),
+	}
+}
+
+// Request is a request being built.
+type Request struct {
+	client  *Client
+	method  string
+	path    string
+	query   url.Values
+	headers http.Header
+	cookies []*http.Cookie
+	body    []byte
+	err     error
+}
+
+// WithQuery adds a query parameter.
+func (r *Request) WithQuery(key string, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// WithHeader sets a request header.
+func (r *Request) WithHeader(key string, value string) *Request {
+	r.headers.Set(key, value)
+	return r
+}
+
+// WithCookie adds a cookie.
+func (r *Request) WithCookie(cookie *http.Cookie) *Request {
+	r.cookies = append(r.cookies, cookie)
+	return r
+}
+
+// WithBasicAuth sets the Authorization header for basic authentication.
+func (r *Request) WithBasicAuth(username string, password string) *Request {
+	req := http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	return r.WithHeader("Authorization", req.Header.Get("Authorization"))
+}
+
+// WithBearerToken sets the Authorization header for bearer authentication.
+func (r *Request) WithBearerToken(token string) *Request {
+	return r.WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithBody sets the request body and its content type.
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.body = body
+	r.headers.Set("Content-Type", contentType)
+	return r
+}
+
+// WithJSON marshals body to JSON and sets it as request body.
+func (r *Request) WithJSON(body any) *Request {
+	data, err := json.Marshal(body)
+	if err != nil {
+		r.err = fmt.Errorf("unable to marshal request body: %w", err)
+	}
+	return r.WithBody("application/json", data)
+}
+
+// Do sends the request and returns the recorded response.
+func (r *Request) Do() *httptest.ResponseRecorder {
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+	var body io.Reader
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req := httptest.NewRequest(r.method, target, body)
+	req.Header = r.headers.Clone()
+	for _, cookie := range r.cookies {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	r.client.engine.ServeHTTP(w, req)
+	return w
+}
+
+// Expect sends the request and returns the response for assertions. Failed assertions are reported to t.
+func (r *Request) Expect(t testing.TB) *Response {
+	t.Helper()
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	return &Response{t: t, Recorder: r.Do()}
+}
+
+// Response is a recorded response with assertions. Failed assertions mark the test as failed and continue.
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+	json     any
+	jsonErr  error
+	decoded  bool
+}
+
+// Status asserts the status code.
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("expected status %d, got %d: %s", code, r.Recorder.Code, r.Recorder.Body.String())
+	}
+	return r
+}
+
+// Header asserts a response header value.
+func (r *Response) Header(key string, value string) *Response {
+	r.t.Helper()
+	if actual := r.Recorder.Header().Get(key); actual != value {
+		r.t.Errorf("expected header %s to be %q, got %q", key, value, actual)
+	}
+	return r
+}
+
+// Body asserts the response body.
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+	if actual := r.Recorder.Body.String(); actual != body {
+		r.t.Errorf("expected body %q, got %q", body, actual)
+	}
+	return r
+}
+
+// JSON asserts that the response body is JSON equal to expected.
+func (r *Response) JSON(expected any) *Response {
+	r.t.Helper()
+	actual, ok := r.decodeJSON()
+	if !ok {
+		return r
+	}
+	if !jsonEqual(actual, expected) {
+		r.t.Errorf("expected JSON %s, got %s", mustMarshal(expected), r.Recorder.Body.String())
+	}
+	return r
+}
+
+// JSONPath asserts the value at a dot separated path of the JSON response body, e.g. items.0.name.
+func (r *Response) JSONPath(path string, expected any) *Response {
+	r.t.Helper()
+	doc, ok := r.decodeJSON()
+	if !ok {
+		return r
+	}
+	actual, err := lookupPath(doc, path)
+	if err != nil {
+		r.t.Errorf("%v in %s", err, r.Recorder.Body.String())
+		return r
+	}
+	if !jsonEqual(actual, expected) {
+		r.t.Errorf("expected %s at %s, got %s", mustMarshal(expected), path, mustMarshal(actual))
+	}
+	return r
+}
+
+// Decode unmarshals the JSON response body into v.
+func (r *Response) Decode(v any) *Response {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), v); err != nil {
+		r.t.Errorf("unable to decode response body: %v", err)
+	}
+	return r
+}
+
+func (r *Response) decodeJSON() (any, bool) {
+	r.t.Helper()
+	if !r.decoded {
+		r.decoded = true
+		r.jsonErr = json.Unmarshal(r.Recorder.Body.Bytes(), &r.json)
+	}
+	if r.jsonErr != nil {
+		r.t.Errorf("response body is not JSON: %v: %s", r.jsonErr, r.Recorder.Body.String())
+		return nil, false
+	}
+	return r.json, true
+}
+
+func lookupPath(doc any, path string) (any, error) {
+	current := doc
+	for _, key := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, fmt.Errorf("no value at %s", path)
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("no value at %s", path)
+			}
+			current = v[i]
+		default:
+			return nil, fmt.Errorf("no value at %s", path)
+		}
+	}
+	return current, nil
+}
+
+// jsonEqual compares a decoded JSON value with an arbitrary value by its JSON representation.
+func jsonEqual(actual any, expected any) bool {
+	var normalized any
+	if err := json.Unmarshal(mustMarshal(expected), &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(actual, normalized)
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return []byte(fmt.Sprintf("%v", v))
+	}
+	return data
+}
@@ -0,0 +1,66 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jugtest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/cfichtmueller/jug"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestClient(t *testing.T) {
+	e := jug.New()
+	e.GET("/users", func(c jug.Context) {
+		c.SetHeader("X-Tenant", c.GetHeader("X-Tenant"))
+		c.RespondOk(map[string]any{
+			"items": []user{{ID: 1, Name: c.Query("name")}},
+			"total": 1,
+		})
+	})
+	e.POST("/users", func(c jug.Context) {
+		var u user
+		if !c.MustBindJSON(&u) {
+			return
+		}
+		c.RespondCreated(u)
+	})
+
+	client := NewClient(e).WithHeader("X-Tenant", "acme")
+	client.GET("/users").WithQuery("name", "jug").Expect(t).
+		Status(http.StatusOK).
+		Header("X-Tenant", "acme").
+		JSONPath("total", 1).
+		JSONPath("items.0.name", "jug").
+		JSON(map[string]any{"items": []user{{ID: 1, Name: "jug"}}, "total": 1})
+
+	var created user
+	client.POST("/users").WithJSON(user{ID: 2, Name: "gin"}).Expect(t).
+		Status(http.StatusCreated).
+		Body(`{"id":2,"name":"gin"}`).
+		Decode(&created)
+	if created.Name != "gin" {
+		t.Fatal("expected decoded user, got", created)
+	}
+
+	client.POST("/users").WithBody("application/json", []byte("{")).Expect(t).Status(http.StatusBadRequest)
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := map[string]any{"items": []any{map[string]any{"name": "jug"}}}
+	if v, err := lookupPath(doc, "items.0.name"); err != nil || v != "jug" {
+		t.Fatal("lookupPath() =", v, err)
+	}
+	for _, path := range []string{"items.1.name", "items.x", "missing", "items.0.name.first"} {
+		if _, err := lookupPath(doc, path); err == nil {
+			t.Fatal("expected error for", path)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+// Package jugtest provides utilities for testing jug engines and handlers.
+package jugtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cfichtmueller/jug"
+)
+
+// RunEngineConformance verifies that engines created by factory behave like the reference engine.
+// It covers routing, groups, middleware order, ExpandMethods, binding and the Respond helpers.
+// Each case runs as a subtest on a new engine.
+func RunEngineConformance(t *testing.T, factory func() jug.Engine) {
+	cases := []struct {
+		name string
+		run  func(t *testing.T, e jug.Engine)
+	}{
+		{"Routing", conformRouting},
+		{"PathParameters", conformPathParameters},
+		{"Groups", conformGroups},
+		{"MiddlewareOrder", conformMiddlewareOrder},
+		{"Abort", conformAbort},
+		{"ExpandMethods", conformExpandMethods},
+		{"Binding", conformBinding},
+		{"Respond", conformRespond},
+		{"HandleError", conformHandleError},
+	}
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			c.run(t, factory())
+		})
+	}
+}
+
+type conformanceResponse struct {
+	code   int
+	body   string
+	header http.Header
+}
+
+func do(e jug.Engine, method string, path string, body string) conformanceResponse {
+	var r io.Reader
+	if len(body) > 0 {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, r)
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return conformanceResponse{code: w.Code, body: w.Body.String(), header: w.Header()}
+}
+
+func expect(t *testing.T, res conformanceResponse, code int, body string) {
+	t.Helper()
+	if res.code != code {
+		t.Fatalf("expected status %d, got %d", code, res.code)
+	}
+	if res.body != body {
+		t.Fatalf("expected body %q, got %q", body, res.body)
+	}
+}
+
+func text(s string) jug.HandlerFunc {
+	return func(c jug.Context) {
+		c.String(http.StatusOK, s)
+	}
+}
+
+func conformRouting(t *testing.T, e jug.Engine) {
+	e.GET("/resource", text("get"))
+	e.POST("/resource", text("post"))
+	e.PUT("/resource", text("put"))
+	e.DELETE("/resource", text("delete"))
+	e.PATCH("/resource", text("patch"))
+	e.OPTIONS("/resource", text("options"))
+	e.Any("/any", text("any"))
+
+	for _, m := range []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"} {
+		expect(t, do(e, m, "/resource", ""), http.StatusOK, strings.ToLower(m))
+		expect(t, do(e, m, "/any", ""), http.StatusOK, "any")
+	}
+	if res := do(e, http.MethodGet, "/missing", ""); res.code != http.StatusNotFound {
+		t.Fatal("expected 404 for unknown path, got", res.code)
+	}
+}
+
+func conformPathParameters(t *testing.T, e jug.Engine) {
+	e.GET("/users/:id", func(c jug.Context) {
+		c.String(http.StatusOK, "user %s", c.Param("id"))
+	})
+	e.GET("/files/*path", func(c jug.Context) {
+		c.String(http.StatusOK, "file %s", c.Param("path"))
+	})
+	expect(t, do(e, http.MethodGet, "/users/42", ""), http.StatusOK, "user 42")
+	expect(t, do(e, http.MethodGet, "/files/a/b.txt", ""), http.StatusOK, "file /a/b.txt")
+}
+
+func conformGroups(t *testing.T, e jug.Engine) {
+	api := e.Group("/api", func(c jug.Context) {
+		c.SetHeader("X-Group", "api")
+	})
+	api.GET("/status", text("api"))
+	api.Group("/v1").GET("/status", text("v1"))
+
+	res := do(e, http.MethodGet, "/api/status", "")
+	expect(t, res, http.StatusOK, "api")
+	if res.header.Get("X-Group") != "api" {
+		t.Fatal("expected group middleware to run")
+	}
+	res = do(e, http.MethodGet, "/api/v1/status", "")
+	expect(t, res, http.StatusOK, "v1")
+	if res.header.Get("X-Group") != "api" {
+		t.Fatal("expected parent group middleware to run for nested groups")
+	}
+}
+
+func conformMiddlewareOrder(t *testing.T, e jug.Engine) {
+	calls := make([]string, 0)
+	trace := func(name string) jug.HandlerFunc {
+		return func(c jug.Context) {
+			calls = append(calls, name+">")
+			c.Next()
+			calls = append(calls, "<"+name)
+		}
+	}
+	e.Use(trace("engine"))
+	g := e.Group("/g", trace("group"))
+	g.GET("/route", trace("route"), func(c jug.Context) {
+		calls = append(calls, "handler")
+		c.Status(http.StatusNoContent)
+	})
+	if res := do(e, http.MethodGet, "/g/route", ""); res.code != http.StatusNoContent {
+		t.Fatal("expected 204, got", res.code)
+	}
+	expected := "engine> group> route> handler <route <group <engine"
+	if trace := strings.Join(calls, " "); trace != expected {
+		t.Fatalf("expected %s, got %s", expected, trace)
+	}
+}
+
+func conformAbort(t *testing.T, e jug.Engine) {
+	e.GET("/guarded", func(c jug.Context) {
+		c.Status(http.StatusForbidden)
+		c.Abort()
+	}, text("handler"))
+	expect(t, do(e, http.MethodGet, "/guarded", ""), http.StatusForbidden, "")
+}
+
+func conformExpandMethods(t *testing.T, e jug.Engine) {
+	e.GET("/resource", text("get"))
+	e.Group("/api").POST("/items", text("post"))
+	e.ExpandMethods()
+	e.ExpandMethods()
+
+	expect(t, do(e, http.MethodGet, "/resource", ""), http.StatusOK, "get")
+	if res := do(e, http.MethodPut, "/resource", ""); res.code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 for unhandled method, got", res.code)
+	}
+	if res := do(e, http.MethodGet, "/api/items", ""); res.code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 for unhandled method in group, got", res.code)
+	}
+}
+
+type conformanceUser struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func conformBinding(t *testing.T, e jug.Engine) {
+	e.POST("/users", func(c jug.Context) {
+		var u conformanceUser
+		if !c.MustBindJSON(&u) {
+			return
+		}
+		c.RespondCreated(u)
+	})
+	expect(t, do(e, http.MethodPost, "/users", `{"name":"jug"}`), http.StatusCreated, `{"name":"jug"}`)
+	if res := do(e, http.MethodPost, "/users", `{"name":`); res.code != http.StatusBadRequest {
+		t.Fatal("expected 400 for malformed JSON, got", res.code)
+	}
+	if res := do(e, http.MethodPost, "/users", `{"name":""}`); res.code != http.StatusBadRequest {
+		t.Fatal("expected 400 for invalid JSON, got", res.code)
+	}
+}
+
+func conformRespond(t *testing.T, e jug.Engine) {
+	e.GET("/ok", func(c jug.Context) { c.RespondOk(map[string]string{"status": "ok"}) })
+	e.GET("/no-content", func(c jug.Context) { c.RespondNoContent() })
+	e.GET("/bad-request", func(c jug.Context) { c.RespondBadRequestE(errString("invalid")) })
+	e.GET("/unauthorized", func(c jug.Context) { c.RespondUnauthorizedE(errString("unauthorized")) })
+	e.GET("/forbidden", func(c jug.Context) { c.RespondForbiddenE(errString("forbidden")) })
+	e.GET("/not-found", func(c jug.Context) { c.RespondNotFoundE(errString("not found")) })
+	e.GET("/conflict", func(c jug.Context) { c.RespondConflictE(errString("conflict")) })
+	e.GET("/error", func(c jug.Context) { c.RespondInternalServerErrorE(errString("error")) })
+
+	res := do(e, http.MethodGet, "/ok", "")
+	expect(t, res, http.StatusOK, `{"status":"ok"}`)
+	if ct := res.header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatal("expected JSON content type, got", ct)
+	}
+	expect(t, do(e, http.MethodGet, "/no-content", ""), http.StatusNoContent, "")
+	expect(t, do(e, http.MethodGet, "/bad-request", ""), http.StatusBadRequest, `{"error":"invalid"}`)
+	expect(t, do(e, http.MethodGet, "/unauthorized", ""), http.StatusUnauthorized, `{"error":"unauthorized"}`)
+	expect(t, do(e, http.MethodGet, "/forbidden", ""), http.StatusForbidden, `{"error":"forbidden"}`)
+	expect(t, do(e, http.MethodGet, "/not-found", ""), http.StatusNotFound, `{"error":"not found"}`)
+	expect(t, do(e, http.MethodGet, "/conflict", ""), http.StatusConflict, `{"error":"conflict"}`)
+	expect(t, do(e, http.MethodGet, "/error", ""), http.StatusInternalServerError, `{"error":"error"}`)
+}
+
+func conformHandleError(t *testing.T, e jug.Engine) {
+	e.GET("/status", func(c jug.Context) { c.HandleError(jug.NewConflictError("taken")) })
+	e.GET("/validation", func(c jug.Context) {
+		c.HandleError(jug.NewValidator().Structured().RequireStringNotEmpty("", "name is required").Validate())
+	})
+	e.GET("/unknown", func(c jug.Context) { c.HandleError(errString("boom")) })
+
+	expect(t, do(e, http.MethodGet, "/status", ""), http.StatusConflict, `{"error":"taken"}`)
+	if res := do(e, http.MethodGet, "/validation", ""); res.code != http.StatusBadRequest {
+		t.Fatal("expected 400 for validation errors, got", res.code)
+	}
+	if res := do(e, http.MethodGet, "/unknown", ""); res.code != http.StatusInternalServerError {
+		t.Fatal("expected 500 for unknown errors, got", res.code)
+	}
+}
+
+type errString string
+
+func (e errString) Error() string {
+	return string(e)
+}
@@ -0,0 +1,17 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jugtest
+
+import (
+	"testing"
+
+	"github.com/cfichtmueller/jug"
+)
+
+func TestRunEngineConformance(t *testing.T) {
+	RunEngineConformance(t, func() jug.Engine {
+		return jug.New()
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefreshInterval limits refreshes triggered by unknown key ids or failed fetches.
+const jwksMinRefreshInterval = time.Minute
+
+// jwks caches the keys of a JSON Web Key Set.
+type jwks struct {
+	url             string
+	refreshInterval time.Duration
+	client          *http.Client
+	mu              sync.Mutex
+	keys            map[string]any
+	fetched         time.Time
+	attempted       time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func newJWKS(url string, refreshInterval time.Duration, client *http.Client) *jwks {
+	if refreshInterval <= 0 {
+		refreshInterval = time.Hour
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &jwks{
+		url:             url,
+		refreshInterval: refreshInterval,
+		client:          client,
+	}
+}
+
+// key returns the key with the given id. The key set is refreshed if it is outdated or the key is unknown.
+func (j *jwks) key(kid string, _ string) (any, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	key, ok := j.keys[kid]
+	stale := now.Sub(j.fetched) > j.refreshInterval
+	if (stale || !ok) && now.Sub(j.attempted) > jwksMinRefreshInterval {
+		j.attempted = now
+		if err := j.refresh(); err != nil && j.keys == nil {
+			return nil, err
+		}
+		key, ok = j.keys[kid]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+	return key, nil
+}
+
+func (j *jwks) refresh() error {
+	res, err := j.client.Get(j.url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to fetch key set: %s", res.Status)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+		return err
+	}
+	keys := make(map[string]any)
+	for _, k := range set.Keys {
+		if len(k.Use) > 0 && k.Use != "sig" {
+			continue
+		}
+		if key, err := k.publicKey(); err == nil {
+			keys[k.Kid] = key
+		}
+	}
+	j.keys = keys
+	j.fetched = time.Now()
+	return nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
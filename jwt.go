@@ -0,0 +1,359 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClaimsKey is the context key holding the claims of a validated token.
+const ClaimsKey = "jug.claims"
+
+var (
+	// ErrMissingToken is returned when a request carries no token.
+	ErrMissingToken = errors.New("missing token")
+	// ErrInvalidToken is returned when a token is malformed or its signature is invalid.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrExpiredToken is returned when a token has expired or is not valid yet.
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// JWTConfig configures the JWT middleware.
+type JWTConfig struct {
+	// Key verifies token signatures. Use a []byte for HS256/384/512, an *rsa.PublicKey for RS256/384/512 and
+	// PS256/384/512 and an *ecdsa.PublicKey for ES256/384/512.
+	Key any
+	// KeyFunc selects the key by the kid and alg header of a token. It takes precedence over Key.
+	KeyFunc func(kid string, alg string) (any, error)
+	// JWKSURL is the URL of a JSON Web Key Set. Keys are selected by kid and refreshed periodically.
+	// It takes precedence over Key and KeyFunc.
+	JWKSURL string
+	// JWKSRefreshInterval is the interval in which the key set is refreshed. Defaults to one hour.
+	JWKSRefreshInterval time.Duration
+	// HTTPClient is used to fetch the key set. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Issuer is the required iss claim. It is not checked if empty.
+	Issuer string
+	// Audience is the required aud claim. It is not checked if empty.
+	Audience string
+	// Leeway is the clock skew allowed when checking exp and nbf.
+	Leeway time.Duration
+	// Optional lets requests without a token pass. Requests with an invalid token are still rejected.
+	Optional bool
+	// TokenFunc extracts the token from a request. Defaults to the bearer token of the Authorization header.
+	TokenFunc func(c Context) string
+}
+
+// Claims holds the claims of a token.
+type Claims map[string]any
+
+// Subject returns the sub claim.
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// Issuer returns the iss claim.
+func (c Claims) Issuer() string {
+	s, _ := c["iss"].(string)
+	return s
+}
+
+// Audience returns the aud claim, which may be a single string or a list.
+func (c Claims) Audience() []string {
+	return c.strings("aud")
+}
+
+// ExpiresAt returns the exp claim.
+func (c Claims) ExpiresAt() (time.Time, bool) {
+	return c.time("exp")
+}
+
+// Scopes returns the space separated scope claim or, if missing, the scp claim.
+func (c Claims) Scopes() []string {
+	if s, ok := c["scope"].(string); ok {
+		return strings.Fields(s)
+	}
+	return c.strings("scp")
+}
+
+// Roles returns the roles claim.
+func (c Claims) Roles() []string {
+	return c.strings("roles")
+}
+
+// HasScope reports whether the token was granted the given scope.
+func (c Claims) HasScope(scope string) bool {
+	return contains(c.Scopes(), scope)
+}
+
+// HasRole reports whether the token holds the given role.
+func (c Claims) HasRole(role string) bool {
+	return contains(c.Roles(), role)
+}
+
+func (c Claims) strings(key string) []string {
+	switch v := c[key].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	}
+	return nil
+}
+
+func (c Claims) time(key string) (time.Time, bool) {
+	if n, ok := c[key].(float64); ok {
+		return time.Unix(int64(n), 0), true
+	}
+	return time.Time{}, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// JWT returns a middleware that validates JSON Web Tokens. Claims of valid tokens are stored in the context
+// and can be read with Context.Claims. Requests with a missing or invalid token are aborted with 401.
+func JWT(config JWTConfig) HandlerFunc {
+	keyFunc := config.KeyFunc
+	if len(config.JWKSURL) > 0 {
+		keyFunc = newJWKS(config.JWKSURL, config.JWKSRefreshInterval, config.HTTPClient).key
+	}
+	if keyFunc == nil {
+		if config.Key == nil {
+			panic("jug: JWT requires a key")
+		}
+		keyFunc = func(string, string) (any, error) {
+			return config.Key, nil
+		}
+	}
+	tokenFunc := config.TokenFunc
+	if tokenFunc == nil {
+		tokenFunc = bearerToken
+	}
+	return func(c Context) {
+		token := tokenFunc(c)
+		if len(token) == 0 {
+			if !config.Optional {
+				c.HandleError(NewUnauthorizedError(ErrMissingToken.Error()))
+				c.Abort()
+			}
+			return
+		}
+		claims, err := parseJWT(token, keyFunc)
+		if err == nil {
+			err = config.validateClaims(claims, time.Now())
+		}
+		if err != nil {
+			c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.HandleError(NewUnauthorizedError(err.Error()))
+			c.Abort()
+			return
+		}
+		c.Set(ClaimsKey, claims)
+	}
+}
+
+// RequireScopes returns a middleware that requires the token to be granted all given scopes.
+// It must run after the JWT middleware. Requests failing the requirement are aborted with 403.
+func RequireScopes(scopes ...string) HandlerFunc {
+	return requireClaims(func(claims Claims) error {
+		for _, s := range scopes {
+			if !claims.HasScope(s) {
+				return fmt.Errorf("scope %s is required", s)
+			}
+		}
+		return nil
+	})
+}
+
+// RequireRoles returns a middleware that requires the token to hold at least one of the given roles.
+// It must run after the JWT middleware. Requests failing the requirement are aborted with 403.
+func RequireRoles(roles ...string) HandlerFunc {
+	return requireClaims(func(claims Claims) error {
+		for _, r := range roles {
+			if claims.HasRole(r) {
+				return nil
+			}
+		}
+		return fmt.Errorf("one of the roles %s is required", strings.Join(roles, ", "))
+	})
+}
+
+func requireClaims(check func(claims Claims) error) HandlerFunc {
+	return func(c Context) {
+		claims, ok := c.Claims()
+		if !ok {
+			c.HandleError(NewUnauthorizedError(ErrMissingToken.Error()))
+			c.Abort()
+			return
+		}
+		if err := check(claims); err != nil {
+			c.HandleError(NewForbiddenError(err.Error()))
+			c.Abort()
+		}
+	}
+}
+
+func bearerToken(c Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func (config JWTConfig) validateClaims(claims Claims, now time.Time) error {
+	for _, key := range []string{"exp", "nbf"} {
+		if _, ok := claims[key]; ok {
+			if _, ok := claims.time(key); !ok {
+				return fmt.Errorf("%w: invalid %s claim", ErrInvalidToken, key)
+			}
+		}
+	}
+	if exp, ok := claims.ExpiresAt(); ok && !now.Before(exp.Add(config.Leeway)) {
+		return ErrExpiredToken
+	}
+	if nbf, ok := claims.time("nbf"); ok && now.Add(config.Leeway).Before(nbf) {
+		return ErrExpiredToken
+	}
+	if len(config.Issuer) > 0 && claims.Issuer() != config.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if len(config.Audience) > 0 && !contains(claims.Audience(), config.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// parseJWT parses a token in compact serialization and verifies its signature.
+func parseJWT(token string, keyFunc func(kid string, alg string) (any, error)) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	key, err := keyFunc(header.Kid, header.Alg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, obj any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, obj)
+}
+
+func hashFor(alg string) (crypto.Hash, bool) {
+	if len(alg) != 5 {
+		return 0, false
+	}
+	switch alg[2:] {
+	case "256":
+		return crypto.SHA256, true
+	case "384":
+		return crypto.SHA384, true
+	case "512":
+		return crypto.SHA512, true
+	}
+	return 0, false
+}
+
+// verifySignature verifies a signature. The key type must match the algorithm to prevent algorithm confusion.
+func verifySignature(alg string, key any, signed string, sig []byte) error {
+	hash, ok := hashFor(alg)
+	if !ok || !hash.Available() {
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, alg)
+	}
+	var valid bool
+	switch alg[:2] {
+	case "HS":
+		k, ok := key.([]byte)
+		if !ok {
+			return ErrInvalidToken
+		}
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signed))
+		valid = hmac.Equal(sig, mac.Sum(nil))
+	case "RS", "PS":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidToken
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		if alg[0] == 'R' {
+			valid = rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), sig) == nil
+		} else {
+			valid = rsa.VerifyPSS(k, hash, h.Sum(nil), sig, nil) == nil
+		}
+	case "ES":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrInvalidToken
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return ErrInvalidToken
+		}
+		h := hash.New()
+		h.Write([]byte(signed))
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		valid = ecdsa.Verify(k, h.Sum(nil), r, s)
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %s", ErrInvalidToken, alg)
+	}
+	if !valid {
+		return ErrInvalidToken
+	}
+	return nil
+}
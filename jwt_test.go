@@ -0,0 +1,152 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signJWT(t *testing.T, alg string, kid string, key any, claims Claims) string {
+	header, _ := json.Marshal(jwtHeader{Alg: alg, Kid: kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash, _ := hashFor(alg)
+	h := hash.New()
+	h.Write([]byte(signed))
+	var sig []byte
+	var err error
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, k)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, h.Sum(nil))
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k, h.Sum(nil))
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func serveWithToken(e Engine, token string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if len(token) > 0 {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return serveRequest(e, r)
+}
+
+func jwtTestEngine(config JWTConfig, handlers ...HandlerFunc) Engine {
+	e := New()
+	e.Use(JWT(config))
+	handlers = append(handlers, func(c Context) {
+		claims, _ := c.Claims()
+		c.String(http.StatusOK, claims.Subject())
+	})
+	e.GET("/", handlers...)
+	return e
+}
+
+func TestJWT_HMAC(t *testing.T) {
+	key := []byte("secret")
+	e := jwtTestEngine(JWTConfig{Key: key, Issuer: "jug"})
+	exp := float64(time.Now().Add(time.Hour).Unix())
+
+	tests := []struct {
+		token    string
+		expected int
+	}{
+		{"", http.StatusUnauthorized},
+		{"not.a.token", http.StatusUnauthorized},
+		{signJWT(t, "HS256", "", key, Claims{"sub": "alice", "iss": "jug", "exp": exp}), http.StatusOK},
+		{signJWT(t, "HS512", "", key, Claims{"sub": "alice", "iss": "jug"}), http.StatusOK},
+		{signJWT(t, "HS256", "", []byte("other"), Claims{"sub": "alice", "iss": "jug"}), http.StatusUnauthorized},
+		{signJWT(t, "HS256", "", key, Claims{"sub": "alice", "iss": "other"}), http.StatusUnauthorized},
+		{signJWT(t, "HS256", "", key, Claims{"sub": "alice", "iss": "jug", "exp": float64(time.Now().Add(-time.Minute).Unix())}), http.StatusUnauthorized},
+		{signJWT(t, "HS256", "", key, Claims{"sub": "alice", "iss": "jug", "exp": "tomorrow"}), http.StatusUnauthorized},
+		{signJWT(t, "HS256", "", key, Claims{"sub": "alice", "iss": "jug", "nbf": "now"}), http.StatusUnauthorized},
+	}
+	for i, test := range tests {
+		w := serveWithToken(e, test.token)
+		if w.Code != test.expected {
+			t.Errorf("test %d: expected %d, got %d %s", i, test.expected, w.Code, w.Body.String())
+		}
+	}
+	if w := serveWithToken(e, tests[2].token); w.Body.String() != "alice" {
+		t.Error("expected claims in context, got", w.Body.String())
+	}
+}
+
+func TestJWT_AlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	e := jwtTestEngine(JWTConfig{Key: &rsaKey.PublicKey})
+	token := signJWT(t, "HS256", "", []byte("secret"), Claims{"sub": "mallory"})
+	if w := serveWithToken(e, token); w.Code != http.StatusUnauthorized {
+		t.Fatal("expected HMAC token to be rejected for an RSA key, got", w.Code)
+	}
+	token = signJWT(t, "RS256", "", rsaKey, Claims{"sub": "alice"})
+	if w := serveWithToken(e, token); w.Code != http.StatusOK {
+		t.Fatal("expected RSA token to be accepted, got", w.Code)
+	}
+}
+
+func TestJWT_JWKS(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []jsonWebKey{{
+			Kty: "EC",
+			Kid: "k1",
+			Crv: "P-256",
+			X:   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+			Y:   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+		}}})
+	}))
+	defer jwksServer.Close()
+
+	e := jwtTestEngine(JWTConfig{JWKSURL: jwksServer.URL})
+	if w := serveWithToken(e, signJWT(t, "ES256", "k1", ecKey, Claims{"sub": "alice"})); w.Code != http.StatusOK {
+		t.Fatal("expected token signed by key set to be accepted, got", w.Code, w.Body.String())
+	}
+	if w := serveWithToken(e, signJWT(t, "ES256", "k2", ecKey, Claims{"sub": "alice"})); w.Code != http.StatusUnauthorized {
+		t.Fatal("expected token with unknown key id to be rejected, got", w.Code)
+	}
+}
+
+func TestRequireScopesAndRoles(t *testing.T) {
+	key := []byte("secret")
+	scoped := jwtTestEngine(JWTConfig{Key: key}, RequireScopes("read", "write"))
+	if w := serveWithToken(scoped, signJWT(t, "HS256", "", key, Claims{"scope": "read write"})); w.Code != http.StatusOK {
+		t.Error("expected scopes to be sufficient, got", w.Code)
+	}
+	if w := serveWithToken(scoped, signJWT(t, "HS256", "", key, Claims{"scope": "read"})); w.Code != http.StatusForbidden {
+		t.Error("expected missing scope to be forbidden, got", w.Code)
+	}
+	roles := jwtTestEngine(JWTConfig{Key: key}, RequireRoles("admin", "owner"))
+	if w := serveWithToken(roles, signJWT(t, "HS256", "", key, Claims{"roles": []string{"owner"}})); w.Code != http.StatusOK {
+		t.Error("expected role to be sufficient, got", w.Code)
+	}
+	if w := serveWithToken(roles, signJWT(t, "HS256", "", key, Claims{"roles": []string{"user"}})); w.Code != http.StatusForbidden {
+		t.Error("expected missing role to be forbidden, got", w.Code)
+	}
+}
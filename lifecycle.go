@@ -0,0 +1,94 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// LifecycleHook is a function run when the engine starts or stops.
+type LifecycleHook func(ctx context.Context) error
+
+// LifecycleError holds the errors of failed stop hooks.
+type LifecycleError struct {
+	Errors []error
+}
+
+func (e *LifecycleError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *LifecycleError) Unwrap() []error {
+	return e.Errors
+}
+
+type lifecycle struct {
+	mu      sync.Mutex
+	start   []LifecycleHook
+	stop    []LifecycleHook
+	started bool
+	stopped bool
+}
+
+func (l *lifecycle) onStart(hook LifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.start = append(l.start, hook)
+}
+
+func (l *lifecycle) onStop(hook LifecycleHook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stop = append(l.stop, hook)
+}
+
+// runStart runs the start hooks in registration order. It stops at the first failing hook.
+// Start hooks run once.
+func (l *lifecycle) runStart(ctx context.Context) error {
+	l.mu.Lock()
+	if l.started {
+		l.mu.Unlock()
+		return nil
+	}
+	l.started = true
+	hooks := l.start
+	l.mu.Unlock()
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("start hook %d failed: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// runStop runs the stop hooks in reverse registration order. All hooks run, failures are collected in a LifecycleError.
+// Stop hooks run once.
+func (l *lifecycle) runStop(ctx context.Context) error {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return nil
+	}
+	l.stopped = true
+	hooks := l.stop
+	l.mu.Unlock()
+	errs := make([]error, 0)
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("stop hook %d failed: %w", i+1, err))
+		}
+	}
+	if len(errs) > 0 {
+		return &LifecycleError{Errors: errs}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngine_OnStart_Failure(t *testing.T) {
+	e := New()
+	calls := make([]string, 0)
+	e.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "db")
+		return errors.New("connection refused")
+	})
+	e.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "cache")
+		return nil
+	})
+	err := e.Run("127.0.0.1:0")
+	if err == nil || err.Error() != "start hook 1 failed: connection refused" {
+		t.Fatal("expected start hook error, got", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"db"}) {
+		t.Fatal("expected remaining hooks to be skipped, got", calls)
+	}
+}
+
+func TestEngine_Lifecycle(t *testing.T) {
+	e := New()
+	calls := make([]string, 0)
+	started := make(chan struct{})
+	e.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start db")
+		return nil
+	})
+	e.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start cache")
+		close(started)
+		return nil
+	})
+	e.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop db")
+		return errors.New("db failed")
+	})
+	e.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop cache")
+		return errors.New("cache failed")
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run("127.0.0.1:0")
+	}()
+	<-started
+	time.Sleep(10 * time.Millisecond)
+	err := e.Shutdown(context.Background())
+	var lerr *LifecycleError
+	if !errors.As(err, &lerr) || len(lerr.Errors) != 2 {
+		t.Fatal("expected lifecycle error with both failures, got", err)
+	}
+	if err.Error() != "stop hook 2 failed: cache failed; stop hook 1 failed: db failed" {
+		t.Fatal("unexpected error message", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected Run to return nil, got", err)
+	}
+	expected := []string{"start db", "start cache", "stop cache", "stop db"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal("expected stop hooks to run once, got", err)
+	}
+}
+
+func TestEngine_Run_ListenFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	e := New()
+	calls := make([]string, 0)
+	e.OnStart(func(ctx context.Context) error {
+		calls = append(calls, "start db")
+		return nil
+	})
+	e.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop db")
+		return errors.New("db failed")
+	})
+
+	err = e.Run(l.Addr().String())
+	if err == nil || !strings.HasSuffix(err.Error(), "; stop hook 1 failed: db failed") {
+		t.Fatal("expected listen and stop hook errors, got", err)
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Fatal("expected listen error to be wrapped, got", err)
+	}
+	if !reflect.DeepEqual(calls, []string{"start db", "stop db"}) {
+		t.Fatal("expected stop hooks to run, got", calls)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal("expected stop hooks to run once, got", err)
+	}
+}
+
+// failingListener fails to accept connections.
+type failingListener struct {
+	net.Listener
+}
+
+func (l failingListener) Accept() (net.Conn, error) {
+	return nil, errors.New("accept failed")
+}
+
+func TestEngine_RunListener_ServeFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	stopped := false
+	e.OnStop(func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+
+	if err := e.RunListener(failingListener{Listener: l}); err == nil || err.Error() != "accept failed" {
+		t.Fatal("expected serve error, got", err)
+	}
+	if !stopped {
+		t.Fatal("expected stop hooks to run")
+	}
+}
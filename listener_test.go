@@ -0,0 +1,140 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, client *http.Client, url string) string {
+	t.Helper()
+	res, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	return string(body)
+}
+
+func TestEngine_RunListener(t *testing.T) {
+	e := New()
+	e.GET("/ping", func(c Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	listeners := make([]net.Listener, 2)
+	for i := range listeners {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		listeners[i] = l
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunListener(listeners...)
+	}()
+
+	for _, l := range listeners {
+		if body := get(t, http.DefaultClient, "http://"+l.Addr().String()+"/ping"); body != "pong" {
+			t.Fatal("expected pong, got", body)
+		}
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected RunListener to return nil, got", err)
+	}
+}
+
+func TestEngine_RunUnix(t *testing.T) {
+	e := New()
+	e.GET("/ping", func(c Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	path := filepath.Join(t.TempDir(), "jug.sock")
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunUnix(path)
+	}()
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if body := get(t, client, "http://unix/ping"); body != "pong" {
+		t.Fatal("expected pong, got", body)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal("expected RunUnix to return nil, got", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected socket file to be removed, got", err)
+	}
+}
+
+func TestEngine_Run_AddressInUse(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := New().Run("127.0.0.1:0", l.Addr().String()); err == nil {
+		t.Fatal("expected listen error")
+	}
+}
+
+func TestNew_ServerOptions(t *testing.T) {
+	type ctxKey struct{}
+	e := New(
+		ServerReadTimeout(time.Second),
+		ServerWriteTimeout(2*time.Second),
+		ServerMaxHeaderBytes(1024),
+		ServerBaseContext(func(l net.Listener) context.Context {
+			return context.WithValue(context.Background(), ctxKey{}, "base")
+		}),
+	)
+	server := e.(*ginEngine).serverConfig.newServer(":0", nil)
+	if server.ReadTimeout != time.Second || server.WriteTimeout != 2*time.Second || server.MaxHeaderBytes != 1024 {
+		t.Fatal("expected options to configure the server", server)
+	}
+
+	e.GET("/ctx", func(c Context) {
+		c.String(http.StatusOK, "%v", c.Value(ctxKey{}))
+	})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- e.RunListener(l)
+	}()
+	if body := get(t, http.DefaultClient, "http://"+l.Addr().String()+"/ctx"); body != "base" {
+		t.Fatal("expected base context value, got", body)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+}
@@ -4,22 +4,48 @@
 
 package jug
 
-import "net/http"
+import (
+	"context"
+	"io/fs"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+)
 
-func Default() Engine {
-	return defaultGinEngine()
+// Default creates an engine with logging, panic recovery and the security headers of DefaultSecureConfig.
+func Default(opts ...Option) Engine {
+	return defaultGinEngine(opts)
 }
 
-func New() Engine {
-	return newGinEngine()
+// New creates an engine without any middleware.
+func New(opts ...Option) Engine {
+	return newGinEngine(opts)
 }
 
 type Validatable interface {
 	Validate() error
 }
 
+// validate validates obj using its `validate` struct tags and, if it implements Validatable, its Validate method.
+func validate(obj any) error {
+	return validateWith(NewValidator(), obj)
+}
+
+// validateWith validates obj like validate, using v for the struct tags.
+func validateWith(v *Validator, obj any) error {
+	if err := v.Struct(obj).Validate(); err != nil {
+		return err
+	}
+	if val, ok := obj.(Validatable); ok {
+		return val.Validate()
+	}
+	return nil
+}
+
 type Engine interface {
 	RouterGroup
+	http.Handler
 
 	NoMethod(handlers ...HandlerFunc)
 	NoRoute(handlers ...HandlerFunc)
@@ -27,7 +53,118 @@ type Engine interface {
 	// ExpandMethods expands each non-configured method for each path to return 405 Method not allowed
 	ExpandMethods()
 
+	// Version creates a group for an API version selected by the path, e.g. /v1.
+	// It is a shortcut for Versioned(VersioningConfig{}).Version(version, opts...).
+	Version(version string, opts ...VersionOption) RouterGroup
+
+	// Host creates a group for the routes of a host, e.g. api.example.com. A leading "*." matches any subdomain,
+	// e.g. *.example.com. Requests for other hosts are served by the routes of the engine.
+	// Middleware registered with Use before and the given handlers apply to the routes of the host.
+	Host(pattern string, handlers ...HandlerFunc) RouterGroup
+
+	// Dynamic serves routes below prefix that can be added and removed while the engine is running.
+	Dynamic(prefix string) *DynamicRoutes
+
+	// CheckRoutes analyses the registered routes, including the routes of mounted engines, and reports
+	// conflicting parameter names, shadowed wildcards and paths differing only by a trailing slash.
+	// Call it before ExpandMethods, e.g. in a test, to catch routing mistakes early.
+	// ExpandMethods panics on conflicting parameter names.
+	CheckRoutes() []RouteIssue
+
+	// RegisterEncoder registers an encoder for the given content type.
+	// Respond helpers select an encoder based on the Accept header of the request and fall back to JSON.
+	RegisterEncoder(contentType string, enc Encoder)
+
+	// SetJSONCodec sets the codec used to bind JSON request bodies and to write JSON responses. Defaults to StdJSONCodec.
+	// It replaces the encoder registered for application/json.
+	SetJSONCodec(codec JSONCodec)
+
+	// SetLocation sets the location used to parse dates without time zone information. Defaults to UTC.
+	SetLocation(loc *time.Location)
+
+	// SetQueryParsingMode sets how typed query helpers handle parse errors. Defaults to LenientQueryParsing.
+	SetQueryParsingMode(mode QueryParsingMode)
+
+	// LoadHTMLGlob loads HTML templates matching the given pattern.
+	LoadHTMLGlob(pattern string) error
+	// LoadHTMLFS loads HTML templates matching the given patterns from a file system, e.g. embed.FS.
+	LoadHTMLFS(fsys fs.FS, patterns ...string) error
+	// SetHTMLRenderer sets the renderer used by Context.HTML.
+	SetHTMLRenderer(renderer Renderer)
+
+	// Install installs plugins in the given order. Plugins with the name of an installed plugin are skipped.
+	Install(plugins ...Plugin) error
+
+	// Events returns the event bus of the engine. See the Topic constants for the events published by the engine.
+	Events() *EventBus
+
+	// SetTrustedProxies sets the proxies whose headers are used to resolve Context.ClientIP. Lists contain CIDRs or
+	// single IP addresses. Without trusted proxies, the client IP is the peer address and forwarding headers are ignored.
+	SetTrustedProxies(cidrs []string) error
+	// SetClientIPStrategy sets the header used to resolve the client IP behind trusted proxies. Defaults to ClientIPXForwardedFor.
+	SetClientIPStrategy(strategy ClientIPStrategy)
+
+	// UseProblemJSON makes error responses follow RFC 7807 with the content type application/problem+json.
+	// It applies to the E variants of the Respond helpers, HandleError and AbortWithError.
+	UseProblemJSON()
+
+	// Route returns the route with the given name.
+	Route(name string) (RouteInfo, bool)
+	// Routes returns all routes in registration order, followed by the routes of mounted engines.
+	Routes() []RouteInfo
+	// URL builds the path of the named route. Path parameters are replaced by params in order of appearance.
+	URL(name string, params ...string) (string, error)
+
+	// Health returns the health checks of the engine. The first call mounts the /healthz and /readyz endpoints.
+	Health() *Health
+
+	// SetMaxBodySize sets the default size limit of request bodies in bytes. Zero disables the limit.
+	SetMaxBodySize(n int64)
+
+	// Provide registers the constructor of a request scoped dependency, see Resolve and ResolveKey.
+	// Dependencies are constructed lazily once per request. Providing a key again replaces the constructor.
+	Provide(key string, constructor Constructor)
+
+	// SetTaskPool sets the number of workers running tasks started with Context.Go and the number of tasks waiting for a worker.
+	// Defaults to 8 workers and a queue of 256 tasks. It must be called before the engine serves requests.
+	SetTaskPool(workers int, queueSize int)
+
+	// SetMetrics sets the receiver of the counters emitted by the engine, e.g. binding and validation failures.
+	SetMetrics(metrics Metrics)
+
+	// SetShutdownEvent sets an event that is sent to all event streams when the engine shuts down.
+	SetShutdownEvent(event *Event)
+
+	// OnStart registers a hook that runs before Run starts listening, e.g. to connect to a database.
+	// Hooks run in registration order. If a hook fails, Run returns its error without listening.
+	OnStart(hook LifecycleHook)
+	// OnStop registers a hook that runs after Shutdown drained the engine, e.g. to close a database.
+	// Hooks run in reverse registration order. All hooks run, failures are reported together in a LifecycleError.
+	OnStop(hook LifecycleHook)
+
+	// Run starts listening and serving HTTP requests on all given addresses. If no address is given, the PORT
+	// environment variable or :8080 is used. Run returns nil after the engine was shut down.
+	// If one of the addresses fails, serving stops on all of them and the error is returned.
+	// If listening or serving fails after the start hooks ran, the stop hooks run before Run returns.
 	Run(addr ...string) error
+	// RunListener serves HTTP requests on the given listeners, e.g. sockets passed by systemd.
+	// It behaves like Run.
+	RunListener(listeners ...net.Listener) error
+	// RunH2C serves HTTP/1 and HTTP/2 without TLS (h2c), e.g. for gRPC-web behind a TLS terminating proxy.
+	// It behaves like Run.
+	RunH2C(addr ...string) error
+	// SetHTTP2Config configures HTTP/2 connections served by RunH2C.
+	SetHTTP2Config(cfg HTTP2Config)
+	// RunHTTP3 runs the start hooks and serves HTTP/3 with the given server, which must use the engine as handler.
+	// Shutdown closes the server. Run it next to Run, since clients discover HTTP/3 over TCP, see AdvertiseHTTP3.
+	RunHTTP3(server HTTP3Server) error
+	// RunUnix serves HTTP requests on a unix domain socket. The socket file is removed when the engine stops.
+	// It behaves like Run.
+	RunUnix(path string) error
+
+	// Shutdown gracefully shuts down the engine. Event streams and WebSocket connections are notified
+	// and the engine waits until they are closed and background tasks have finished or ctx is done. Then the stop hooks run.
+	Shutdown(ctx context.Context) error
 
 	EnableDebugMode()
 }
@@ -35,18 +172,63 @@ type Engine interface {
 type RouterGroup interface {
 	Router
 	Group(relativePath string, handlers ...HandlerFunc) RouterGroup
+	// CORS applies a CORS policy to the routes and groups registered afterwards.
+	// Preflight requests are answered with the methods registered for the requested path.
+	CORS(policy CORSPolicy)
+	// SetErrorHandler sets the handler used by Context.HandleError. On the engine it replaces DefaultErrorHandler,
+	// on a group it applies to the routes and groups registered afterwards. Use WithErrorHandler for single routes.
+	SetErrorHandler(handler ErrorHandler)
+	// Mount serves h for all requests below prefix. The prefix is stripped from the request path.
+	// If h is an Engine, ExpandMethods, Route and URL of the mounting engine include its routes.
+	Mount(prefix string, h http.Handler)
+	// Versioned creates API versions below the group, selected by path, header or query parameter.
+	Versioned(cfg VersioningConfig) *APIVersions
 }
 
 type Router interface {
 	Use(middleware ...HandlerFunc) Router
-	Any(relativePath string, handlers ...HandlerFunc) Router
-	GET(relativePath string, handlers ...HandlerFunc) Router
-	POST(relativePath string, handlers ...HandlerFunc) Router
-	PUT(relativePath string, handlers ...HandlerFunc) Router
-	DELETE(relativePath string, handlers ...HandlerFunc) Router
-	PATCH(relativePath string, handlers ...HandlerFunc) Router
-	OPTIONS(relativePath string, handlers ...HandlerFunc) Router
-	HEAD(relativePath string, handlers ...HandlerFunc) Router
+	Any(relativePath string, handlers ...HandlerFunc) Route
+	GET(relativePath string, handlers ...HandlerFunc) Route
+	POST(relativePath string, handlers ...HandlerFunc) Route
+	PUT(relativePath string, handlers ...HandlerFunc) Route
+	DELETE(relativePath string, handlers ...HandlerFunc) Route
+	PATCH(relativePath string, handlers ...HandlerFunc) Route
+	OPTIONS(relativePath string, handlers ...HandlerFunc) Route
+	HEAD(relativePath string, handlers ...HandlerFunc) Route
+	// Static serves files from the given file system directory.
+	Static(relativePath string, root string) Route
+	// StaticFS serves files from the given file system.
+	StaticFS(relativePath string, fsys fs.FS) Route
+	// SPA serves a single page application from the given file system.
+	// Requests for unknown paths are answered with the index file.
+	SPA(relativePath string, fsys fs.FS, index string) Route
+}
+
+// Route is a route registered on a Router. Its methods configure that route.
+type Route interface {
+	Router
+	// Cache declares the cache policy of the route.
+	// Successful GET and HEAD responses get a matching Cache-Control header, unless the handler sets one.
+	Cache(maxAge time.Duration, public bool) Route
+	// Name names the route. Names must be unique within the engine.
+	Name(name string) Route
+	// Meta attaches a metadata value to the route.
+	Meta(key string, value any) Route
+	// RequireHeader requires a request header like the RequireHeader middleware and reports the requirement in the
+	// RouteInfo. Requirements are checked before the handlers of the route. It panics for static routes.
+	RequireHeader(name string, pattern *regexp.Regexp) Route
+	// Budget limits the body size and duration of requests to the route. The budget is enforced before the handlers
+	// of the route and reported in its RouteInfo. It panics for static routes.
+	Budget(budget RouteBudget) Route
+	// Canary serves percent (0-100) of the requests with handler instead of the last handler of the route.
+	// Middleware of the route runs for both variants. It panics for static routes.
+	//
+	// Experimental: this API may change.
+	Canary(handler HandlerFunc, percent float64) Route
+	// CanaryWith is like Canary, with header based selection and an observer, see CanaryConfig.
+	//
+	// Experimental: this API may change.
+	CanaryWith(handler HandlerFunc, config CanaryConfig) Route
 }
 
 func MethodNotAllowed(c Context) {
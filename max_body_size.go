@@ -0,0 +1,71 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// requestBodyKey is the context key holding the limitedBody installed by limitBody.
+const requestBodyKey = "jug.requestBody"
+
+// limitedBody is a request body limited by limitBody.
+type limitedBody struct {
+	// limited is the limiting reader set as request body.
+	limited io.ReadCloser
+	// body is the body read by limited.
+	body io.ReadCloser
+}
+
+// MaxBodySize returns a middleware that limits the size of request bodies to n bytes.
+// It overrides the engine default. Reading beyond the limit fails and binding responds with 413.
+// Bodies replaced by earlier middleware, e.g. decompressed by Transform, are limited as replaced.
+func MaxBodySize(n int64) HandlerFunc {
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		limitBody(gc, n)
+	}
+}
+
+// limitBodies applies the engine default body size limit.
+func limitBodies(config *engineConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.maxBodySize > 0 {
+			limitBody(c, config.maxBodySize)
+		}
+	}
+}
+
+// limitBody limits the current request body. If the body is still limited by an earlier limit, e.g. the engine
+// default, that limit is replaced so that routes can raise it.
+func limitBody(c *gin.Context, n int64) {
+	body := c.Request.Body
+	if v, ok := c.Get(requestBodyKey); ok {
+		if lb := v.(*limitedBody); lb.limited == body {
+			body = lb.body
+		}
+	}
+	if body == nil || body == http.NoBody {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, body, n)
+	c.Set(requestBodyKey, &limitedBody{limited: c.Request.Body, body: body})
+}
+
+// bodyTooLarge returns an error describing an exceeded body size limit.
+func bodyTooLarge(err error) (error, bool) {
+	var mbe *http.MaxBytesError
+	if !errors.As(err, &mbe) {
+		return nil, false
+	}
+	return fmt.Errorf("request body exceeds %d bytes", mbe.Limit), true
+}
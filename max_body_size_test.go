@@ -0,0 +1,62 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	e := New()
+	e.SetMaxBodySize(16)
+	handler := func(c Context) {
+		var body map[string]string
+		if c.MustBindJSON(&body) {
+			c.RespondNoContent()
+		}
+	}
+	e.POST("/small", handler)
+	e.POST("/large", MaxBodySize(1024), handler)
+
+	post := func(path string, body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+	}
+	large := `{"name":"` + strings.Repeat("x", 64) + `"}`
+	if w := post("/small", `{"a":"b"}`); w.Code != http.StatusNoContent {
+		t.Error("expected small body to pass, got", w.Code)
+	}
+	w := post("/small", large)
+	if w.Code != http.StatusRequestEntityTooLarge || w.Body.String() != `{"error":"request body exceeds 16 bytes"}` {
+		t.Error("expected 413, got", w.Code, w.Body.String())
+	}
+	if w := post("/large", large); w.Code != http.StatusNoContent {
+		t.Error("expected route limit to override the default, got", w.Code)
+	}
+}
+
+func TestMaxBodySize_ReplacedBodies(t *testing.T) {
+	e := New()
+	e.POST("/idempotent", Idempotency(IdempotencyConfig{}), MaxBodySize(8), echoBody)
+	e.POST("/echo", Transform(TransformConfig{DecompressRequests: true, MaxDecompressedSize: 1024}), MaxBodySize(8), echoBody)
+
+	r := httptest.NewRequest(http.MethodPost, "/idempotent", strings.NewReader("hello"))
+	r.Header.Set("Idempotency-Key", "k1")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Body.String() != "|hello" {
+		t.Error("expected the body read by Idempotency to reach the handler, got", w.Code, w.Body.String())
+	}
+	w = serveTransform(e, gzipped("hello"), http.Header{"Content-Encoding": {"gzip"}})
+	if w.Code != http.StatusOK || w.Body.String() != "|hello" {
+		t.Error("expected the decompressed body to reach the handler, got", w.Code, w.Body.String())
+	}
+	w = serveTransform(e, gzipped("hello world"), http.Header{"Content-Encoding": {"gzip"}})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected the decompressed body to be limited, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// Counters emitted by the engine.
+const (
+	// MetricBindingFailures counts request values that could not be bound. Labels: method, route, field.
+	MetricBindingFailures = "jug_binding_failures_total"
+	// MetricValidationFailures counts failed validation rules. Labels: method, route, field, code.
+	MetricValidationFailures = "jug_validation_failures_total"
+	// MetricCircuitBreakerTransitions counts state changes of circuit breakers. Labels: method, route, state.
+	MetricCircuitBreakerTransitions = "jug_circuit_breaker_transitions_total"
+	// MetricCircuitBreakerRejections counts requests rejected by open circuit breakers. Labels: method, route.
+	MetricCircuitBreakerRejections = "jug_circuit_breaker_rejections_total"
+)
+
+// Metrics receives counters emitted by the engine, e.g. to forward them to Prometheus.
+type Metrics interface {
+	// IncCounter increments the counter with the given name and labels.
+	IncCounter(name string, labels map[string]string)
+}
+
+// MetricsFunc is an adapter to allow the use of ordinary functions as Metrics.
+type MetricsFunc func(name string, labels map[string]string)
+
+func (f MetricsFunc) IncCounter(name string, labels map[string]string) {
+	f(name, labels)
+}
+
+// bindingFailed records a binding failure and responds with 400, or 413 if the body is too large.
+// Configuration errors are passed to the error handler.
+func (w *contextWrapper) bindingFailed(err error) {
+	if tooLarge, ok := bodyTooLarge(err); ok {
+		w.respondE(http.StatusRequestEntityTooLarge, tooLarge)
+		return
+	}
+	if isConfigurationError(err) {
+		w.HandleError(err)
+		return
+	}
+	if m := w.config.metrics; m != nil {
+		var be *BindingError
+		var te *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &be):
+			for _, f := range be.Fields {
+				m.IncCounter(MetricBindingFailures, w.metricLabels(f.Field, ""))
+			}
+		case errors.As(err, &te):
+			m.IncCounter(MetricBindingFailures, w.metricLabels(te.Field, ""))
+		default:
+			m.IncCounter(MetricBindingFailures, w.metricLabels("", ""))
+		}
+	}
+	w.RespondBadRequestE(err)
+}
+
+// validationFailed records a validation failure and responds with 400.
+// Field and code labels are only known for errors returned by a Validator. Configuration errors are passed to the error handler.
+func (w *contextWrapper) validationFailed(err error) {
+	if isConfigurationError(err) {
+		w.HandleError(err)
+		return
+	}
+	if m := w.config.metrics; m != nil {
+		var ve *ValidationError
+		var ue *unstructuredValidationError
+		var failed []FieldError
+		if errors.As(err, &ve) {
+			failed = ve.Errors
+		} else if errors.As(err, &ue) {
+			failed = ue.errors
+		}
+		for _, fe := range failed {
+			m.IncCounter(MetricValidationFailures, w.metricLabels(fe.Field, fe.Code))
+		}
+		if len(failed) == 0 {
+			m.IncCounter(MetricValidationFailures, w.metricLabels("", ""))
+		}
+	}
+	w.RespondBadRequestE(err)
+}
+
+func (w *contextWrapper) metricLabels(field string, code string) map[string]string {
+	labels := map[string]string{
+		"method": w.c.Request.Method,
+		"route":  w.c.FullPath(),
+		"field":  field,
+	}
+	if len(code) > 0 {
+		labels["code"] = code
+	}
+	return labels
+}
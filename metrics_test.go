@@ -0,0 +1,42 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type metricsTestBody struct {
+	Name  string `json:"name" validate:"required"`
+	Count int    `json:"count"`
+}
+
+func TestMetrics_BindingAndValidationFailures(t *testing.T) {
+	counters := make(map[string]int)
+	e := New()
+	e.SetMetrics(MetricsFunc(func(name string, labels map[string]string) {
+		counters[name+" "+labels["route"]+" "+labels["field"]+" "+labels["code"]]++
+	}))
+	e.POST("/items/:id", func(c Context) {
+		var body metricsTestBody
+		c.MustBindJSON(&body)
+	})
+
+	for _, body := range []string{`{"count":"ten"}`, `{"count":1}`} {
+		w := serveRequest(e, httptest.NewRequest(http.MethodPost, "/items/1", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Fatal("expected bad request, got", w.Code)
+		}
+	}
+	if counters[MetricBindingFailures+" /items/:id count "] != 1 {
+		t.Error("expected a binding failure for count, got", counters)
+	}
+	if counters[MetricValidationFailures+" /items/:id name required"] != 1 {
+		t.Error("expected a validation failure, got", counters)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// engineMount is an engine mounted below a prefix of another engine.
+type engineMount struct {
+	prefix string
+	engine *ginEngine
+}
+
+func mountPattern(prefix string) string {
+	return joinPaths(prefix, "/*path")
+}
+
+// mount serves h for all methods below prefix. The prefix is stripped from the request path.
+// Mounted engines are recorded, so that ExpandMethods and route lookups include their routes.
+func (cfg *engineConfig) mount(routes gin.IRoutes, basePath string, prefix string, h http.Handler) {
+	absolutePrefix := strings.TrimSuffix(joinPaths(basePath, prefix), "/")
+	routes.Any(mountPattern(prefix), gin.WrapH(http.StripPrefix(absolutePrefix, h)))
+	cfg.newRoute(basePath, mountPattern(prefix), registryMethods...)
+	if sub, ok := h.(*ginEngine); ok {
+		cfg.mounts = append(cfg.mounts, engineMount{prefix: absolutePrefix, engine: sub})
+	}
+}
+
+func (cfg *engineConfig) expandMounts() {
+	for _, m := range cfg.mounts {
+		m.engine.ExpandMethods()
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRouterGroup_Mount_Handler(t *testing.T) {
+	e := New()
+	e.Group("/api").Mount("/legacy", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path))
+	}))
+
+	w := serve(e, http.MethodPost, "/api/legacy/users/1")
+	if w.Code != http.StatusOK || w.Body.String() != "POST /users/1" {
+		t.Fatal("expected mounted handler with stripped path, got", w.Code, w.Body.String())
+	}
+}
+
+func TestEngine_Mount_Engine(t *testing.T) {
+	admin := New()
+	admin.GET("/users/:id", func(c Context) {
+		c.RespondOkData("text/plain", []byte("user "+c.Param("id")))
+	}).Name("admin.users.show")
+
+	e := New()
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+	e.Mount("/admin", admin)
+	e.ExpandMethods()
+
+	if w := serve(e, http.MethodGet, "/admin/users/1"); w.Code != http.StatusOK || w.Body.String() != "user 1" {
+		t.Fatal("expected mounted engine response, got", w.Code, w.Body.String())
+	}
+	if w := serve(e, http.MethodDelete, "/admin/users/1"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected mounted engine to expand methods, got", w.Code)
+	}
+	if w := serve(e, http.MethodDelete, "/"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected engine to expand methods, got", w.Code)
+	}
+	if route, ok := e.Route("admin.users.show"); !ok || route.Path != "/admin/users/:id" {
+		t.Fatal("expected mounted route, got", route, ok)
+	}
+	if url, err := e.URL("admin.users.show", "7"); err != nil || url != "/admin/users/7" {
+		t.Fatal("expected mounted route url, got", url, err)
+	}
+}
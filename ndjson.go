@@ -0,0 +1,114 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NDJSONContentType is the content type of newline delimited JSON.
+const NDJSONContentType = "application/x-ndjson"
+
+// Decoder decodes a single value of a request body, see Context.BindNDJSONEach.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// NDJSONLineError reports a line of a newline delimited JSON body that could not be decoded.
+type NDJSONLineError struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+	Err  error
+}
+
+func (e *NDJSONLineError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err)
+}
+
+func (e *NDJSONLineError) Unwrap() error {
+	return e.Err
+}
+
+// ndjsonLine decodes a single line. Decoded values are validated.
+type ndjsonLine struct {
+	number int
+	data   []byte
+	codec  JSONCodec
+}
+
+func (l *ndjsonLine) Decode(v any) error {
+	if err := l.codec.Unmarshal(l.data, v); err != nil {
+		return &NDJSONLineError{Line: l.number, Err: err}
+	}
+	if err := validate(v); err != nil {
+		return &recordValidationError{err: lineValidationError(l.number, err)}
+	}
+	return nil
+}
+
+// recordValidationError marks validation failures of a record of a NDJSON or CSV body.
+// They are answered like validation failures of other bodies.
+type recordValidationError struct {
+	err error
+}
+
+func (e *recordValidationError) Error() string {
+	return e.err.Error()
+}
+
+func (e *recordValidationError) Unwrap() error {
+	return e.err
+}
+
+// lineValidationError prefixes the fields of a ValidationError with the line number, e.g. [3].name.
+func lineValidationError(line int, err error) error {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return &NDJSONLineError{Line: line, Err: err}
+	}
+	prefixed := &ValidationError{Errors: make([]FieldError, 0, len(ve.Errors))}
+	for _, fe := range ve.Errors {
+		field := fmt.Sprintf("[%d]", line)
+		if len(fe.Field) > 0 {
+			field += "." + fe.Field
+		}
+		other := fe.Other
+		if len(other) > 0 {
+			other = fmt.Sprintf("[%d].%s", line, other)
+		}
+		prefixed.Errors = append(prefixed.Errors, FieldError{
+			Field:   field,
+			Other:   other,
+			Code:    fe.Code,
+			Message: fmt.Sprintf("line %d: %s", line, fe.Message),
+		})
+	}
+	return prefixed
+}
+
+// eachNDJSONLine calls fn for each non-empty line of r.
+func eachNDJSONLine(r io.Reader, codec JSONCodec, fn func(dec Decoder) error) error {
+	reader := bufio.NewReader(r)
+	number := 0
+	for {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		number++
+		if line := bytes.TrimSpace(data); len(line) > 0 {
+			if fnErr := fn(&ndjsonLine{number: number, data: line, codec: codec}); fnErr != nil {
+				return fnErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
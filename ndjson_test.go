@@ -0,0 +1,116 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type ndjsonItem struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func serveNDJSON(e Engine, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(body))
+	r.Header.Set("Content-Type", NDJSONContentType)
+	return serveRequest(e, r)
+}
+
+func TestContext_BindNDJSONEach(t *testing.T) {
+	var names []string
+	e := New()
+	e.POST("/import", func(c Context) {
+		names = nil
+		ok := c.BindNDJSONEach(func(dec Decoder) error {
+			var item ndjsonItem
+			if err := dec.Decode(&item); err != nil {
+				return err
+			}
+			if item.Name == "conflict" {
+				return NewConflictError("duplicate item")
+			}
+			names = append(names, item.Name)
+			return nil
+		})
+		if ok {
+			c.RespondNoContent()
+		}
+	})
+
+	w := serveNDJSON(e, "{\"name\":\"a\"}\n\n{\"name\":\"b\"}\r\n{\"name\":\"c\"}")
+	if w.Code != http.StatusNoContent || strings.Join(names, ",") != "a,b,c" {
+		t.Fatal("expected all lines to be processed, got", w.Code, names)
+	}
+
+	w = serveNDJSON(e, "{\"name\":\"a\"}\n{\"name\":")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "line 2: ") {
+		t.Fatal("expected binding error for line 2, got", w.Code, w.Body.String())
+	}
+
+	w = serveNDJSON(e, "{\"name\":\"a\"}\n{\"name\":\"\"}\n")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"line 2: name is required"}` {
+		t.Fatal("expected validation error for line 2, got", w.Code, w.Body.String())
+	}
+
+	w = serveNDJSON(e, "{\"name\":\"conflict\"}\n")
+	if w.Code != http.StatusConflict {
+		t.Fatal("expected errors of fn to be handled, got", w.Code)
+	}
+}
+
+type structuredNDJSONItem struct {
+	Name string `json:"name"`
+}
+
+func (i structuredNDJSONItem) Validate() error {
+	return NewValidator().Structured().Field("name").RequireStringNotEmpty(i.Name, "name is required").Validate()
+}
+
+func TestContext_BindNDJSONEach_StructuredValidation(t *testing.T) {
+	e := New()
+	e.POST("/import", func(c Context) {
+		c.BindNDJSONEach(func(dec Decoder) error {
+			var item structuredNDJSONItem
+			return dec.Decode(&item)
+		})
+	})
+	w := serveNDJSON(e, "{\"name\":\"a\"}\n{\"name\":\"\"}\n")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `[{"field":"[2].name","code":"required","message":"line 2: name is required"}]` {
+		t.Fatal("expected field errors prefixed with the line, got", w.Code, w.Body.String())
+	}
+}
+
+func TestNDJSONLineError(t *testing.T) {
+	cause := errors.New("unexpected end of JSON input")
+	err := &NDJSONLineError{Line: 4, Err: cause}
+	if err.Error() != "line 4: unexpected end of JSON input" || !errors.Is(err, cause) {
+		t.Fatal("unexpected error", err)
+	}
+}
+
+func TestContext_RespondNDJSON(t *testing.T) {
+	e := New()
+	e.GET("/export", func(c Context) {
+		items := make(chan any)
+		go func() {
+			defer close(items)
+			items <- ndjsonItem{Name: "a"}
+			items <- ndjsonItem{Name: "b"}
+		}()
+		_ = c.RespondNDJSON(items)
+	})
+
+	w := serve(e, http.MethodGet, "/export")
+	if w.Code != http.StatusOK || w.Body.String() != "{\"name\":\"a\"}\n{\"name\":\"b\"}\n" {
+		t.Fatal("expected newline delimited JSON, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != NDJSONContentType {
+		t.Fatal("unexpected content type", ct)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option configures the HTTP server of an engine.
+type Option func(cfg *serverConfig)
+
+type serverConfig struct {
+	readTimeout       time.Duration
+	readHeaderTimeout time.Duration
+	writeTimeout      time.Duration
+	idleTimeout       time.Duration
+	maxHeaderBytes    int
+	errorLog          *log.Logger
+	baseContext       func(net.Listener) context.Context
+}
+
+// ServerReadTimeout limits the time to read a request including the body.
+func ServerReadTimeout(d time.Duration) Option {
+	return func(cfg *serverConfig) {
+		cfg.readTimeout = d
+	}
+}
+
+// ServerReadHeaderTimeout limits the time to read the request headers. Defaults to the read timeout.
+func ServerReadHeaderTimeout(d time.Duration) Option {
+	return func(cfg *serverConfig) {
+		cfg.readHeaderTimeout = d
+	}
+}
+
+// ServerWriteTimeout limits the time from the end of reading the request headers to the end of writing the response.
+// It also ends event streams and WebSocket connections, so leave it zero when serving them.
+func ServerWriteTimeout(d time.Duration) Option {
+	return func(cfg *serverConfig) {
+		cfg.writeTimeout = d
+	}
+}
+
+// ServerIdleTimeout limits the time a keep-alive connection waits for the next request. Defaults to the read timeout.
+func ServerIdleTimeout(d time.Duration) Option {
+	return func(cfg *serverConfig) {
+		cfg.idleTimeout = d
+	}
+}
+
+// ServerMaxHeaderBytes limits the size of the request headers. Defaults to http.DefaultMaxHeaderBytes.
+func ServerMaxHeaderBytes(n int) Option {
+	return func(cfg *serverConfig) {
+		cfg.maxHeaderBytes = n
+	}
+}
+
+// ServerErrorLog sets the logger for errors accepting connections and unexpected behavior of handlers.
+// Defaults to the standard logger.
+func ServerErrorLog(logger *log.Logger) Option {
+	return func(cfg *serverConfig) {
+		cfg.errorLog = logger
+	}
+}
+
+// ServerBaseContext sets the function creating the base context of the requests of a listener.
+// Values of the context are available through the Context of the handlers.
+func ServerBaseContext(f func(l net.Listener) context.Context) Option {
+	return func(cfg *serverConfig) {
+		cfg.baseContext = f
+	}
+}
+
+func (cfg serverConfig) newServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.readTimeout,
+		ReadHeaderTimeout: cfg.readHeaderTimeout,
+		WriteTimeout:      cfg.writeTimeout,
+		IdleTimeout:       cfg.idleTimeout,
+		MaxHeaderBytes:    cfg.maxHeaderBytes,
+		ErrorLog:          cfg.errorLog,
+		BaseContext:       cfg.baseContext,
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationDefaults configures Context.Pagination.
+type PaginationDefaults struct {
+	// Size is the page size used if the request does not specify one. Defaults to 20.
+	Size int
+	// MaxSize caps the requested page size. Defaults to 100.
+	MaxSize int
+}
+
+// Page is a requested page.
+type Page struct {
+	// Offset is the number of items to skip.
+	Offset int
+	// Limit is the number of items on the page.
+	Limit int
+}
+
+// Number returns the 1-based page number.
+func (p Page) Number() int {
+	if p.Limit <= 0 {
+		return 1
+	}
+	return p.Offset/p.Limit + 1
+}
+
+// PageResponse is the envelope written by Context.RespondPage.
+type PageResponse struct {
+	Items  any   `json:"items"`
+	Total  int64 `json:"total"`
+	Page   int   `json:"page"`
+	Size   int   `json:"size"`
+	Offset int   `json:"offset"`
+}
+
+func (d PaginationDefaults) withDefaults() PaginationDefaults {
+	if d.MaxSize <= 0 {
+		d.MaxSize = 100
+	}
+	if d.Size <= 0 {
+		d.Size = 20
+	}
+	if d.Size > d.MaxSize {
+		d.Size = d.MaxSize
+	}
+	return d
+}
+
+// usesOffset reports whether the request paginates by limit and offset instead of page and size.
+func usesOffset(query url.Values) bool {
+	return query.Has("limit") || query.Has("offset")
+}
+
+func parsePage(query url.Values, defaults PaginationDefaults) (Page, string, error) {
+	defaults = defaults.withDefaults()
+	sizeKey, posKey := "size", "page"
+	if usesOffset(query) {
+		sizeKey, posKey = "limit", "offset"
+	}
+	size, err := parsePaginationValue(query, sizeKey, defaults.Size, 1)
+	if err != nil {
+		return Page{}, sizeKey, err
+	}
+	if size > defaults.MaxSize {
+		size = defaults.MaxSize
+	}
+	if posKey == "offset" {
+		offset, err := parsePaginationValue(query, posKey, 0, 0)
+		return Page{Offset: offset, Limit: size}, posKey, err
+	}
+	number, err := parsePaginationValue(query, posKey, 1, 1)
+	return Page{Offset: (number - 1) * size, Limit: size}, posKey, err
+}
+
+func parsePaginationValue(query url.Values, key string, defaultValue int, min int) (int, error) {
+	val := query.Get(key)
+	if len(val) == 0 {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		return defaultValue, err
+	}
+	if i < min {
+		return defaultValue, fmt.Errorf("%s must be at least %d", key, min)
+	}
+	return i, nil
+}
+
+// pageLinks returns the Link header value for the pages around page, keeping the pagination style of the request.
+func pageLinks(u *url.URL, page Page, total int64) string {
+	if page.Limit <= 0 {
+		return ""
+	}
+	last := 0
+	if total > 0 {
+		last = int((total - 1) / int64(page.Limit))
+	}
+	current := page.Offset / page.Limit
+	query := u.Query()
+	offsetStyle := usesOffset(query)
+	link := func(index int, offset int, rel string) string {
+		if offsetStyle {
+			query.Set("offset", strconv.Itoa(offset))
+			query.Set("limit", strconv.Itoa(page.Limit))
+		} else {
+			query.Set("page", strconv.Itoa(index+1))
+			query.Set("size", strconv.Itoa(page.Limit))
+		}
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, u.Path, query.Encode(), rel)
+	}
+	links := []string{link(0, 0, "first")}
+	if page.Offset > 0 {
+		prev := page.Offset - page.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, link(current-1, prev, "prev"))
+	}
+	if int64(page.Offset+page.Limit) < total {
+		links = append(links, link(current+1, page.Offset+page.Limit, "next"))
+	}
+	links = append(links, link(last, last*page.Limit, "last"))
+	return strings.Join(links, ", ")
+}
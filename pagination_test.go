@@ -0,0 +1,78 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParsePage(t *testing.T) {
+	defaults := PaginationDefaults{Size: 10, MaxSize: 50}
+	tests := []struct {
+		query    string
+		expected Page
+		err      bool
+	}{
+		{"", Page{Offset: 0, Limit: 10}, false},
+		{"page=3", Page{Offset: 20, Limit: 10}, false},
+		{"page=2&size=25", Page{Offset: 25, Limit: 25}, false},
+		{"size=500", Page{Offset: 0, Limit: 50}, false},
+		{"offset=15&limit=5", Page{Offset: 15, Limit: 5}, false},
+		{"offset=15", Page{Offset: 15, Limit: 10}, false},
+		{"page=0", Page{}, true},
+		{"size=abc", Page{}, true},
+		{"offset=-1", Page{}, true},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		page, _, err := parsePage(query, defaults)
+		if (err != nil) != tt.err {
+			t.Fatalf("parsePage(%s) error = %v, expected error %v", tt.query, err, tt.err)
+		}
+		if !tt.err && page != tt.expected {
+			t.Fatalf("parsePage(%s) = %v, expected %v", tt.query, page, tt.expected)
+		}
+	}
+}
+
+func TestContext_RespondPage(t *testing.T) {
+	e := New()
+	e.GET("/users", func(c Context) {
+		page, err := c.Pagination(PaginationDefaults{})
+		if err != nil {
+			c.HandleError(err)
+			return
+		}
+		c.RespondPage([]string{"c", "d"}, 5, page)
+	})
+
+	w := serve(e, http.MethodGet, "/users?page=2&size=2&sort=name")
+	if w.Code != http.StatusOK {
+		t.Fatal("expected 200, got", w.Code)
+	}
+	if w.Body.String() != `{"items":["c","d"],"total":5,"page":2,"size":2,"offset":2}` {
+		t.Fatal("unexpected body", w.Body.String())
+	}
+	if h := w.Header().Get("X-Total-Count"); h != "5" {
+		t.Fatal("expected total count header, got", h)
+	}
+	expected := `</users?page=1&size=2&sort=name>; rel="first", </users?page=1&size=2&sort=name>; rel="prev", ` +
+		`</users?page=3&size=2&sort=name>; rel="next", </users?page=3&size=2&sort=name>; rel="last"`
+	if h := w.Header().Get("Link"); h != expected {
+		t.Fatalf("expected Link %s, got %s", expected, h)
+	}
+
+	w = serve(e, http.MethodGet, "/users?offset=4&limit=2")
+	expected = `</users?limit=2&offset=0>; rel="first", </users?limit=2&offset=2>; rel="prev", </users?limit=2&offset=4>; rel="last"`
+	if h := w.Header().Get("Link"); h != expected {
+		t.Fatalf("expected Link %s, got %s", expected, h)
+	}
+
+	if w := serve(e, http.MethodGet, "/users?page=x"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected 400, got", w.Code)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestContext_TypedParams(t *testing.T) {
+	e := New()
+	e.GET("/users/:id/files/:file", func(c Context) {
+		if v, err := c.IntParam("id"); err != nil || v != 42 {
+			t.Error("IntParam() =", v, err)
+		}
+		if v, err := c.Int64Param("id"); err != nil || v != 42 {
+			t.Error("Int64Param() =", v, err)
+		}
+		if v, err := c.UUIDParam("file"); err != nil || v != "0b3e9c1c-8f5a-4b0e-9d6a-2a1f3c4d5e6f" {
+			t.Error("UUIDParam() =", v, err)
+		}
+		if _, err := c.UUIDParam("id"); err == nil {
+			t.Error("UUIDParam() should fail for 42")
+		}
+		c.RespondNoContent()
+	})
+	if w := serve(e, http.MethodGet, "/users/42/files/0B3E9C1C-8F5A-4B0E-9D6A-2A1F3C4D5E6F"); w.Code != http.StatusNoContent {
+		t.Fatal("expected 204, got", w.Code)
+	}
+}
+
+func TestContext_MustIntParam(t *testing.T) {
+	e := New()
+	e.GET("/users/:id", func(c Context) {
+		id, ok := c.MustIntParam("id")
+		if !ok {
+			return
+		}
+		c.RespondOk(id)
+	})
+	if w := serve(e, http.MethodGet, "/users/42"); w.Code != http.StatusOK || w.Body.String() != "42" {
+		t.Fatal("expected 42, got", w.Code, w.Body.String())
+	}
+	w := serve(e, http.MethodGet, "/users/abc")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"invalid value for path parameter id"}` {
+		t.Fatal("expected 400, got", w.Code, w.Body.String())
+	}
+}
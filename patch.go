@@ -0,0 +1,168 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Patch is a partial update of a T, bound from a JSON body with MustBindJSON using the JSONCodec of the engine. It records which fields were present,
+// so handlers can tell a field set to its zero value or null from a field that was not provided.
+// T must be a struct. Fields are identified by their JSON name, nested fields by a dotted path, e.g. address.city.
+//
+// Only present fields are validated with their `validate` struct tags. The Validate method of T is not called,
+// validate the target after Apply instead.
+type Patch[T any] struct {
+	value  T
+	fields patchFields
+}
+
+// patchFields holds the present fields by JSON name. Fields given as a JSON object hold their present fields.
+type patchFields map[string]patchFields
+
+// codecUnmarshaler is implemented by types bound with the JSONCodec of the engine instead of their UnmarshalJSON method.
+type codecUnmarshaler interface {
+	unmarshalJSONWith(codec JSONCodec, data []byte) error
+}
+
+func (p *Patch[T]) UnmarshalJSON(data []byte) error {
+	return p.unmarshalJSONWith(StdJSONCodec, data)
+}
+
+func (p *Patch[T]) unmarshalJSONWith(codec JSONCodec, data []byte) error {
+	t := reflect.TypeOf(p.value)
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("jug: Patch requires a struct type, got %v", t)
+	}
+	if err := codec.Unmarshal(data, &p.value); err != nil {
+		return err
+	}
+	fields, ok := parsePatchFields(codec, data, t)
+	if !ok {
+		return errors.New("patch must be a JSON object")
+	}
+	p.fields = fields
+	return nil
+}
+
+// parsePatchFields records the keys of a JSON object matching fields of t. It returns false if data is no object.
+func parsePatchFields(codec JSONCodec, data []byte, t reflect.Type) (patchFields, bool) {
+	var present map[string]json.RawMessage
+	if err := codec.Unmarshal(data, &present); err != nil || present == nil {
+		return nil, false
+	}
+	types := jsonFieldTypes(t)
+	fields := make(patchFields, len(present))
+	for key, raw := range present {
+		name, ft, ok := lookupJSONField(types, key)
+		if !ok {
+			continue
+		}
+		var nested patchFields
+		if st := mergeableStructType(ft); st != nil && bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+			nested, _ = parsePatchFields(codec, raw, st)
+		}
+		fields[name] = nested
+	}
+	return fields, true
+}
+
+// mergeableStructType returns the struct type of a struct or struct pointer field patched field by field, or nil.
+func mergeableStructType(t reflect.Type) reflect.Type {
+	st := nestedStructType(t)
+	if st == nil || reflect.PointerTo(st).Implements(jsonUnmarshalerType) {
+		return nil
+	}
+	return st
+}
+
+// Value returns the bound value. Fields that were not present have their zero value.
+func (p *Patch[T]) Value() T {
+	return p.value
+}
+
+// Present reports whether a field was present, e.g. Present("name") or Present("address.city").
+// Fields of arrays are present if the array is, e.g. Present("items[0].name").
+func (p *Patch[T]) Present(path string) bool {
+	fields := p.fields
+	segments := strings.Split(path, ".")
+	for i, segment := range segments {
+		name, _, indexed := strings.Cut(segment, "[")
+		nested, ok := fields[name]
+		if !ok {
+			return false
+		}
+		if nested == nil && i < len(segments)-1 {
+			// arrays are replaced as a whole, other values have no fields
+			return indexed
+		}
+		fields = nested
+	}
+	return true
+}
+
+// Apply sets the present fields of target to the bound values. Fields given as a JSON object are applied field by
+// field, other values, including arrays and null, replace the target value.
+func (p *Patch[T]) Apply(target *T) {
+	applyPatch(reflect.ValueOf(target).Elem(), reflect.ValueOf(&p.value).Elem(), p.fields)
+}
+
+func applyPatch(dst reflect.Value, src reflect.Value, fields patchFields) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && len(tag) == 0 && f.Type.Kind() == reflect.Struct {
+			applyPatch(dst.Field(i), src.Field(i), fields)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		nested, ok := fields[jsonFieldName(f)]
+		if !ok {
+			continue
+		}
+		df, sf := dst.Field(i), src.Field(i)
+		if nested == nil || mergeableStructType(f.Type) == nil {
+			df.Set(sf)
+			continue
+		}
+		if f.Type.Kind() == reflect.Pointer {
+			if df.IsNil() {
+				df.Set(reflect.New(f.Type.Elem()))
+			}
+			df, sf = df.Elem(), sf.Elem()
+		}
+		applyPatch(df, sf, nested)
+	}
+}
+
+// Validate validates the present fields using the `validate` struct tags of T.
+func (p *Patch[T]) Validate() error {
+	err := NewValidator().Structured().Struct(&p.value).Validate()
+	var e *ValidationError
+	if !errors.As(err, &e) {
+		return err
+	}
+	present := make([]FieldError, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		if len(fe.Field) == 0 || p.Present(fe.Field) {
+			present = append(present, fe)
+		}
+	}
+	if len(present) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: present}
+}
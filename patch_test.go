@@ -0,0 +1,131 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type patchTestAddress struct {
+	City   string `json:"city" validate:"required"`
+	Street string `json:"street"`
+}
+
+type patchTestUser struct {
+	Name     string            `json:"name" validate:"required,max=10"`
+	Nickname *string           `json:"nickname"`
+	Age      int               `json:"age"`
+	Tags     []string          `json:"tags"`
+	Address  *patchTestAddress `json:"address"`
+	Home     patchTestAddress  `json:"home"`
+}
+
+func TestPatch_PresentAndApply(t *testing.T) {
+	nickname := "jd"
+	user := patchTestUser{
+		Name:     "Jane",
+		Nickname: &nickname,
+		Age:      30,
+		Tags:     []string{"a", "b"},
+		Address:  &patchTestAddress{City: "Vienna", Street: "Ring"},
+		Home:     patchTestAddress{City: "Graz", Street: "Hauptplatz"},
+	}
+	var p Patch[patchTestUser]
+	data := `{"nickname":null,"Age":0,"tags":["c"],"address":{"street":"Gürtel"},"home":{"city":"Linz"}}`
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	for path, expected := range map[string]bool{
+		"name":           false,
+		"nickname":       true,
+		"age":            true,
+		"tags[0]":        true,
+		"address":        true,
+		"address.street": true,
+		"address.city":   false,
+		"nickname.x":     false,
+	} {
+		if p.Present(path) != expected {
+			t.Errorf("expected Present(%q) to be %v", path, expected)
+		}
+	}
+
+	p.Apply(&user)
+	if user.Name != "Jane" || user.Nickname != nil || user.Age != 0 || len(user.Tags) != 1 || user.Tags[0] != "c" {
+		t.Errorf("expected present fields to be applied, got %+v", user)
+	}
+	if user.Address.City != "Vienna" || user.Address.Street != "Gürtel" {
+		t.Errorf("expected nested pointer to be merged, got %+v", user.Address)
+	}
+	if user.Home.City != "Linz" || user.Home.Street != "Hauptplatz" {
+		t.Errorf("expected nested struct to be merged, got %+v", user.Home)
+	}
+}
+
+func TestPatch_Binding(t *testing.T) {
+	e := New()
+	e.PATCH("/users", func(c Context) {
+		var p Patch[patchTestUser]
+		if !c.MustBindJSON(&p) {
+			return
+		}
+		user := patchTestUser{Name: "Jane"}
+		p.Apply(&user)
+		c.RespondOk(user)
+	})
+	patch := func(body string) *httptest.ResponseRecorder {
+		return serveRequest(e, httptest.NewRequest(http.MethodPatch, "/users", strings.NewReader(body)))
+	}
+
+	w := patch(`{"age":31,"address":{"street":"Ring"}}`)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Jane","nickname":null,"age":31`) {
+		t.Error("expected absent required fields to be accepted, got", w.Code, w.Body.String())
+	}
+	w = patch(`{"name":""}`)
+	if w.Code != http.StatusBadRequest || w.Body.String() != `[{"field":"name","code":"required","message":"name is required"}]` {
+		t.Error("expected present fields to be validated, got", w.Code, w.Body.String())
+	}
+	w = patch(`[1]`)
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected non object patches to be rejected, got", w.Code)
+	}
+}
+
+func TestPatch_Validate(t *testing.T) {
+	var p Patch[patchTestUser]
+	if err := json.Unmarshal([]byte(`{"name":"","address":{"city":""},"home":{"street":"Ring"}}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	var e *ValidationError
+	if err := p.Validate(); !errors.As(err, &e) || len(e.Errors) != 2 || e.Errors[0].Field != "name" || e.Errors[1].Field != "address.city" {
+		t.Error("expected validation errors of present fields, got", err)
+	}
+}
+
+func TestPatch_JSONCodec(t *testing.T) {
+	codec := &countingCodec{}
+	e := New()
+	e.SetJSONCodec(codec)
+	e.PATCH("/users", func(c Context) {
+		var p Patch[patchTestUser]
+		if !c.MustBindJSON(&p) {
+			return
+		}
+		c.RespondNoContent()
+	})
+
+	w := serveRequest(e, httptest.NewRequest(http.MethodPatch, "/users", strings.NewReader(`{"age":31}`)))
+	if w.Code != http.StatusNoContent {
+		t.Fatal("expected patch to be bound, got", w.Code, w.Body.String())
+	}
+	if codec.unmarshals == 0 {
+		t.Error("expected patch to be bound with the codec of the engine")
+	}
+}
@@ -4,7 +4,16 @@
 
 package jug
 
+import (
+	"fmt"
+	"sort"
+)
+
+// registryMethods are the methods covered by ExpandMethods.
+var registryMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS", "HEAD"}
+
 type PathRegistry struct {
+	// paths maps paths to methods. Methods registered by ExpandMethods map to false.
 	paths map[string]map[string]bool
 }
 
@@ -25,6 +34,26 @@ func (p *PathRegistry) Add(path string, methods ...string) {
 	}
 }
 
+// addExpanded records a method registered by ExpandMethods.
+func (p *PathRegistry) addExpanded(path string, method string) {
+	p.Add(path)
+	p.paths[path][method] = false
+}
+
+// checkDuplicates panics if one of the methods is already registered for a path.
+func (p *PathRegistry) checkDuplicates(absolutePath string, relativePath string, methods []string) {
+	for _, m := range methods {
+		handled, ok := p.paths[relativePath][m]
+		if !ok {
+			continue
+		}
+		if !handled {
+			panic(fmt.Sprintf("jug: %s %s is already registered by ExpandMethods, register routes before calling ExpandMethods", m, absolutePath))
+		}
+		panic(fmt.Sprintf("jug: %s %s is already registered", m, absolutePath))
+	}
+}
+
 func (p *PathRegistry) Get(path string, method string) bool {
 	e, ok := p.paths[path]
 	if !ok {
@@ -41,3 +70,15 @@ func (p *PathRegistry) Paths() []string {
 	}
 	return paths
 }
+
+// Methods returns the methods handled for a path in sorted order. Methods only registered by ExpandMethods are omitted.
+func (p *PathRegistry) Methods(path string) []string {
+	methods := make([]string, 0)
+	for m, handled := range p.paths[path] {
+		if handled {
+			methods = append(methods, m)
+		}
+	}
+	sort.Strings(methods)
+	return methods
+}
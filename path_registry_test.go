@@ -0,0 +1,110 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestEngine_ExpandMethods_Groups(t *testing.T) {
+	e := New()
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	api := e.Group("/api")
+	api.GET("/users", noop)
+	api.Group("/v1").POST("/items", noop)
+	e.ExpandMethods()
+
+	if w := serve(e, http.MethodGet, "/api/users"); w.Code != http.StatusNoContent {
+		t.Fatal("expected handler, got", w.Code)
+	}
+	if w := serve(e, http.MethodDelete, "/api/users"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 in group, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/api/v1/items"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 in nested group, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/users"); w.Code != http.StatusNotFound {
+		t.Fatal("expected group paths not to be expanded on the engine, got", w.Code)
+	}
+}
+
+func TestEngine_ExpandMethods_Allow(t *testing.T) {
+	e := New()
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	e.GET("/users", noop)
+	e.POST("/users", noop)
+	e.Group("/api").DELETE("/items/:id", noop)
+	e.ExpandMethods()
+
+	w := serve(e, http.MethodPut, "/users")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, POST, OPTIONS" {
+		t.Fatal("expected 405 with Allow header, got", w.Code, w.Header().Get("Allow"))
+	}
+	w = serve(e, http.MethodOptions, "/users")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, POST, OPTIONS" {
+		t.Fatal("expected OPTIONS to be answered, got", w.Code, w.Header().Get("Allow"))
+	}
+	w = serve(e, http.MethodOptions, "/api/items/1")
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "DELETE, OPTIONS" {
+		t.Fatal("expected OPTIONS in group to be answered, got", w.Code, w.Header().Get("Allow"))
+	}
+}
+
+func TestEngine_ExpandMethods_UseRouters(t *testing.T) {
+	e := New()
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	e.Use(noop).GET("/users", noop).POST("/users", noop)
+	e.Group("/api").Use(noop).PUT("/items", noop)
+	e.ExpandMethods()
+	e.ExpandMethods()
+
+	if w := serve(e, http.MethodPost, "/users"); w.Code != http.StatusNoContent {
+		t.Fatal("expected handler, got", w.Code)
+	}
+	w := serve(e, http.MethodDelete, "/users")
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, POST, OPTIONS" {
+		t.Fatal("expected 405 for route registered on Use router, got", w.Code, w.Header().Get("Allow"))
+	}
+	if w := serve(e, http.MethodGet, "/api/items"); w.Code != http.StatusMethodNotAllowed {
+		t.Fatal("expected 405 for route registered on group Use router, got", w.Code)
+	}
+}
+
+func TestEngine_DuplicateRoutes(t *testing.T) {
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	expectPanic := func(message string, register func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != message {
+				t.Errorf("expected panic %q, got %v", message, r)
+			}
+		}()
+		register()
+	}
+
+	e := New()
+	e.GET("/users", noop)
+	expectPanic("jug: GET /users is already registered", func() {
+		e.Use(noop).GET("/users", noop)
+	})
+	api := e.Group("/api")
+	api.Any("/items", noop)
+	expectPanic("jug: POST /api/items is already registered", func() {
+		api.POST("/items", noop)
+	})
+	e.ExpandMethods()
+	expectPanic("jug: POST /users is already registered by ExpandMethods, register routes before calling ExpandMethods", func() {
+		e.POST("/users", noop)
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type PeriodKind string
+
+const (
+	PeriodDay     PeriodKind = "day"
+	PeriodWeek    PeriodKind = "week"
+	PeriodMonth   PeriodKind = "month"
+	PeriodQuarter PeriodKind = "quarter"
+	PeriodYear    PeriodKind = "year"
+)
+
+// Period is a calendar period. Start is inclusive, End is exclusive.
+type Period struct {
+	Kind  PeriodKind
+	Start time.Time
+	End   time.Time
+}
+
+var (
+	periodYearRegex    = regexp.MustCompile(`^(\d{4})$`)
+	periodQuarterRegex = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+	periodMonthRegex   = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	periodWeekRegex    = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+	periodDayRegex     = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+)
+
+// ParsePeriod parses a period in one of the formats 2024, 2024-Q2, 2024-03, 2024-W07 or 2024-03-15.
+// The period boundaries are midnight in the given location.
+func ParsePeriod(s string, loc *time.Location) (*Period, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if m := periodYearRegex.FindStringSubmatch(s); m != nil {
+		start := time.Date(atoi(m[1]), time.January, 1, 0, 0, 0, 0, loc)
+		return &Period{Kind: PeriodYear, Start: start, End: start.AddDate(1, 0, 0)}, nil
+	}
+	if m := periodQuarterRegex.FindStringSubmatch(s); m != nil {
+		month := time.Month((atoi(m[2])-1)*3 + 1)
+		start := time.Date(atoi(m[1]), month, 1, 0, 0, 0, 0, loc)
+		return &Period{Kind: PeriodQuarter, Start: start, End: start.AddDate(0, 3, 0)}, nil
+	}
+	if m := periodMonthRegex.FindStringSubmatch(s); m != nil {
+		month := atoi(m[2])
+		if month < 1 || month > 12 {
+			return nil, fmt.Errorf("invalid month in period %s", s)
+		}
+		start := time.Date(atoi(m[1]), time.Month(month), 1, 0, 0, 0, 0, loc)
+		return &Period{Kind: PeriodMonth, Start: start, End: start.AddDate(0, 1, 0)}, nil
+	}
+	if m := periodWeekRegex.FindStringSubmatch(s); m != nil {
+		year, week := atoi(m[1]), atoi(m[2])
+		start := isoWeekStart(year, week, loc)
+		if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+			return nil, fmt.Errorf("invalid week in period %s", s)
+		}
+		return &Period{Kind: PeriodWeek, Start: start, End: start.AddDate(0, 0, 7)}, nil
+	}
+	if m := periodDayRegex.FindStringSubmatch(s); m != nil {
+		start, err := time.ParseInLocation("2006-01-02", s, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid day in period %s", s)
+		}
+		return &Period{Kind: PeriodDay, Start: start, End: start.AddDate(0, 0, 1)}, nil
+	}
+	return nil, fmt.Errorf("invalid period %s", s)
+}
+
+// isoWeekStart returns the Monday of the given ISO 8601 week.
+func isoWeekStart(year int, week int, loc *time.Location) time.Time {
+	// January 4th is always in week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	return jan4.AddDate(0, 0, (week-1)*7-offset)
+}
+
+// atoi converts a string known to consist of digits only.
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
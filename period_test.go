@@ -0,0 +1,49 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		value string
+		kind  PeriodKind
+		start time.Time
+		end   time.Time
+	}{
+		{"2024", PeriodYear, date(2024, 1, 1), date(2025, 1, 1)},
+		{"2024-Q2", PeriodQuarter, date(2024, 4, 1), date(2024, 7, 1)},
+		{"2024-Q4", PeriodQuarter, date(2024, 10, 1), date(2025, 1, 1)},
+		{"2024-03", PeriodMonth, date(2024, 3, 1), date(2024, 4, 1)},
+		{"2024-W07", PeriodWeek, date(2024, 2, 12), date(2024, 2, 19)},
+		{"2021-W01", PeriodWeek, date(2021, 1, 4), date(2021, 1, 11)},
+		{"2020-W53", PeriodWeek, date(2020, 12, 28), date(2021, 1, 4)},
+		{"2024-02-29", PeriodDay, date(2024, 2, 29), date(2024, 3, 1)},
+	}
+	for _, test := range tests {
+		p, err := ParsePeriod(test.value, time.UTC)
+		if err != nil {
+			t.Errorf("ParsePeriod(%s) should not fail, got %v", test.value, err)
+			continue
+		}
+		if p.Kind != test.kind || !p.Start.Equal(test.start) || !p.End.Equal(test.end) {
+			t.Errorf("ParsePeriod(%s) = %s %s - %s, expected %s %s - %s", test.value, p.Kind, p.Start, p.End, test.kind, test.start, test.end)
+		}
+	}
+}
+
+func TestParsePeriod_Invalid(t *testing.T) {
+	for _, value := range []string{"", "24", "2024-Q5", "2024-13", "2024-W00", "2021-W53", "2023-02-29", "2024-w07"} {
+		if _, err := ParsePeriod(value, time.UTC); err == nil {
+			t.Errorf("ParsePeriod(%s) should fail", value)
+		}
+	}
+}
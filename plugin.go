@@ -0,0 +1,49 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import "fmt"
+
+// Plugin bundles reusable setup, e.g. middleware, routes and event subscriptions, that is applied to an engine.
+type Plugin interface {
+	// Name identifies the plugin. A plugin is installed at most once per engine.
+	Name() string
+	// Install applies the plugin to the engine.
+	Install(e Engine) error
+}
+
+// PluginFunc creates a Plugin from a name and an install function.
+func PluginFunc(name string, install func(e Engine) error) Plugin {
+	return &funcPlugin{name: name, install: install}
+}
+
+type funcPlugin struct {
+	name    string
+	install func(e Engine) error
+}
+
+func (p *funcPlugin) Name() string {
+	return p.name
+}
+
+func (p *funcPlugin) Install(e Engine) error {
+	return p.install(e)
+}
+
+// installPlugins installs plugins in the given order. Plugins that are already installed are skipped.
+// Installation stops at the first plugin that fails.
+func installPlugins(e Engine, installed map[string]bool, plugins []Plugin) error {
+	for _, p := range plugins {
+		name := p.Name()
+		if installed[name] {
+			continue
+		}
+		if err := p.Install(e); err != nil {
+			return fmt.Errorf("unable to install plugin %s: %w", name, err)
+		}
+		installed[name] = true
+	}
+	return nil
+}
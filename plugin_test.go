@@ -0,0 +1,42 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestEngine_Install(t *testing.T) {
+	installs := 0
+	health := PluginFunc("health", func(e Engine) error {
+		installs++
+		e.GET("/health", func(c Context) {
+			c.RespondNoContent()
+		})
+		return nil
+	})
+	e := New()
+	if err := e.Install(health, health); err != nil {
+		t.Fatal("Install() should not fail, got", err)
+	}
+	if err := e.Install(health); err != nil {
+		t.Fatal("Install() should not fail, got", err)
+	}
+	if installs != 1 {
+		t.Fatal("expected plugin to be installed once, got", installs)
+	}
+	if w := serve(e, http.MethodGet, "/health"); w.Code != http.StatusNoContent {
+		t.Fatal("expected plugin route, got", w.Code)
+	}
+
+	failing := PluginFunc("failing", func(e Engine) error {
+		return errors.New("boom")
+	})
+	if err := e.Install(failing); err == nil || err.Error() != "unable to install plugin failing: boom" {
+		t.Fatal("expected install error, got", err)
+	}
+}
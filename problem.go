@@ -0,0 +1,112 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the content type of problem details responses.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem. Handling a ProblemDetails error responds with it, regardless of the engine's mode.
+type ProblemDetails struct {
+	// Type is a URI identifying the problem type. Defaults to about:blank.
+	Type string
+	// Title is a short summary of the problem type. Defaults to the status text.
+	Title string
+	// Status is the HTTP status code.
+	Status int
+	// Detail explains this occurrence of the problem.
+	Detail string
+	// Instance is a URI identifying this occurrence of the problem. Defaults to the request path.
+	Instance string
+	// Extensions are additional members of the problem.
+	Extensions map[string]any
+}
+
+// NewProblemDetails creates a problem with the given status and detail.
+func NewProblemDetails(status int, detail string) *ProblemDetails {
+	return &ProblemDetails{
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *ProblemDetails) Error() string {
+	if len(p.Detail) > 0 {
+		return p.Detail
+	}
+	return p.title()
+}
+
+func (p *ProblemDetails) title() string {
+	if len(p.Title) > 0 {
+		return p.Title
+	}
+	return http.StatusText(p.Status)
+}
+
+func (p *ProblemDetails) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	m["type"] = p.Type
+	if len(p.Type) == 0 {
+		m["type"] = "about:blank"
+	}
+	m["title"] = p.title()
+	m["status"] = p.Status
+	if len(p.Detail) > 0 {
+		m["detail"] = p.Detail
+	}
+	if len(p.Instance) > 0 {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// problemFor converts an error to a problem. Binding and validation errors are reported in the fields and errors members.
+func problemFor(status int, err error) *ProblemDetails {
+	var p *ProblemDetails
+	if errors.As(err, &p) {
+		return p
+	}
+	p = &ProblemDetails{Status: status}
+	if err != nil {
+		p.Detail = err.Error()
+	}
+	var be *BindingError
+	var ve *ValidationError
+	var rse *ResponseStatusError
+	if errors.As(err, &be) {
+		p.Extensions = map[string]any{"fields": be.Fields}
+	} else if errors.As(err, &ve) {
+		p.Extensions = map[string]any{"errors": ve.Errors}
+	} else if errors.As(err, &rse) && len(rse.Code) > 0 {
+		p.Extensions = map[string]any{"code": rse.Code}
+	}
+	return p
+}
+
+func (w *contextWrapper) respondProblem(p *ProblemDetails) {
+	copied := *p
+	if len(copied.Instance) == 0 {
+		copied.Instance = w.c.Request.URL.Path
+	}
+	if copied.Status == 0 {
+		copied.Status = http.StatusInternalServerError
+	}
+	p = &copied
+	data, err := w.config.jsonCodec.Marshal(p)
+	if err != nil {
+		w.c.Status(http.StatusInternalServerError)
+		return
+	}
+	w.c.Data(p.Status, ProblemContentType, data)
+}
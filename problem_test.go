@@ -0,0 +1,78 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestEngine_UseProblemJSON(t *testing.T) {
+	e := New()
+	e.UseProblemJSON()
+	e.GET("/conflict", func(c Context) {
+		c.HandleError(NewConflictError("name is taken"))
+	})
+	e.GET("/internal", func(c Context) {
+		c.HandleError(errors.New("database password is wrong"))
+	})
+	e.GET("/invalid", func(c Context) {
+		c.HandleError(NewValidator().Structured().RequireStringNotEmpty("", "name is required").Validate())
+	})
+	e.GET("/abort", func(c Context) {
+		c.AbortWithError(http.StatusForbidden, errors.New("no access"))
+	})
+
+	tests := []struct {
+		path     string
+		status   int
+		expected map[string]any
+	}{
+		{"/conflict", http.StatusConflict, map[string]any{"type": "about:blank", "title": "Conflict", "status": 409.0, "detail": "name is taken", "instance": "/conflict"}},
+		{"/internal", http.StatusInternalServerError, map[string]any{"type": "about:blank", "title": "Internal Server Error", "status": 500.0, "instance": "/internal"}},
+		{"/abort", http.StatusForbidden, map[string]any{"type": "about:blank", "title": "Forbidden", "status": 403.0, "detail": "no access", "instance": "/abort"}},
+		{"/invalid", http.StatusBadRequest, map[string]any{"type": "about:blank", "title": "Bad Request", "status": 400.0, "detail": "name is required", "instance": "/invalid",
+			"errors": []any{map[string]any{"code": "required", "message": "name is required"}}}},
+	}
+	for _, tt := range tests {
+		w := serve(e, http.MethodGet, tt.path)
+		if w.Code != tt.status {
+			t.Fatalf("expected %d for %s, got %d", tt.status, tt.path, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+			t.Fatalf("expected problem content type for %s, got %s", tt.path, ct)
+		}
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(body, tt.expected) {
+			t.Fatalf("expected %v for %s, got %v", tt.expected, tt.path, body)
+		}
+	}
+}
+
+func TestHandleError_ProblemDetails(t *testing.T) {
+	e := New()
+	e.GET("/out-of-credit", func(c Context) {
+		c.HandleError(&ProblemDetails{
+			Type:       "https://example.com/probs/out-of-credit",
+			Title:      "You do not have enough credit.",
+			Status:     http.StatusForbidden,
+			Extensions: map[string]any{"balance": 30},
+		})
+	})
+	w := serve(e, http.MethodGet, "/out-of-credit")
+	if w.Code != http.StatusForbidden || w.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatal("expected problem response, got", w.Code, w.Header().Get("Content-Type"))
+	}
+	expected := `{"balance":30,"instance":"/out-of-credit","status":403,"title":"You do not have enough credit.","type":"https://example.com/probs/out-of-credit"}`
+	if w.Body.String() != expected {
+		t.Fatalf("expected %s, got %s", expected, w.Body.String())
+	}
+}
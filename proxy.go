@@ -0,0 +1,150 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+type proxyConfig struct {
+	stripPrefix    string
+	preserveHost   bool
+	setHeaders     map[string]string
+	removeHeaders  []string
+	transport      http.RoundTripper
+	modifyResponse func(res *http.Response) error
+}
+
+// ProxyOption configures a reverse proxy request.
+type ProxyOption func(c *proxyConfig)
+
+// ProxyStripPrefix removes the prefix from the request path before it is joined with the target path.
+func ProxyStripPrefix(prefix string) ProxyOption {
+	return func(c *proxyConfig) {
+		c.stripPrefix = prefix
+	}
+}
+
+// ProxyPreserveHost forwards the Host header of the incoming request. By default the host of the target is used.
+func ProxyPreserveHost() ProxyOption {
+	return func(c *proxyConfig) {
+		c.preserveHost = true
+	}
+}
+
+// ProxySetHeader sets a header on the upstream request.
+func ProxySetHeader(key string, value string) ProxyOption {
+	return func(c *proxyConfig) {
+		c.setHeaders[key] = value
+	}
+}
+
+// ProxyRemoveHeader removes a header from the upstream request, e.g. Authorization or Cookie.
+func ProxyRemoveHeader(key string) ProxyOption {
+	return func(c *proxyConfig) {
+		c.removeHeaders = append(c.removeHeaders, key)
+	}
+}
+
+// ProxyTransport sets the transport used for upstream requests. Defaults to http.DefaultTransport.
+func ProxyTransport(transport http.RoundTripper) ProxyOption {
+	return func(c *proxyConfig) {
+		c.transport = transport
+	}
+}
+
+// ProxyModifyResponse sets a function that modifies the upstream response before it is copied to the client.
+// If it returns an error, the request fails with 502.
+func ProxyModifyResponse(f func(res *http.Response) error) ProxyOption {
+	return func(c *proxyConfig) {
+		c.modifyResponse = f
+	}
+}
+
+// newReverseProxy creates a reverse proxy to target. Errors are handled by onError.
+func newReverseProxy(target *url.URL, onError func(err error), opts ...ProxyOption) *httputil.ReverseProxy {
+	cfg := &proxyConfig{setHeaders: make(map[string]string)}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		host := r.Host
+		proto := "http"
+		if r.TLS != nil {
+			proto = "https"
+		}
+		if len(cfg.stripPrefix) > 0 {
+			r.URL.Path = stripPathPrefix(r.URL.Path, cfg.stripPrefix)
+			if len(r.URL.RawPath) > 0 {
+				r.URL.RawPath = stripPathPrefix(r.URL.RawPath, cfg.stripPrefix)
+			}
+		}
+		director(r)
+		if !cfg.preserveHost {
+			r.Host = target.Host
+		}
+		r.Header.Set("X-Forwarded-Host", host)
+		r.Header.Set("X-Forwarded-Proto", proto)
+		for _, key := range cfg.removeHeaders {
+			r.Header.Del(key)
+		}
+		for key, value := range cfg.setHeaders {
+			r.Header.Set(key, value)
+		}
+	}
+	proxy.Transport = cfg.transport
+	proxy.ModifyResponse = cfg.modifyResponse
+	proxy.ErrorHandler = func(_ http.ResponseWriter, _ *http.Request, err error) {
+		onError(err)
+	}
+	return proxy
+}
+
+// proxyWriter hides http.CloseNotifier of the gin writer, which panics if the underlying writer does not implement it.
+// The proxy watches the request context instead.
+type proxyWriter struct {
+	http.ResponseWriter
+}
+
+func (w proxyWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w proxyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func stripPathPrefix(p string, prefix string) string {
+	p = strings.TrimPrefix(p, strings.TrimSuffix(prefix, "/"))
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// handleProxyError maps upstream errors to error responses.
+// Requests canceled by the client are aborted without a response.
+func handleProxyError(c Context, err error) {
+	defer c.Abort()
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	if _, ok := bodyTooLarge(err); ok || errors.Is(err, context.DeadlineExceeded) {
+		c.HandleError(err)
+		return
+	}
+	log.Printf("[jug] proxy error: %v", err)
+	c.HandleError(NewResponseStatusError(http.StatusBadGateway, "bad gateway"))
+}
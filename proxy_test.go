@@ -0,0 +1,84 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestContext_Proxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.Path)
+		w.Header().Set("X-Host", r.Host)
+		w.Header().Set("X-Forwarded-Host", r.Header.Get("X-Forwarded-Host"))
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("X-Cookie", r.Header.Get("Cookie"))
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("upstream"))
+	}))
+	defer upstream.Close()
+	target, _ := url.Parse(upstream.URL + "/v1")
+
+	e := New()
+	e.GET("/api/*path", func(c Context) {
+		c.Proxy(target, ProxyStripPrefix("/api"), ProxySetHeader("X-Token", "secret"), ProxyRemoveHeader("Cookie"))
+	})
+	r := httptest.NewRequest(http.MethodGet, "http://jug.test/api/users/1", nil)
+	r.Header.Set("Cookie", "session=1")
+	w := serveRequest(e, r)
+
+	if w.Code != http.StatusTeapot || w.Body.String() != "upstream" {
+		t.Fatal("expected upstream response, got", w.Code, w.Body.String())
+	}
+	if p := w.Header().Get("X-Path"); p != "/v1/users/1" {
+		t.Fatal("expected stripped and joined path, got", p)
+	}
+	if h := w.Header().Get("X-Host"); h != target.Host {
+		t.Fatal("expected target host, got", h)
+	}
+	if h := w.Header().Get("X-Forwarded-Host"); h != "jug.test" {
+		t.Fatal("expected forwarded host, got", h)
+	}
+	if h := w.Header().Get("X-Token"); h != "secret" {
+		t.Fatal("expected header to be set, got", h)
+	}
+	if h := w.Header().Get("X-Cookie"); h != "" {
+		t.Fatal("expected header to be removed, got", h)
+	}
+}
+
+func TestContext_Proxy_Errors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	target, _ := url.Parse(upstream.URL)
+	upstream.Close()
+
+	e := New()
+	e.GET("/down", func(c Context) {
+		c.Proxy(target)
+	})
+	e.GET("/rejected", func(c Context) {
+		c.Proxy(target, ProxyTransport(roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+		})), ProxyModifyResponse(func(res *http.Response) error {
+			return errors.New("rejected")
+		}))
+	})
+	if w := serve(e, http.MethodGet, "/down"); w.Code != http.StatusBadGateway {
+		t.Fatal("expected 502 for unreachable upstream, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/rejected"); w.Code != http.StatusBadGateway {
+		t.Fatal("expected 502 for rejected response, got", w.Code)
+	}
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
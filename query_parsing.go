@@ -0,0 +1,25 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+const queryParsingModeKey = "jug.queryParsingMode"
+
+// QueryParsingMode determines how typed query helpers handle parse errors.
+type QueryParsingMode int
+
+const (
+	// LenientQueryParsing returns parse errors to the handler.
+	LenientQueryParsing QueryParsingMode = iota
+	// StrictQueryParsing responds with 400 and aborts the request on parse errors.
+	// The error is still returned to the handler.
+	StrictQueryParsing
+)
+
+// QueryParsing returns a middleware that overrides the engine's query parsing mode for a route or group.
+func QueryParsing(mode QueryParsingMode) HandlerFunc {
+	return func(c Context) {
+		c.Set(queryParsingModeKey, mode)
+	}
+}
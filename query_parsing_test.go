@@ -0,0 +1,97 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestStrictQueryParsing(t *testing.T) {
+	e := New()
+	e.SetQueryParsingMode(StrictQueryParsing)
+	handler := func(c Context) {
+		if _, err := c.IntQuery("limit"); err != nil {
+			return
+		}
+		c.RespondNoContent()
+	}
+	e.GET("/strict", handler)
+	e.GET("/lenient", QueryParsing(LenientQueryParsing), handler)
+
+	if w := serve(e, http.MethodGet, "/strict?limit=10"); w.Code != http.StatusNoContent {
+		t.Error("expected valid values to pass, got", w.Code)
+	}
+	w := serve(e, http.MethodGet, "/strict?limit=ten")
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"invalid value for query parameter limit"}` {
+		t.Error("expected 400 for invalid values, got", w.Code, w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/lenient?limit=ten"); w.Code != http.StatusOK {
+		t.Error("expected the route to override the engine mode, got", w.Code)
+	}
+}
+
+func TestLenientQueryParsing(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		if _, err := c.BoolQuery("active"); err == nil {
+			t.Error("BoolQuery() should return the parse error")
+		}
+		c.RespondNoContent()
+	})
+	if w := serve(e, http.MethodGet, "/?active=maybe"); w.Code != http.StatusNoContent {
+		t.Error("expected lenient parsing by default, got", w.Code)
+	}
+}
+
+func TestContext_NumericQueries(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		if v, err := c.Int64Query("id"); err != nil || v != 9007199254740993 {
+			t.Error("Int64Query() =", v, err)
+		}
+		if v, err := c.UIntQuery("limit"); err != nil || v != 25 {
+			t.Error("UIntQuery() =", v, err)
+		}
+		if v, err := c.UInt64Query("id"); err != nil || v != 9007199254740993 {
+			t.Error("UInt64Query() =", v, err)
+		}
+		if v, err := c.Float64Query("lat"); err != nil || v != 48.1374 {
+			t.Error("Float64Query() =", v, err)
+		}
+		if v, err := c.DurationQuery("interval"); err != nil || v != 90*time.Second {
+			t.Error("DurationQuery() =", v, err)
+		}
+		if v, err := c.DefaultDurationQuery("timeout", time.Minute); err != nil || v != time.Minute {
+			t.Error("DefaultDurationQuery() =", v, err)
+		}
+		if v, err := c.DefaultUIntQuery("offset", 10); err != nil || v != 10 {
+			t.Error("DefaultUIntQuery() =", v, err)
+		}
+		if _, err := c.UIntQuery("negative"); err == nil {
+			t.Error("UIntQuery() should fail for negative values")
+		}
+		c.RespondNoContent()
+	})
+	w := serve(e, http.MethodGet, "/?id=9007199254740993&limit=25&lat=48.1374&interval=1m30s&negative=-1")
+	if w.Code != http.StatusNoContent {
+		t.Fatal("expected lenient parsing, got", w.Code)
+	}
+}
+
+func TestContext_DurationQuery_Strict(t *testing.T) {
+	e := New()
+	e.SetQueryParsingMode(StrictQueryParsing)
+	e.GET("/", func(c Context) {
+		if _, err := c.DurationQuery("interval"); err != nil {
+			return
+		}
+		c.RespondNoContent()
+	})
+	if w := serve(e, http.MethodGet, "/?interval=soon"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected 400, got", w.Code)
+	}
+}
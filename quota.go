@@ -0,0 +1,157 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// QuotaConfig configures the Quota middleware.
+type QuotaConfig struct {
+	// Limit is the number of requests allowed per Period.
+	Limit int
+	// Period is the fixed window the quota applies to. Defaults to 24 hours.
+	Period time.Duration
+	// KeyFunc selects the key requests are counted by, e.g. RateLimitByTenant. Defaults to RateLimitByIP.
+	KeyFunc func(c Context) string
+	// Store counts the requests. Defaults to an in-memory store.
+	Store QuotaStore
+	// Provider overrides the quota per key, e.g. per tenant plan.
+	// Keys without override and keys the provider fails for use Limit and Period.
+	Provider QuotaProvider
+}
+
+// QuotaLimit is the number of requests allowed per period.
+type QuotaLimit struct {
+	Limit  int
+	Period time.Duration
+}
+
+// QuotaProvider provides the quotas of keys with individual limits. Implement it to load the quotas from a database.
+type QuotaProvider interface {
+	// Quota returns the quota for key. It returns false if the key has no individual quota.
+	Quota(key string) (QuotaLimit, bool, error)
+}
+
+// QuotaLimits is a QuotaProvider holding fixed quotas by key.
+type QuotaLimits map[string]QuotaLimit
+
+func (l QuotaLimits) Quota(key string) (QuotaLimit, bool, error) {
+	quota, ok := l[key]
+	return quota, ok, nil
+}
+
+// QuotaStore counts requests in fixed windows. Implement it to share quotas between instances, e.g. using Redis.
+type QuotaStore interface {
+	// Increment counts a request for key in the current window of the given period.
+	Increment(key string, period time.Duration) (QuotaUsage, error)
+}
+
+// QuotaUsage is the usage of a quota in the current window.
+type QuotaUsage struct {
+	// Count is the number of requests in the window, including the current one.
+	Count int
+	// Reset is the time until the window ends.
+	Reset time.Duration
+}
+
+// Quota returns a middleware that limits the number of requests per fixed period, e.g. a daily quota per tenant.
+// Unlike RateLimit, the quota is not refilled gradually but resets at the end of the period.
+// Responses carry X-Quota-Limit, X-Quota-Remaining and X-Quota-Reset headers.
+// Requests exceeding the quota are aborted with 429 and a Retry-After header.
+// If the store fails, the request is allowed.
+func Quota(config QuotaConfig) HandlerFunc {
+	if config.Limit <= 0 {
+		panic("jug: quota must be positive")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryQuotaStore()
+	}
+	quota := QuotaLimit{Limit: config.Limit, Period: config.Period}.normalize()
+	return func(c Context) {
+		key := config.KeyFunc(c)
+		quota := quota
+		if config.Provider != nil {
+			if q, ok, err := config.Provider.Quota(key); err == nil && ok && q.Limit > 0 {
+				quota = q.normalize()
+			}
+		}
+		usage, err := config.Store.Increment(key, quota.Period)
+		if err != nil {
+			return
+		}
+		remaining := quota.Limit - usage.Count
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.SetHeader("X-Quota-Limit", strconv.Itoa(quota.Limit))
+		c.SetHeader("X-Quota-Remaining", strconv.Itoa(remaining))
+		c.SetHeader("X-Quota-Reset", strconv.Itoa(seconds(usage.Reset)))
+		if usage.Count > quota.Limit {
+			c.SetHeader("Retry-After", strconv.Itoa(seconds(usage.Reset)))
+			c.HandleError(NewResponseStatusError(http.StatusTooManyRequests, "quota exceeded"))
+			c.Abort()
+		}
+	}
+}
+
+// normalize applies the defaults of QuotaConfig to a quota.
+func (q QuotaLimit) normalize() QuotaLimit {
+	if q.Period <= 0 {
+		q.Period = 24 * time.Hour
+	}
+	return q
+}
+
+// MemoryQuotaStore counts requests in memory.
+type MemoryQuotaStore struct {
+	mu      sync.Mutex
+	windows map[string]*quotaWindow
+	now     func() time.Time
+}
+
+type quotaWindow struct {
+	count int
+	end   time.Time
+}
+
+// NewMemoryQuotaStore creates an in-memory store. Ended windows are evicted periodically.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{
+		windows: make(map[string]*quotaWindow),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryQuotaStore) Increment(key string, period time.Duration) (QuotaUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.end) {
+		if !ok && len(s.windows) > 0 && len(s.windows)%1024 == 0 {
+			s.evict(now)
+		}
+		w = &quotaWindow{end: now.Add(period)}
+		s.windows[key] = w
+	}
+	w.count++
+	return QuotaUsage{Count: w.count, Reset: w.end.Sub(now)}, nil
+}
+
+// evict removes windows that have ended.
+func (s *MemoryQuotaStore) evict(now time.Time) {
+	for key, w := range s.windows {
+		if !now.Before(w.end) {
+			delete(s.windows, key)
+		}
+	}
+}
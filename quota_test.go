@@ -0,0 +1,62 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryQuotaStore_Increment(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryQuotaStore()
+	s.now = func() time.Time { return now }
+
+	for i := 1; i <= 3; i++ {
+		if usage, _ := s.Increment("a", time.Hour); usage.Count != i || usage.Reset != time.Hour {
+			t.Fatalf("expected request %d in a new window, got %+v", i, usage)
+		}
+	}
+	if usage, _ := s.Increment("b", time.Hour); usage.Count != 1 {
+		t.Fatal("expected keys to be counted separately, got", usage.Count)
+	}
+	now = now.Add(30 * time.Minute)
+	if usage, _ := s.Increment("a", time.Hour); usage.Count != 4 || usage.Reset != 30*time.Minute {
+		t.Fatalf("expected the window to continue, got %+v", usage)
+	}
+	now = now.Add(30 * time.Minute)
+	if usage, _ := s.Increment("a", time.Hour); usage.Count != 1 || usage.Reset != time.Hour {
+		t.Fatalf("expected the window to reset, got %+v", usage)
+	}
+}
+
+func TestQuota(t *testing.T) {
+	e := New()
+	e.Use(Tenant(TenantFromHeader("X-Tenant-ID")))
+	e.Use(Quota(QuotaConfig{
+		Limit:    1,
+		KeyFunc:  RateLimitByTenant,
+		Provider: QuotaLimits{"enterprise": {Limit: 2}},
+	}))
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	for tenant, limit := range map[string]int{"acme": 1, "enterprise": 2} {
+		for i := 0; i < limit; i++ {
+			if w := serveHeader(e, "/", "X-Tenant-ID", tenant); w.Code != http.StatusNoContent {
+				t.Fatalf("expected request %d of %s to pass, got %d", i+1, tenant, w.Code)
+			}
+		}
+		w := serveHeader(e, "/", "X-Tenant-ID", tenant)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("X-Quota-Remaining") != "0" {
+			t.Fatalf("expected %s to exceed its quota after %d requests, got %d", tenant, limit, w.Code)
+		}
+		if w.Header().Get("Retry-After") != "86400" {
+			t.Errorf("expected Retry-After of one day, got %s", w.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig configures the RateLimit middleware.
+type RateLimitConfig struct {
+	// Limit is the number of requests allowed per Window.
+	Limit int
+	// Window is the period in which Limit requests are allowed. Defaults to one minute.
+	Window time.Duration
+	// Burst is the size of the token bucket. Defaults to Limit.
+	Burst int
+	// KeyFunc selects the key requests are limited by. Defaults to RateLimitByIP.
+	KeyFunc func(c Context) string
+	// Store keeps the token buckets. Defaults to an in-memory store.
+	Store RateLimitStore
+	// Provider overrides the bucket per key, e.g. per tenant with RateLimitByTenant.
+	// Keys without override and keys the provider fails for use Limit, Window and Burst.
+	Provider RateLimitProvider
+}
+
+// RateLimitProvider provides the buckets of keys with individual limits, e.g. tenants on different plans.
+// Implement it to load the limits from a database.
+type RateLimitProvider interface {
+	// RateLimit returns the bucket for key. It returns false if the key has no individual limit.
+	RateLimit(key string) (TokenBucket, bool, error)
+}
+
+// RateLimits is a RateLimitProvider holding fixed buckets by key.
+type RateLimits map[string]TokenBucket
+
+func (l RateLimits) RateLimit(key string) (TokenBucket, bool, error) {
+	bucket, ok := l[key]
+	return bucket, ok, nil
+}
+
+// RateLimitStore keeps token buckets. Implement it to share limits between instances, e.g. using Redis.
+type RateLimitStore interface {
+	// Take takes a token from the bucket identified by key.
+	Take(key string, bucket TokenBucket) (RateLimitResult, error)
+}
+
+// TokenBucket describes a token bucket holding up to Burst tokens, refilled at Limit tokens per Window.
+type TokenBucket struct {
+	Limit  int
+	Window time.Duration
+	Burst  int
+}
+
+// RateLimitResult is the result of taking a token from a bucket.
+type RateLimitResult struct {
+	// Allowed reports whether a token was available.
+	Allowed bool
+	// Remaining is the number of tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available. It is zero if the request was allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimitByIP limits requests by client IP as resolved by Context.ClientIP.
+// Forwarding headers are only used behind proxies trusted with Engine.SetTrustedProxies.
+func RateLimitByIP(c Context) string {
+	return c.ClientIP()
+}
+
+// RateLimitByTenant limits requests by the tenant resolved by the Tenant middleware, see TenantID.
+// It panics if the tenant is missing, since requests without tenant would share one bucket.
+func RateLimitByTenant(c Context) string {
+	tenant := TenantID(c)
+	if len(tenant) == 0 {
+		panic("jug: RateLimitByTenant requires the Tenant middleware")
+	}
+	return tenant
+}
+
+// RateLimitByHeader limits requests by the value of a request header.
+func RateLimitByHeader(name string) func(c Context) string {
+	return func(c Context) string {
+		return c.GetHeader(name)
+	}
+}
+
+// RateLimit returns a middleware that limits requests using token buckets.
+// Responses carry RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+// Limited requests are aborted with 429 and a Retry-After header.
+// If the store fails, the request is allowed.
+func RateLimit(config RateLimitConfig) HandlerFunc {
+	if config.Limit <= 0 {
+		panic("jug: rate limit must be positive")
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateLimitStore()
+	}
+	bucket := TokenBucket{
+		Limit:  config.Limit,
+		Window: config.Window,
+		Burst:  config.Burst,
+	}.normalize()
+	return func(c Context) {
+		key := config.KeyFunc(c)
+		bucket := bucket
+		if config.Provider != nil {
+			if b, ok, err := config.Provider.RateLimit(key); err == nil && ok && b.Limit > 0 {
+				bucket = b.normalize()
+			}
+		}
+		res, err := config.Store.Take(key, bucket)
+		if err != nil {
+			return
+		}
+		c.SetHeader("RateLimit-Limit", strconv.Itoa(bucket.Burst))
+		c.SetHeader("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.SetHeader("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+		if !res.Allowed {
+			c.SetHeader("Retry-After", strconv.Itoa(seconds(res.RetryAfter)))
+			c.HandleError(NewResponseStatusError(http.StatusTooManyRequests, "too many requests"))
+			c.Abort()
+		}
+	}
+}
+
+// normalize applies the defaults of RateLimitConfig to a bucket.
+func (b TokenBucket) normalize() TokenBucket {
+	if b.Window <= 0 {
+		b.Window = time.Minute
+	}
+	if b.Burst <= 0 {
+		b.Burst = b.Limit
+	}
+	return b
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore keeps token buckets in memory.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	now     func() time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	last   time.Time
+	// rate and burst of the bucket, buckets of different keys may differ, see RateLimitConfig.Provider.
+	rate  float64
+	burst int
+}
+
+// NewMemoryRateLimitStore creates an in-memory store. Full buckets are evicted periodically.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*memoryBucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, bucket TokenBucket) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	rate := float64(bucket.Limit) / bucket.Window.Seconds()
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) > 0 && len(s.buckets)%1024 == 0 {
+			s.evict(now)
+		}
+		b = &memoryBucket{tokens: float64(bucket.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.rate, b.burst = rate, bucket.Burst
+	b.tokens = math.Min(float64(bucket.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = secondsToDuration((float64(bucket.Burst) - b.tokens) / rate)
+	return res, nil
+}
+
+// evict removes buckets that have been refilled completely.
+func (s *MemoryRateLimitStore) evict(now time.Time) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*b.rate >= float64(b.burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStore_Take(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryRateLimitStore()
+	s.now = func() time.Time { return now }
+	bucket := TokenBucket{Limit: 2, Window: time.Second, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if res, _ := s.Take("a", bucket); !res.Allowed {
+			t.Fatalf("request %d should be allowed", i)
+		}
+	}
+	res, _ := s.Take("a", bucket)
+	if res.Allowed {
+		t.Fatal("third request should be limited")
+	}
+	if res.RetryAfter != 500*time.Millisecond {
+		t.Fatal("expected retry after 500ms, got", res.RetryAfter)
+	}
+	if res, _ := s.Take("b", bucket); !res.Allowed {
+		t.Fatal("other keys should not be limited")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := s.Take("a", bucket); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected a refilled token, got %+v", res)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	e := New()
+	e.Use(RateLimit(RateLimitConfig{Limit: 1, Window: time.Hour}))
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	w := serve(e, http.MethodGet, "/")
+	if w.Code != http.StatusNoContent {
+		t.Fatal("expected first request to pass, got", w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatal("expected rate limit headers, got", w.Header())
+	}
+	w = serve(e, http.MethodGet, "/")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatal("expected second request to be limited, got", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "3600" {
+		t.Fatal("expected Retry-After of one hour, got", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	e := New()
+	e.Use(RateLimit(RateLimitConfig{Limit: 1, Window: time.Hour, KeyFunc: RateLimitByIP}))
+	e.GET("/admin", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	if w := serveFrom(e, "198.51.100.1:1234", "203.0.113.1"); w.Code != http.StatusNoContent {
+		t.Fatal("expected first request to pass, got", w.Code)
+	}
+	if w := serveFrom(e, "198.51.100.1:1234", "203.0.113.2"); w.Code != http.StatusTooManyRequests {
+		t.Fatal("expected a spoofed X-Forwarded-For to share the peer's limit, got", w.Code)
+	}
+
+	if err := e.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if w := serveFrom(e, "10.0.0.1:1234", "203.0.113.1"); w.Code != http.StatusNoContent {
+		t.Fatal("expected clients behind a trusted proxy to be limited separately, got", w.Code)
+	}
+	if w := serveFrom(e, "10.0.0.1:1234", "203.0.113.2"); w.Code != http.StatusNoContent {
+		t.Fatal("expected clients behind a trusted proxy to be limited separately, got", w.Code)
+	}
+}
+
+// failingRateLimitProvider fails for every key.
+type failingRateLimitProvider struct{}
+
+func (failingRateLimitProvider) RateLimit(key string) (TokenBucket, bool, error) {
+	return TokenBucket{}, false, errors.New("database unavailable")
+}
+
+func TestRateLimitByTenant(t *testing.T) {
+	e := New()
+	e.Use(Tenant(TenantFromHeader("X-Tenant-ID")))
+	e.Use(RateLimit(RateLimitConfig{
+		Limit:    1,
+		Window:   time.Hour,
+		KeyFunc:  RateLimitByTenant,
+		Provider: RateLimits{"enterprise": {Limit: 2, Window: time.Hour}},
+	}))
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	for tenant, limit := range map[string]int{"acme": 1, "globex": 1, "enterprise": 2} {
+		for i := 0; i < limit; i++ {
+			if w := serveHeader(e, "/", "X-Tenant-ID", tenant); w.Code != http.StatusNoContent {
+				t.Fatalf("expected request %d of %s to pass, got %d", i+1, tenant, w.Code)
+			}
+		}
+		w := serveHeader(e, "/", "X-Tenant-ID", tenant)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected %s to be limited after %d requests, got %d", tenant, limit, w.Code)
+		}
+		if w.Header().Get("RateLimit-Limit") != strconv.Itoa(limit) {
+			t.Errorf("expected RateLimit-Limit %d for %s, got %s", limit, tenant, w.Header().Get("RateLimit-Limit"))
+		}
+	}
+}
+
+func TestRateLimit_ProviderFailure(t *testing.T) {
+	e := New()
+	e.Use(RateLimit(RateLimitConfig{Limit: 1, Window: time.Hour, Provider: failingRateLimitProvider{}}))
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusNoContent {
+		t.Fatal("expected first request to pass, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusTooManyRequests {
+		t.Fatal("expected default limit when the provider fails, got", w.Code)
+	}
+}
+
+func TestMemoryRateLimitStore_EvictPerBucket(t *testing.T) {
+	now := time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC)
+	s := NewMemoryRateLimitStore()
+	s.now = func() time.Time { return now }
+	_, _ = s.Take("slow", TokenBucket{Limit: 1, Window: time.Hour, Burst: 1})
+	_, _ = s.Take("fast", TokenBucket{Limit: 100, Window: time.Second, Burst: 100})
+
+	now = now.Add(time.Second)
+	s.evict(now)
+	if _, ok := s.buckets["fast"]; ok {
+		t.Error("expected the refilled bucket to be evicted")
+	}
+	if _, ok := s.buckets["slow"]; !ok {
+		t.Error("expected the bucket refilling at its own rate to be kept")
+	}
+}
+
+func TestRateLimitByTenant_MissingTenant(t *testing.T) {
+	e := New()
+	e.Use(Recovery(DefaultRecoveryHandler), RateLimit(RateLimitConfig{Limit: 1, KeyFunc: RateLimitByTenant}))
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusInternalServerError {
+		t.Error("expected requests without tenant to fail, got", w.Code)
+	}
+}
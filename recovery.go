@@ -0,0 +1,52 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+)
+
+// Recovery returns a middleware that recovers from panics in subsequent handlers.
+// The panic value and stack trace are logged, then handler is invoked to write the response and the request is aborted.
+// If handler is nil, DefaultRecoveryHandler is used. Panics with http.ErrAbortHandler are re-panicked,
+// so the server aborts the response.
+func Recovery(handler func(c Context, recovered any)) HandlerFunc {
+	if handler == nil {
+		handler = DefaultRecoveryHandler
+	}
+	return func(c Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+			if err, ok := recovered.(error); ok && isBrokenConnection(err) {
+				log.Printf("[jug] connection lost: %v", err)
+				c.Abort()
+				return
+			}
+			log.Printf("[jug] panic recovered: %v\n%s", recovered, debug.Stack())
+			handler(c, recovered)
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// DefaultRecoveryHandler passes a 500 error with a generic message to the error handler.
+func DefaultRecoveryHandler(c Context, _ any) {
+	c.HandleError(NewResponseStatusError(http.StatusInternalServerError, "internal server error"))
+}
+
+func isBrokenConnection(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRecovery(t *testing.T) {
+	handled := 0
+	e := New()
+	e.SetErrorHandler(func(c Context, err error) {
+		handled++
+		DefaultErrorHandler(c, err)
+	})
+	e.Use(Recovery(nil))
+	e.GET("/", func(c Context) {
+		panic("boom")
+	})
+
+	w := serve(e, http.MethodGet, "/")
+	if w.Code != http.StatusInternalServerError || w.Body.String() != `{"error":"internal server error"}` {
+		t.Error("expected 500, got", w.Code, w.Body.String())
+	}
+	if handled != 1 {
+		t.Error("expected the error handler to write the response, got", handled)
+	}
+}
+
+func TestRecovery_CustomHandler(t *testing.T) {
+	var recovered any
+	e := New()
+	e.Use(Recovery(func(c Context, r any) {
+		recovered = r
+		c.Status(http.StatusServiceUnavailable)
+	}))
+	e.GET("/", func(c Context) {
+		panic("boom")
+	})
+
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusServiceUnavailable {
+		t.Error("expected the custom handler to write the response, got", w.Code)
+	}
+	if recovered != "boom" {
+		t.Error("expected the panic value, got", recovered)
+	}
+}
+
+func TestRecovery_ErrAbortHandler(t *testing.T) {
+	e := New()
+	e.Use(Recovery(func(c Context, r any) {
+		t.Error("expected ErrAbortHandler not to be recovered")
+	}))
+	e.GET("/", func(c Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Error("expected ErrAbortHandler to be re-panicked, got", r)
+		}
+	}()
+	serve(e, http.MethodGet, "/")
+}
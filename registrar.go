@@ -0,0 +1,79 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"sort"
+	"strings"
+)
+
+// RouteModule describes a set of routes that is mounted below a common prefix.
+type RouteModule struct {
+	// Prefix is the path prefix of the module. It is normalized to start with a slash and to end without one.
+	Prefix string
+	// Order determines the mount order. Modules with a lower order are mounted first.
+	Order int
+	// Middleware is applied to all routes of the module.
+	Middleware []HandlerFunc
+	// Register sets up the routes of the module.
+	Register func(r RouterGroup)
+}
+
+// Registrar collects route modules and mounts them on a router.
+// It allows large APIs to keep their route definitions next to their handlers instead of in a single file.
+type Registrar struct {
+	modules []RouteModule
+}
+
+func NewRegistrar() *Registrar {
+	return &Registrar{
+		modules: make([]RouteModule, 0),
+	}
+}
+
+// Add adds a module with the default order.
+func (r *Registrar) Add(prefix string, register func(r RouterGroup), middleware ...HandlerFunc) *Registrar {
+	return r.AddModule(RouteModule{
+		Prefix:     prefix,
+		Middleware: middleware,
+		Register:   register,
+	})
+}
+
+// AddModule adds a module.
+func (r *Registrar) AddModule(module RouteModule) *Registrar {
+	module.Prefix = normalizePrefix(module.Prefix)
+	r.modules = append(r.modules, module)
+	return r
+}
+
+// Modules returns the modules in mount order.
+// Modules are sorted by order, then by prefix. Modules with equal order and prefix keep their insertion order.
+func (r *Registrar) Modules() []RouteModule {
+	modules := make([]RouteModule, len(r.modules))
+	copy(modules, r.modules)
+	sort.SliceStable(modules, func(i, j int) bool {
+		if modules[i].Order != modules[j].Order {
+			return modules[i].Order < modules[j].Order
+		}
+		return modules[i].Prefix < modules[j].Prefix
+	})
+	return modules
+}
+
+// Mount mounts all modules on the given router. Each module gets its own group.
+func (r *Registrar) Mount(router RouterGroup) {
+	for _, m := range r.Modules() {
+		m.Register(router.Group(m.Prefix, m.Middleware...))
+	}
+}
+
+func normalizePrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if len(prefix) == 0 {
+		return ""
+	}
+	return "/" + prefix
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegistrar_Mount(t *testing.T) {
+	var calls []string
+	tag := func(name string) HandlerFunc {
+		return func(c Context) {
+			calls = append(calls, name)
+		}
+	}
+	e := New()
+	api := e.Group("/api", tag("group"))
+	NewRegistrar().
+		Add("users/", func(r RouterGroup) {
+			r.GET("/:id", func(c Context) {
+				c.String(http.StatusOK, "user "+c.Param("id"))
+			})
+		}, tag("users")).
+		AddModule(RouteModule{Prefix: "/orders", Order: -1, Register: func(r RouterGroup) {
+			r.GET("", func(c Context) {
+				c.String(http.StatusOK, "orders")
+			})
+		}}).
+		Mount(api)
+
+	w := serve(e, http.MethodGet, "/api/users/42")
+	if w.Code != http.StatusOK || w.Body.String() != "user 42" {
+		t.Fatal("expected the module route below the group, got", w.Code, w.Body.String())
+	}
+	if len(calls) != 2 || calls[0] != "group" || calls[1] != "users" {
+		t.Fatal("expected group and module middleware, got", calls)
+	}
+
+	calls = nil
+	if w := serve(e, http.MethodGet, "/api/orders"); w.Body.String() != "orders" {
+		t.Fatal("expected the second module, got", w.Code, w.Body.String())
+	}
+	if len(calls) != 1 || calls[0] != "group" {
+		t.Fatal("expected module middleware to stay in its module, got", calls)
+	}
+	if w := serve(e, http.MethodGet, "/users/42"); w.Code != http.StatusNotFound {
+		t.Fatal("expected no routes outside the group, got", w.Code)
+	}
+}
+
+func TestRegistrar_Modules(t *testing.T) {
+	r := NewRegistrar().
+		Add("/b", func(RouterGroup) {}).
+		Add("/a", func(RouterGroup) {}).
+		AddModule(RouteModule{Prefix: "z", Order: -1})
+	modules := r.Modules()
+	if modules[0].Prefix != "/z" || modules[1].Prefix != "/a" || modules[2].Prefix != "/b" {
+		t.Fatal("expected modules sorted by order and prefix, got", modules)
+	}
+}
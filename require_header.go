@@ -0,0 +1,45 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// HeaderRequirement is a request header required by a route, see Route.RequireHeader.
+type HeaderRequirement struct {
+	Name string
+	// Pattern is the regular expression the value must match. It is empty if any value is accepted.
+	Pattern string
+	pattern *regexp.Regexp
+}
+
+func newHeaderRequirement(name string, pattern *regexp.Regexp) HeaderRequirement {
+	h := HeaderRequirement{Name: name, pattern: pattern}
+	if pattern != nil {
+		h.Pattern = pattern.String()
+	}
+	return h
+}
+
+// RequireHeader returns a middleware that requires a request header to be present.
+// If pattern is not nil, the header value must match it.
+// Requests failing the requirement are aborted with 400.
+// Use Route.RequireHeader to declare the requirement on a route, so that it is reported in the RouteInfo.
+func RequireHeader(name string, pattern *regexp.Regexp) HandlerFunc {
+	return func(c Context) {
+		value := c.GetHeader(name)
+		if len(value) == 0 {
+			c.RespondBadRequestE(fmt.Errorf("header %s is required", name))
+			c.Abort()
+			return
+		}
+		if pattern != nil && !pattern.MatchString(value) {
+			c.RespondBadRequestE(fmt.Errorf("header %s is invalid", name))
+			c.Abort()
+		}
+	}
+}
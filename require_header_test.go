@@ -0,0 +1,85 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestRequireHeader(t *testing.T) {
+	e := New()
+	e.GET("/", RequireHeader("X-Tenant", regexp.MustCompile(`^[a-z]+$`)), func(c Context) {
+		c.RespondNoContent()
+	})
+	e.GET("/any", RequireHeader("X-Tenant", nil), func(c Context) {
+		c.RespondNoContent()
+	})
+
+	tests := []struct {
+		path     string
+		value    string
+		expected int
+		body     string
+	}{
+		{"/", "acme", http.StatusNoContent, ""},
+		{"/", "", http.StatusBadRequest, `{"error":"header X-Tenant is required"}`},
+		{"/", "ACME-1", http.StatusBadRequest, `{"error":"header X-Tenant is invalid"}`},
+		{"/any", "ACME-1", http.StatusNoContent, ""},
+		{"/any", "", http.StatusBadRequest, `{"error":"header X-Tenant is required"}`},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if len(tt.value) > 0 {
+			r.Header.Set("X-Tenant", tt.value)
+		}
+		w := serveRequest(e, r)
+		if w.Code != tt.expected || w.Body.String() != tt.body {
+			t.Errorf("expected %d %s for %s with %q, got %d %s", tt.expected, tt.body, tt.path, tt.value, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRoute_RequireHeader(t *testing.T) {
+	e := New()
+	e.GET("/reports", func(c Context) {
+		c.RespondNoContent()
+	}).RequireHeader("X-Api-Version", regexp.MustCompile(`^[0-9]+$`)).RequireHeader("X-Tenant", nil)
+	e.GET("/health", func(c Context) {
+		c.RespondNoContent()
+	})
+	get := func(version string, tenant string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/reports", nil)
+		r.Header.Set("X-Api-Version", version)
+		r.Header.Set("X-Tenant", tenant)
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := get("2", "acme"); w.Code != http.StatusNoContent {
+		t.Error("expected valid headers to pass, got", w.Code)
+	}
+	if w := get("v2", "acme"); w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"header X-Api-Version is invalid"}` {
+		t.Error("expected invalid header to be rejected, got", w.Code, w.Body.String())
+	}
+	if w := get("2", ""); w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"header X-Tenant is required"}` {
+		t.Error("expected missing header to be rejected, got", w.Code, w.Body.String())
+	}
+
+	routes := e.Routes()
+	if len(routes) != 2 || routes[0].Path != "/reports" || routes[1].Path != "/health" {
+		t.Fatal("expected all routes, got", routes)
+	}
+	headers := routes[0].Headers
+	if len(headers) != 2 || headers[0].Name != "X-Api-Version" || headers[0].Pattern != "^[0-9]+$" || headers[1].Name != "X-Tenant" || headers[1].Pattern != "" {
+		t.Error("expected header requirements in the route info, got", headers)
+	}
+	if len(routes[1].Headers) != 0 {
+		t.Error("expected no header requirements, got", routes[1].Headers)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestContext_RespondHelpers(t *testing.T) {
+	e := New()
+	e.GET("/respond", func(c Context) { c.Respond(http.StatusTeapot, map[string]string{"a": "b"}) })
+	e.GET("/accepted", func(c Context) { c.RespondAccepted(map[string]string{"job": "1"}) })
+	e.GET("/partial", func(c Context) { c.RespondPartialContent([]int{1}) })
+	e.GET("/moved", func(c Context) { c.RespondMovedPermanently("/new") })
+	e.GET("/found", func(c Context) { c.RespondFound("/other") })
+	e.GET("/gone", func(c Context) { c.RespondGoneE(errors.New("deleted")) })
+	e.GET("/unprocessable", func(c Context) { c.RespondUnprocessableEntityE(errors.New("invalid state")) })
+	e.GET("/limited", func(c Context) { c.RespondTooManyRequestsE(1500*time.Millisecond, errors.New("slow down")) })
+	e.GET("/limited-no-retry", func(c Context) { c.RespondTooManyRequests(0, nil) })
+	e.GET("/unavailable", func(c Context) { c.RespondServiceUnavailableE(errors.New("maintenance")) })
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/respond", http.StatusTeapot, `{"a":"b"}`},
+		{"/accepted", http.StatusAccepted, `{"job":"1"}`},
+		{"/partial", http.StatusPartialContent, `[1]`},
+		{"/gone", http.StatusGone, `{"error":"deleted"}`},
+		{"/unprocessable", http.StatusUnprocessableEntity, `{"error":"invalid state"}`},
+		{"/limited", http.StatusTooManyRequests, `{"error":"slow down"}`},
+		{"/limited-no-retry", http.StatusTooManyRequests, ``},
+		{"/unavailable", http.StatusServiceUnavailable, `{"error":"maintenance"}`},
+	}
+	for _, tt := range tests {
+		w := serve(e, http.MethodGet, tt.path)
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Fatalf("%s: expected %d %s, got %d %s", tt.path, tt.status, tt.body, w.Code, w.Body.String())
+		}
+	}
+
+	if w := serve(e, http.MethodGet, "/limited"); w.Header().Get("Retry-After") != "2" {
+		t.Fatal("expected Retry-After to be rounded up, got", w.Header().Get("Retry-After"))
+	}
+	if w := serve(e, http.MethodGet, "/limited-no-retry"); len(w.Header().Values("Retry-After")) != 0 {
+		t.Fatal("expected no Retry-After header")
+	}
+	if w := serve(e, http.MethodGet, "/moved"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/new" {
+		t.Fatal("expected permanent redirect, got", w.Code, w.Header().Get("Location"))
+	}
+	if w := serve(e, http.MethodGet, "/found"); w.Code != http.StatusFound || w.Header().Get("Location") != "/other" {
+		t.Fatal("expected redirect, got", w.Code, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ResponseInfo is the response passed to OnResponse hooks. Hooks may modify it.
+type ResponseInfo struct {
+	Status int
+	Header http.Header
+	// Body is the response body. It is only set for buffered responses, see Context.BufferResponse.
+	Body []byte
+}
+
+// interceptWriterKey stores the interceptWriter of a request.
+const interceptWriterKey = "jug.interceptWriter"
+
+// interceptWriter runs the OnResponse hooks before the headers are written.
+// In buffered mode, the body is held back until the handlers returned.
+type interceptWriter struct {
+	gin.ResponseWriter
+	hooks    []func(r *ResponseInfo)
+	buffered bool
+	body     bytes.Buffer
+	// written reports whether the handlers wrote to a buffered response.
+	written  bool
+	done     bool
+	hijacked bool
+}
+
+// interceptWriterOf returns the interceptWriter of a request and installs it if necessary.
+func interceptWriterOf(c *gin.Context) *interceptWriter {
+	if w, ok := c.Get(interceptWriterKey); ok {
+		return w.(*interceptWriter)
+	}
+	w := &interceptWriter{ResponseWriter: c.Writer}
+	c.Writer = w
+	c.Set(interceptWriterKey, w)
+	return w
+}
+
+// finishResponses writes intercepted responses after the handlers returned.
+func finishResponses(c *gin.Context) {
+	c.Next()
+	if w, ok := c.Get(interceptWriterKey); ok {
+		w.(*interceptWriter).finish()
+	}
+}
+
+func (w *interceptWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *interceptWriter) WriteHeaderNow() {
+	if w.buffered {
+		w.written = true
+		return
+	}
+	w.runHooks(nil)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *interceptWriter) Write(data []byte) (int, error) {
+	if w.buffered {
+		w.written = true
+		return w.body.Write(data)
+	}
+	w.runHooks(nil)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *interceptWriter) WriteString(s string) (int, error) {
+	if w.buffered {
+		w.written = true
+		return w.body.WriteString(s)
+	}
+	w.runHooks(nil)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Flush is a no-op for buffered responses.
+func (w *interceptWriter) Flush() {
+	if w.buffered {
+		return
+	}
+	w.runHooks(nil)
+	w.ResponseWriter.Flush()
+}
+
+func (w *interceptWriter) Written() bool {
+	if w.buffered {
+		return w.written
+	}
+	return w.ResponseWriter.Written()
+}
+
+func (w *interceptWriter) Size() int {
+	if w.buffered {
+		if !w.written {
+			return -1
+		}
+		return w.body.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *interceptWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.ResponseWriter.Hijack()
+}
+
+// runHooks runs the hooks once and applies the status they set.
+func (w *interceptWriter) runHooks(body []byte) *ResponseInfo {
+	info := &ResponseInfo{Status: w.ResponseWriter.Status(), Header: w.Header(), Body: body}
+	if w.done {
+		return info
+	}
+	w.done = true
+	for _, hook := range w.hooks {
+		hook(info)
+	}
+	if info.Status != w.ResponseWriter.Status() {
+		w.ResponseWriter.WriteHeader(info.Status)
+	}
+	return info
+}
+
+// finish runs the hooks of responses without body and writes buffered responses.
+func (w *interceptWriter) finish() {
+	if w.hijacked || w.done {
+		return
+	}
+	if !w.buffered {
+		w.runHooks(nil)
+		return
+	}
+	info := w.runHooks(w.body.Bytes())
+	if len(info.Header.Get("Content-Length")) > 0 {
+		info.Header.Set("Content-Length", strconv.Itoa(len(info.Body)))
+	}
+	if len(info.Body) == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(info.Body)
+}
+
+// unwrapResponseWriter returns the writer of the server underneath the writers wrapping it.
+func unwrapResponseWriter(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
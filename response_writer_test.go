@@ -0,0 +1,201 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestContext_EarlyHints(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		c.EarlyHints("</style.css>; rel=preload; as=style")
+		c.String(http.StatusOK, "page")
+	}).Cache(time.Minute, true)
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	var hints []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				hints = header["Link"]
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if len(hints) != 1 || hints[0] != "</style.css>; rel=preload; as=style" {
+		t.Fatal("expected early hints, got", hints)
+	}
+	if res.StatusCode != http.StatusOK || res.Header.Get("Link") != "</style.css>; rel=preload; as=style" || len(res.Header.Get("Cache-Control")) == 0 {
+		t.Fatal("expected final response, got", res.StatusCode, res.Header)
+	}
+}
+
+func TestContext_SetTrailer(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		c.Stream(func(w io.Writer) bool {
+			_, _ = w.Write([]byte("data"))
+			return false
+		})
+		c.SetTrailer("Checksum", "abc")
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(res.Body)
+	if string(body) != "data" || res.Trailer.Get("Checksum") != "abc" {
+		t.Fatal("expected body and trailer, got", string(body), res.Trailer)
+	}
+}
+
+func TestContext_Flush(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		c.Status(http.StatusAccepted)
+		if err := c.Flush(); err != nil {
+			t.Error("expected flush to succeed, got", err)
+		}
+	})
+	w := serve(e, http.MethodGet, "/")
+	if !w.Flushed || w.Code != http.StatusAccepted {
+		t.Fatal("expected flushed response, got", w.Flushed, w.Code)
+	}
+}
+
+// plainWriter supports neither flushing, hijacking nor server push.
+type plainWriter struct {
+	header http.Header
+}
+
+func (w *plainWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *plainWriter) Write(data []byte) (int, error) {
+	return len(data), nil
+}
+
+func (w *plainWriter) WriteHeader(int) {}
+
+func TestContext_NotSupported(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		if err := c.Flush(); err != http.ErrNotSupported {
+			t.Error("expected flush not to be supported, got", err)
+		}
+		if _, _, err := c.Hijack(); err != http.ErrNotSupported {
+			t.Error("expected hijack not to be supported, got", err)
+		}
+		if err := c.Push("/style.css", nil); err != http.ErrNotSupported {
+			t.Error("expected push not to be supported, got", err)
+		}
+	})
+	e.ServeHTTP(&plainWriter{header: make(http.Header)}, httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestContext_Hijack(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) {
+		conn, rw, err := c.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+		_ = rw.Flush()
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if body, _ := io.ReadAll(res.Body); string(body) != "hijacked" {
+		t.Fatal("expected hijacked response, got", string(body))
+	}
+}
+
+func TestContext_OnResponse(t *testing.T) {
+	var seen []int
+	e := New()
+	e.Use(func(c Context) {
+		c.OnResponse(func(r *ResponseInfo) {
+			seen = append(seen, r.Status)
+			r.Header.Set("X-Signature", "signed")
+		})
+		c.OnResponse(func(r *ResponseInfo) {
+			if r.Status == http.StatusTeapot {
+				r.Status = http.StatusOK
+			}
+		})
+	})
+	e.GET("/tea", func(c Context) {
+		c.String(http.StatusTeapot, "tea")
+	})
+	e.GET("/empty", func(c Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := serve(e, http.MethodGet, "/tea")
+	if w.Code != http.StatusOK || w.Body.String() != "tea" || w.Header().Get("X-Signature") != "signed" {
+		t.Fatal("expected hooks to modify the response, got", w.Code, w.Body.String(), w.Header())
+	}
+	w = serve(e, http.MethodGet, "/empty")
+	if w.Code != http.StatusNoContent || w.Header().Get("X-Signature") != "signed" {
+		t.Fatal("expected hooks to run for responses without body, got", w.Code, w.Header())
+	}
+	if len(seen) != 2 || seen[0] != http.StatusTeapot || seen[1] != http.StatusNoContent {
+		t.Fatal("expected hooks to run once per response, got", seen)
+	}
+}
+
+func TestContext_BufferResponse(t *testing.T) {
+	e := New()
+	e.Use(func(c Context) {
+		c.BufferResponse()
+		c.OnResponse(func(r *ResponseInfo) {
+			r.Body = bytes.Replace(r.Body, []byte("</body>"), []byte("<script src=\"/live.js\"></script></body>"), 1)
+		})
+	})
+	e.GET("/", func(c Context) {
+		page := "<html><body></body></html>"
+		c.SetHeader("Content-Length", strconv.Itoa(len(page)))
+		c.String(http.StatusOK, page)
+		if err := c.Flush(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	w := serve(e, http.MethodGet, "/")
+	expected := "<html><body><script src=\"/live.js\"></script></body></html>"
+	if w.Body.String() != expected || w.Header().Get("Content-Length") != strconv.Itoa(len(expected)) {
+		t.Fatal("expected injected body, got", w.Body.String(), w.Header().Get("Content-Length"))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RouteIssueKind classifies issues found by CheckRoutes.
+type RouteIssueKind string
+
+const (
+	// RouteIssueConflictingParams reports routes using different names for the same path parameter,
+	// e.g. /users/:id and /users/:name.
+	RouteIssueConflictingParams RouteIssueKind = "conflicting_params"
+	// RouteIssueShadowedWildcard reports parameters and catch-all wildcards that don't receive some requests,
+	// because a static route matches them first, e.g. /users/new shadows /users/:id.
+	RouteIssueShadowedWildcard RouteIssueKind = "shadowed_wildcard"
+	// RouteIssueTrailingSlash reports routes differing only by a trailing slash, e.g. /users and /users/.
+	RouteIssueTrailingSlash RouteIssueKind = "trailing_slash"
+)
+
+// RouteIssue is an issue found by CheckRoutes.
+type RouteIssue struct {
+	Kind RouteIssueKind
+	// Path is the route the issue was found on.
+	Path string
+	// Conflicting is the route causing the issue.
+	Conflicting string
+	// Message describes the issue and how to resolve it.
+	Message string
+}
+
+func (i RouteIssue) String() string {
+	return i.Message
+}
+
+// checkRoutes analyses the paths of all routes of the engine, including the routes of mounted engines.
+func (r *ginEngine) checkRoutes() []RouteIssue {
+	paths := make(map[string]bool)
+	r.collectPaths("", paths)
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	issues := make([]RouteIssue, 0)
+	issues = append(issues, conflictingParams(sorted)...)
+	issues = append(issues, shadowedWildcards(sorted)...)
+	issues = append(issues, trailingSlashes(sorted, paths)...)
+	return issues
+}
+
+// collectPaths records the paths of all routes. Mount points of engines are replaced by the routes of the engines.
+func (r *ginEngine) collectPaths(prefix string, paths map[string]bool) {
+	mounted := make(map[string]bool)
+	for _, m := range r.config.mounts {
+		mounted[mountPattern(m.prefix)] = true
+		m.engine.collectPaths(prefix+m.prefix, paths)
+	}
+	for _, route := range r.engine.Routes() {
+		if !mounted[route.Path] {
+			paths[prefix+route.Path] = true
+		}
+	}
+}
+
+// isWildcard reports whether a path segment is a parameter or a catch-all wildcard.
+func isWildcard(segment string) bool {
+	return strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*")
+}
+
+// normalizedPrefix returns the first n segments with wildcards replaced by their kind.
+func normalizedPrefix(segments []string, n int) string {
+	normalized := make([]string, n)
+	for i, s := range segments[:n] {
+		normalized[i] = s
+		if isWildcard(s) {
+			normalized[i] = s[:1]
+		}
+	}
+	return strings.Join(normalized, "/")
+}
+
+func conflictingParams(paths []string) []RouteIssue {
+	type param struct {
+		name string
+		path string
+	}
+	first := make(map[string]param)
+	reported := make(map[string]bool)
+	issues := make([]RouteIssue, 0)
+	for _, p := range paths {
+		segments := strings.Split(p, "/")
+		for i, s := range segments {
+			if !isWildcard(s) {
+				continue
+			}
+			key := normalizedPrefix(segments, i+1)
+			existing, ok := first[key]
+			if !ok {
+				first[key] = param{name: s, path: p}
+				continue
+			}
+			if existing.name == s || reported[key+s] {
+				continue
+			}
+			reported[key+s] = true
+			issues = append(issues, RouteIssue{
+				Kind:        RouteIssueConflictingParams,
+				Path:        p,
+				Conflicting: existing.path,
+				Message: fmt.Sprintf("%s names the parameter %s, but %s names it %s; use the same name in both routes",
+					p, s, existing.path, existing.name),
+			})
+		}
+	}
+	return issues
+}
+
+func shadowedWildcards(paths []string) []RouteIssue {
+	// statics maps normalized prefixes to the static segments following them
+	statics := make(map[string][]string)
+	for _, p := range paths {
+		segments := strings.Split(p, "/")
+		for i, s := range segments {
+			if len(s) > 0 && !isWildcard(s) {
+				key := normalizedPrefix(segments, i)
+				if !contains(statics[key], s) {
+					statics[key] = append(statics[key], s)
+				}
+			}
+		}
+	}
+	reported := make(map[string]bool)
+	issues := make([]RouteIssue, 0)
+	for _, p := range paths {
+		segments := strings.Split(p, "/")
+		for i, s := range segments {
+			if !isWildcard(s) {
+				continue
+			}
+			for _, static := range statics[normalizedPrefix(segments, i)] {
+				shadowing := strings.Join(append(append([]string(nil), segments[:i]...), static), "/")
+				if reported[p+shadowing] {
+					continue
+				}
+				reported[p+shadowing] = true
+				issues = append(issues, RouteIssue{
+					Kind:        RouteIssueShadowedWildcard,
+					Path:        p,
+					Conflicting: shadowing,
+					Message: fmt.Sprintf("%s is never matched for %s = %q, because routes below %s take precedence; rename one of the routes if this is unintended",
+						p, s, static, shadowing),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func trailingSlashes(paths []string, all map[string]bool) []RouteIssue {
+	issues := make([]RouteIssue, 0)
+	for _, p := range paths {
+		if p == "/" || !strings.HasSuffix(p, "/") {
+			continue
+		}
+		other := strings.TrimSuffix(p, "/")
+		if all[other] {
+			issues = append(issues, RouteIssue{
+				Kind:        RouteIssueTrailingSlash,
+				Path:        p,
+				Conflicting: other,
+				Message:     fmt.Sprintf("%s and %s differ only by a trailing slash; register all methods on one of them", p, other),
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"testing"
+)
+
+func TestEngine_CheckRoutes(t *testing.T) {
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	admin := New()
+	admin.GET("/users/:id", noop)
+	admin.DELETE("/users/:userId", noop)
+
+	e := New()
+	e.GET("/users/:id", noop)
+	e.GET("/users/new", noop)
+	e.GET("/orders", noop)
+	e.POST("/orders/", noop)
+	e.GET("/files/*path", noop)
+	e.Mount("/admin", admin)
+
+	issues := e.CheckRoutes()
+	expected := []RouteIssue{
+		{Kind: RouteIssueConflictingParams, Path: "/admin/users/:userId", Conflicting: "/admin/users/:id"},
+		{Kind: RouteIssueShadowedWildcard, Path: "/users/:id", Conflicting: "/users/new"},
+		{Kind: RouteIssueTrailingSlash, Path: "/orders/", Conflicting: "/orders"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatal("unexpected issues", issues)
+	}
+	for i, issue := range issues {
+		if issue.Kind != expected[i].Kind || issue.Path != expected[i].Path || issue.Conflicting != expected[i].Conflicting || len(issue.Message) == 0 {
+			t.Errorf("expected %v, got %v", expected[i], issue)
+		}
+	}
+}
+
+func TestEngine_CheckRoutes_NoIssues(t *testing.T) {
+	noop := func(c Context) {
+		c.RespondNoContent()
+	}
+	e := New()
+	e.GET("/users", noop)
+	e.GET("/users/:id", noop)
+	e.PUT("/users/:id", noop)
+	e.GET("/users/:id/orders/:orderId", noop)
+	e.Group("/api").GET("/items/:id", noop)
+	e.ExpandMethods()
+
+	if issues := e.CheckRoutes(); len(issues) > 0 {
+		t.Fatal("expected no issues, got", issues)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RouteInfo describes a route.
+type RouteInfo struct {
+	Name    string
+	Path    string
+	Methods []string
+	Meta    map[string]any
+	Headers []HeaderRequirement
+	Budget  RouteBudget
+}
+
+func (r *ginRoute) info() RouteInfo {
+	meta := make(map[string]any, len(r.meta))
+	for k, v := range r.meta {
+		meta[k] = v
+	}
+	return RouteInfo{
+		Name:    r.name,
+		Path:    r.path,
+		Methods: append([]string(nil), r.methods...),
+		Meta:    meta,
+		Headers: append([]HeaderRequirement(nil), r.headers...),
+		Budget:  r.budget,
+	}
+}
+
+func (cfg *engineConfig) nameRoute(route *ginRoute, name string) {
+	if len(route.name) > 0 {
+		panic(fmt.Sprintf("jug: route %s is already named %s", route.path, route.name))
+	}
+	if existing, ok := cfg.namedRoutes[name]; ok {
+		panic(fmt.Sprintf("jug: route name %s is already used by %s", name, existing.path))
+	}
+	route.name = name
+	cfg.namedRoutes[name] = route
+}
+
+// routeInfo looks up a named route of the engine or of its mounted engines.
+func (cfg *engineConfig) routeInfo(name string) (RouteInfo, bool) {
+	if route, ok := cfg.namedRoutes[name]; ok {
+		return route.info(), true
+	}
+	for _, m := range cfg.mounts {
+		if info, ok := m.engine.config.routeInfo(name); ok {
+			info.Path = joinPaths(m.prefix, info.Path)
+			return info, true
+		}
+	}
+	return RouteInfo{}, false
+}
+
+// routeInfos lists the routes of the engine and of its mounted engines.
+func (cfg *engineConfig) routeInfos() []RouteInfo {
+	infos := make([]RouteInfo, 0, len(cfg.routes))
+	for _, route := range cfg.routes {
+		infos = append(infos, route.info())
+	}
+	for _, m := range cfg.mounts {
+		for _, info := range m.engine.config.routeInfos() {
+			info.Path = joinPaths(m.prefix, info.Path)
+			infos = append(infos, info)
+		}
+	}
+	return infos
+}
+
+// buildURL replaces the path parameters of the named route with params in order of appearance.
+func (cfg *engineConfig) buildURL(name string, params ...string) (string, error) {
+	route, ok := cfg.routeInfo(name)
+	if !ok {
+		return "", fmt.Errorf("unknown route %s", name)
+	}
+	segments := strings.Split(route.Path, "/")
+	next := 0
+	for i, s := range segments {
+		if len(s) == 0 || (s[0] != ':' && s[0] != '*') {
+			continue
+		}
+		if next >= len(params) {
+			return "", fmt.Errorf("missing parameter %s for route %s", s[1:], name)
+		}
+		if s[0] == '*' {
+			segments[i] = strings.TrimPrefix(params[next], "/")
+		} else {
+			segments[i] = params[next]
+		}
+		next++
+	}
+	if next < len(params) {
+		return "", fmt.Errorf("route %s expects %d parameters, got %d", name, next, len(params))
+	}
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEngine_Route(t *testing.T) {
+	e := New()
+	noop := func(c Context) {}
+	e.Group("/users").GET("/:id", noop).Name("users.show").Meta("auth", "admin")
+	e.Static("/assets", ".").Name("assets")
+
+	route, ok := e.Route("users.show")
+	if !ok {
+		t.Fatal("expected named route")
+	}
+	expected := RouteInfo{Name: "users.show", Path: "/users/:id", Methods: []string{"GET"}, Meta: map[string]any{"auth": "admin"}}
+	if !reflect.DeepEqual(route, expected) {
+		t.Fatalf("expected %v, got %v", expected, route)
+	}
+	if _, ok := e.Route("unknown"); ok {
+		t.Fatal("expected unknown route to be missing")
+	}
+}
+
+func TestEngine_URL(t *testing.T) {
+	e := New()
+	noop := func(c Context) {}
+	e.GET("/users/:id/posts/:post", noop).Name("posts.show")
+	e.Static("/assets", ".").Name("assets")
+
+	tests := []struct {
+		name     string
+		params   []string
+		expected string
+		err      bool
+	}{
+		{"posts.show", []string{"1", "2"}, "/users/1/posts/2", false},
+		{"posts.show", []string{"1"}, "", true},
+		{"posts.show", []string{"1", "2", "3"}, "", true},
+		{"assets", []string{"/css/app.css"}, "/assets/css/app.css", false},
+		{"unknown", nil, "", true},
+	}
+	for _, tt := range tests {
+		url, err := e.URL(tt.name, tt.params...)
+		if (err != nil) != tt.err {
+			t.Fatalf("URL(%s, %v) error = %v, expected error %v", tt.name, tt.params, err, tt.err)
+		}
+		if url != tt.expected {
+			t.Fatalf("URL(%s, %v) = %s, expected %s", tt.name, tt.params, url, tt.expected)
+		}
+	}
+}
+
+func TestRouter_Name_Duplicate(t *testing.T) {
+	e := New()
+	noop := func(c Context) {}
+	e.GET("/a", noop).Name("a")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected duplicate name to panic")
+		}
+	}()
+	e.GET("/b", noop).Name("a")
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SchemaLimits holds the maximum lengths of storage columns, e.g. users.name -> 255.
+// Lengths are counted in characters, not bytes.
+type SchemaLimits struct {
+	mu      sync.RWMutex
+	columns map[string]int
+}
+
+// DefaultSchemaLimits is used by validators that have no limits of their own.
+var DefaultSchemaLimits = NewSchemaLimits()
+
+func NewSchemaLimits() *SchemaLimits {
+	return &SchemaLimits{
+		columns: make(map[string]int),
+	}
+}
+
+// Register registers the maximum length of a column.
+func (l *SchemaLimits) Register(column string, maxLength int) *SchemaLimits {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.columns[column] = maxLength
+	return l
+}
+
+// Limit gets the maximum length of a column.
+func (l *SchemaLimits) Limit(column string) (int, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	limit, ok := l.columns[column]
+	return limit, ok
+}
+
+// unknownColumnError is returned by validators referencing a column without registered limit.
+// It is a configuration error and answered with 500.
+type unknownColumnError struct {
+	column string
+}
+
+func (e *unknownColumnError) Error() string {
+	return fmt.Sprintf("jug: no schema limit registered for column %s", e.column)
+}
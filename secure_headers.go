@@ -0,0 +1,188 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SecureConfig configures the SecureHeaders middleware. Zero values disable the respective header.
+type SecureConfig struct {
+	// HSTSMaxAge sets the max-age of the Strict-Transport-Security header. It is only sent for HTTPS requests.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	HSTSPreload           bool
+	// ContentTypeNosniff sets X-Content-Type-Options to nosniff.
+	ContentTypeNosniff bool
+	// FrameOptions sets X-Frame-Options, e.g. DENY or SAMEORIGIN.
+	FrameOptions string
+	// ReferrerPolicy sets Referrer-Policy, e.g. strict-origin-when-cross-origin.
+	ReferrerPolicy string
+	// ContentSecurityPolicy sets Content-Security-Policy.
+	ContentSecurityPolicy *CSP
+	// CSPReportOnly sends the policy as Content-Security-Policy-Report-Only.
+	CSPReportOnly bool
+}
+
+// DefaultSecureConfig returns the configuration used by Default: HSTS for one year including subdomains,
+// nosniff, DENY framing and the strict-origin-when-cross-origin referrer policy. No CSP is set.
+func DefaultSecureConfig() SecureConfig {
+	return SecureConfig{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "strict-origin-when-cross-origin",
+	}
+}
+
+// SecureHeaders returns a middleware that sets security related response headers.
+// Handlers can override the headers.
+func SecureHeaders(config SecureConfig) HandlerFunc {
+	headers := make(map[string]string)
+	if config.ContentTypeNosniff {
+		headers["X-Content-Type-Options"] = "nosniff"
+	}
+	if len(config.FrameOptions) > 0 {
+		headers["X-Frame-Options"] = config.FrameOptions
+	}
+	if len(config.ReferrerPolicy) > 0 {
+		headers["Referrer-Policy"] = config.ReferrerPolicy
+	}
+	if config.ContentSecurityPolicy != nil {
+		key := "Content-Security-Policy"
+		if config.CSPReportOnly {
+			key = "Content-Security-Policy-Report-Only"
+		}
+		headers[key] = config.ContentSecurityPolicy.String()
+	}
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(config.HSTSMaxAge/time.Second), 10)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+	return func(c Context) {
+		for k, v := range headers {
+			c.SetHeader(k, v)
+		}
+		if len(hsts) > 0 && isHTTPS(c) {
+			c.SetHeader("Strict-Transport-Security", hsts)
+		}
+	}
+}
+
+func isHTTPS(c Context) bool {
+	return c.ConnInfo().TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+}
+
+// Content security policy source expressions.
+const (
+	CSPSelf          = "'self'"
+	CSPNone          = "'none'"
+	CSPUnsafeInline  = "'unsafe-inline'"
+	CSPUnsafeEval    = "'unsafe-eval'"
+	CSPStrictDynamic = "'strict-dynamic'"
+)
+
+// CSP builds a Content-Security-Policy. Adding sources to an existing directive merges them.
+type CSP struct {
+	directives []cspDirective
+}
+
+type cspDirective struct {
+	name    string
+	sources []string
+}
+
+// NewCSP creates an empty policy.
+func NewCSP() *CSP {
+	return &CSP{}
+}
+
+// Add adds sources to a directive. Directives keep the order they were added in, duplicate sources are ignored.
+func (p *CSP) Add(directive string, sources ...string) *CSP {
+	for i := range p.directives {
+		if p.directives[i].name == directive {
+			for _, s := range sources {
+				if !contains(p.directives[i].sources, s) {
+					p.directives[i].sources = append(p.directives[i].sources, s)
+				}
+			}
+			return p
+		}
+	}
+	p.directives = append(p.directives, cspDirective{name: directive})
+	return p.Add(directive, sources...)
+}
+
+// Merge adds all directives of other to the policy.
+func (p *CSP) Merge(other *CSP) *CSP {
+	for _, d := range other.directives {
+		p.Add(d.name, d.sources...)
+	}
+	return p
+}
+
+// Clone returns a copy of the policy, so that it can be extended without changing the original.
+func (p *CSP) Clone() *CSP {
+	return NewCSP().Merge(p)
+}
+
+func (p *CSP) DefaultSrc(sources ...string) *CSP {
+	return p.Add("default-src", sources...)
+}
+
+func (p *CSP) ScriptSrc(sources ...string) *CSP {
+	return p.Add("script-src", sources...)
+}
+
+func (p *CSP) StyleSrc(sources ...string) *CSP {
+	return p.Add("style-src", sources...)
+}
+
+func (p *CSP) ImgSrc(sources ...string) *CSP {
+	return p.Add("img-src", sources...)
+}
+
+func (p *CSP) ConnectSrc(sources ...string) *CSP {
+	return p.Add("connect-src", sources...)
+}
+
+func (p *CSP) FontSrc(sources ...string) *CSP {
+	return p.Add("font-src", sources...)
+}
+
+func (p *CSP) ObjectSrc(sources ...string) *CSP {
+	return p.Add("object-src", sources...)
+}
+
+func (p *CSP) FrameAncestors(sources ...string) *CSP {
+	return p.Add("frame-ancestors", sources...)
+}
+
+// ReportURI sets the URI violations are reported to.
+func (p *CSP) ReportURI(uri string) *CSP {
+	return p.Add("report-uri", uri)
+}
+
+// UpgradeInsecureRequests instructs browsers to load HTTP resources over HTTPS.
+func (p *CSP) UpgradeInsecureRequests() *CSP {
+	return p.Add("upgrade-insecure-requests")
+}
+
+func (p *CSP) String() string {
+	parts := make([]string, len(p.directives))
+	for i, d := range p.directives {
+		parts[i] = strings.TrimSpace(d.name + " " + strings.Join(d.sources, " "))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	e := Default()
+	e.GET("/", func(c Context) {
+		c.RespondNoContent()
+	})
+	e.GET("/embeddable", func(c Context) {
+		c.SetHeader("X-Frame-Options", "SAMEORIGIN")
+		c.RespondNoContent()
+	})
+
+	w := serve(e, http.MethodGet, "/")
+	expected := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Strict-Transport-Security": "",
+	}
+	for k, v := range expected {
+		if w.Header().Get(k) != v {
+			t.Errorf("expected %s to be %q, got %q", k, v, w.Header().Get(k))
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	w = serveRequest(e, r)
+	if h := w.Header().Get("Strict-Transport-Security"); h != "max-age=31536000; includeSubDomains" {
+		t.Error("expected HSTS for HTTPS requests, got", h)
+	}
+
+	if h := serve(e, http.MethodGet, "/embeddable").Header().Get("X-Frame-Options"); h != "SAMEORIGIN" {
+		t.Error("expected handler to override header, got", h)
+	}
+}
+
+func TestSecureHeaders_CSP(t *testing.T) {
+	base := NewCSP().DefaultSrc(CSPSelf).ObjectSrc(CSPNone)
+	policy := base.Clone().ScriptSrc(CSPSelf, "https://cdn.example.com").DefaultSrc(CSPSelf, "https://api.example.com").UpgradeInsecureRequests()
+	if s := base.String(); s != "default-src 'self'; object-src 'none'" {
+		t.Fatal("expected clone not to change the original, got", s)
+	}
+	expected := "default-src 'self' https://api.example.com; object-src 'none'; script-src 'self' https://cdn.example.com; upgrade-insecure-requests"
+	if s := policy.String(); s != expected {
+		t.Fatalf("expected %s, got %s", expected, s)
+	}
+
+	e := New()
+	e.Use(SecureHeaders(SecureConfig{ContentSecurityPolicy: policy, CSPReportOnly: true}))
+	e.GET("/", func(c Context) {})
+	w := serve(e, http.MethodGet, "/")
+	if h := w.Header().Get("Content-Security-Policy-Report-Only"); h != expected {
+		t.Fatal("expected report only policy, got", h)
+	}
+	if h := w.Header().Get("X-Frame-Options"); h != "" {
+		t.Fatal("expected zero config to omit headers, got", h)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ServiceOption configures the registration of a service, see RegisterService.
+type ServiceOption func(cfg *serviceConfig)
+
+type serviceConfig struct {
+	prefix     string
+	path       func(method string) string
+	middleware []HandlerFunc
+	mapError   func(err error) error
+}
+
+// ServicePrefix sets the path prefix of the service routes. Defaults to a slash followed by the type name of the service.
+func ServicePrefix(prefix string) ServiceOption {
+	return func(cfg *serviceConfig) {
+		cfg.prefix = prefix
+	}
+}
+
+// ServicePath sets the function mapping method names to paths relative to the prefix.
+// Defaults to a slash followed by the method name.
+func ServicePath(path func(method string) string) ServiceOption {
+	return func(cfg *serviceConfig) {
+		cfg.path = path
+	}
+}
+
+// ServiceMiddleware adds middleware to all routes of the service.
+func ServiceMiddleware(middleware ...HandlerFunc) ServiceOption {
+	return func(cfg *serviceConfig) {
+		cfg.middleware = append(cfg.middleware, middleware...)
+	}
+}
+
+// ServiceErrorMapper sets a function translating the errors returned by service methods before they are passed to HandleError,
+// e.g. to map domain errors to ResponseStatusError.
+func ServiceErrorMapper(mapError func(err error) error) ServiceOption {
+	return func(cfg *serviceConfig) {
+		cfg.mapError = mapError
+	}
+}
+
+var (
+	contextInterface = reflect.TypeOf((*Context)(nil)).Elem()
+	errorInterface   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RegisterService registers the exported methods of svc as POST routes, e.g. POST /UserService/Create.
+//
+// Methods take a context.Context or a Context and optionally a request, and return an error, optionally preceded by a response:
+//
+//	func (s *UserService) Create(ctx context.Context, req *CreateUserRequest) (*User, error)
+//	func (s *UserService) Delete(ctx context.Context, req DeleteUserRequest) error
+//	func (s *UserService) List(ctx context.Context) ([]User, error)
+//
+// Requests are bound with Context.MustBindBody and validated. Responses are written with Context.RespondOk,
+// methods without response respond with 204. Errors are passed to Context.HandleError.
+// Methods with other signatures are skipped. RegisterService panics if svc has no matching methods.
+func RegisterService(r RouterGroup, svc any, opts ...ServiceOption) {
+	v := reflect.ValueOf(svc)
+	if !v.IsValid() {
+		panic("jug: service must not be nil")
+	}
+	t := v.Type()
+	name := t.Name()
+	if t.Kind() == reflect.Pointer {
+		name = t.Elem().Name()
+	}
+	cfg := &serviceConfig{
+		prefix: "/" + name,
+		path: func(method string) string {
+			return "/" + method
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	registered := 0
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		handler, ok := newServiceHandler(v.Method(i), cfg)
+		if !ok {
+			continue
+		}
+		handlers := append(append([]HandlerFunc{}, cfg.middleware...), handler)
+		r.POST(cfg.prefix+cfg.path(m.Name), handlers...)
+		registered++
+	}
+	if registered == 0 {
+		panic(fmt.Sprintf("jug: service %s has no methods to register", t))
+	}
+}
+
+// newServiceHandler returns a handler invoking method. It returns false if the method has an unsupported signature.
+func newServiceHandler(method reflect.Value, cfg *serviceConfig) (HandlerFunc, bool) {
+	mt := method.Type()
+	if mt.IsVariadic() || mt.NumIn() < 1 || mt.NumIn() > 2 || mt.NumOut() < 1 || mt.NumOut() > 2 {
+		return nil, false
+	}
+	if !contextInterface.AssignableTo(mt.In(0)) || mt.Out(mt.NumOut()-1) != errorInterface {
+		return nil, false
+	}
+	var reqType reflect.Type
+	if mt.NumIn() == 2 {
+		reqType = mt.In(1)
+		base := reqType
+		if base.Kind() == reflect.Pointer {
+			base = base.Elem()
+		}
+		if base.Kind() != reflect.Struct && base.Kind() != reflect.Map && base.Kind() != reflect.Slice {
+			return nil, false
+		}
+	}
+	hasResult := mt.NumOut() == 2
+	return func(c Context) {
+		args := []reflect.Value{reflect.ValueOf(c)}
+		if reqType != nil {
+			req, ok := bindServiceRequest(c, reqType)
+			if !ok {
+				return
+			}
+			args = append(args, req)
+		}
+		out := method.Call(args)
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			if cfg.mapError != nil {
+				err = cfg.mapError(err)
+			}
+			c.HandleError(err)
+			return
+		}
+		if !hasResult {
+			c.RespondNoContent()
+			return
+		}
+		c.RespondOk(out[0].Interface())
+	}, true
+}
+
+// bindServiceRequest binds the request body to a new value of type t.
+func bindServiceRequest(c Context, t reflect.Type) (reflect.Value, bool) {
+	if t.Kind() == reflect.Pointer {
+		req := reflect.New(t.Elem())
+		return req, c.MustBindBody(req.Interface())
+	}
+	req := reflect.New(t)
+	return req.Elem(), c.MustBindBody(req.Interface())
+}
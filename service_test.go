@@ -0,0 +1,112 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type createGreetingRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type greeting struct {
+	Message string `json:"message"`
+}
+
+var errBlocked = errors.New("blocked")
+
+type GreeterService struct {
+	deleted string
+}
+
+func (s *GreeterService) Greet(ctx context.Context, req *createGreetingRequest) (*greeting, error) {
+	if req.Name == "mallory" {
+		return nil, errBlocked
+	}
+	return &greeting{Message: "hello " + req.Name}, nil
+}
+
+func (s *GreeterService) Delete(c Context, req createGreetingRequest) error {
+	s.deleted = req.Name
+	return nil
+}
+
+func (s *GreeterService) List(ctx context.Context) ([]greeting, error) {
+	return []greeting{{Message: "hello"}}, nil
+}
+
+func (s *GreeterService) Helper(name string) string {
+	return name
+}
+
+func postService(e Engine, path string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return serveRequest(e, r)
+}
+
+func TestRegisterService(t *testing.T) {
+	svc := &GreeterService{}
+	e := New()
+	RegisterService(e.Group("/rpc"), svc, ServiceErrorMapper(func(err error) error {
+		if errors.Is(err, errBlocked) {
+			return NewForbiddenError(err.Error())
+		}
+		return err
+	}))
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		res    string
+	}{
+		{"/rpc/GreeterService/Greet", `{"name":"jug"}`, http.StatusOK, `{"message":"hello jug"}`},
+		{"/rpc/GreeterService/Greet", `{}`, http.StatusBadRequest, `{"error":"name is required"}`},
+		{"/rpc/GreeterService/Greet", `{"name":"mallory"}`, http.StatusForbidden, `{"error":"blocked"}`},
+		{"/rpc/GreeterService/Delete", `{"name":"jug"}`, http.StatusNoContent, ``},
+		{"/rpc/GreeterService/List", ``, http.StatusOK, `[{"message":"hello"}]`},
+	}
+	for _, tt := range tests {
+		w := postService(e, tt.path, tt.body)
+		if w.Code != tt.status || w.Body.String() != tt.res {
+			t.Fatalf("%s %s: expected %d %s, got %d %s", tt.path, tt.body, tt.status, tt.res, w.Code, w.Body.String())
+		}
+	}
+	if svc.deleted != "jug" {
+		t.Fatal("expected value request to be bound, got", svc.deleted)
+	}
+	if w := postService(e, "/rpc/GreeterService/Helper", `{}`); w.Code != http.StatusNotFound {
+		t.Fatal("expected unsupported methods to be skipped, got", w.Code)
+	}
+}
+
+func TestRegisterService_Options(t *testing.T) {
+	e := New()
+	RegisterService(e, &GreeterService{},
+		ServicePrefix("/greeter"),
+		ServicePath(func(method string) string { return "/" + strings.ToLower(method) }),
+		ServiceMiddleware(func(c Context) { c.SetHeader("X-Service", "greeter") }),
+	)
+	w := postService(e, "/greeter/list", ``)
+	if w.Code != http.StatusOK || w.Header().Get("X-Service") != "greeter" {
+		t.Fatal("expected mapped path and middleware, got", w.Code, w.Header().Get("X-Service"))
+	}
+}
+
+func TestRegisterService_NoMethods(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for service without methods")
+		}
+	}()
+	RegisterService(New(), struct{}{})
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+const sessionKey = "jug.session"
+
+// ErrSessionTooLarge is returned when a session does not fit into a cookie.
+var ErrSessionTooLarge = errors.New("session too large")
+
+// SessionStore loads and saves sessions.
+type SessionStore interface {
+	// Load returns the values of the session of a request. It returns nil values if there is no valid session.
+	Load(c Context) (map[string]any, error)
+	// Save persists the values of the session and writes the session cookie. Saving nil values deletes the session.
+	Save(c Context, values map[string]any) error
+}
+
+// SessionRegenerator is implemented by session stores identifying sessions by id, see Session.Regenerate.
+type SessionRegenerator interface {
+	// Regenerate persists the values under a new session id, deletes the previous session and writes the cookie.
+	Regenerate(c Context, values map[string]any) error
+}
+
+// SessionOptions configures the session cookie.
+type SessionOptions struct {
+	// CookieName is the name of the session cookie. Defaults to "session".
+	CookieName string
+	// Path is the cookie path. Defaults to "/".
+	Path string
+	// Domain is the cookie domain.
+	Domain string
+	// MaxAge is the lifetime of a session. Defaults to 24 hours.
+	MaxAge time.Duration
+	// Secure restricts the cookie to HTTPS.
+	Secure bool
+	// SameSite is the SameSite attribute of the cookie. Defaults to http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+func (o SessionOptions) withDefaults() SessionOptions {
+	if len(o.CookieName) == 0 {
+		o.CookieName = "session"
+	}
+	if len(o.Path) == 0 {
+		o.Path = "/"
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = 24 * time.Hour
+	}
+	if o.SameSite == 0 {
+		o.SameSite = http.SameSiteLaxMode
+	}
+	return o
+}
+
+// cookie creates the session cookie. An empty value deletes the cookie.
+func (o SessionOptions) cookie(value string) *http.Cookie {
+	maxAge := int(o.MaxAge.Seconds())
+	if len(value) == 0 {
+		maxAge = -1
+	}
+	return &http.Cookie{
+		Name:     o.CookieName,
+		Value:    value,
+		Path:     o.Path,
+		Domain:   o.Domain,
+		MaxAge:   maxAge,
+		Secure:   o.Secure,
+		HttpOnly: true,
+		SameSite: o.SameSite,
+	}
+}
+
+// Session holds the values of a session. Values are encoded as JSON, so numbers are read back as float64.
+// Changes are persisted by Save.
+type Session struct {
+	c      Context
+	store  SessionStore
+	values map[string]any
+	loaded bool
+}
+
+// Sessions returns a middleware that provides sessions backed by the given store. Use Context.Session to access them.
+func Sessions(store SessionStore) HandlerFunc {
+	return func(c Context) {
+		c.Set(sessionKey, &Session{c: c, store: store})
+	}
+}
+
+// load loads the session lazily. Invalid sessions are treated as new sessions.
+func (s *Session) load() {
+	if s.loaded {
+		return
+	}
+	s.loaded = true
+	values, err := s.store.Load(s.c)
+	if err != nil || values == nil {
+		values = make(map[string]any)
+	}
+	s.values = values
+}
+
+// Get gets a session value.
+func (s *Session) Get(key string) (any, bool) {
+	s.load()
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// Set sets a session value.
+func (s *Session) Set(key string, value any) {
+	s.load()
+	s.values[key] = value
+}
+
+// Delete deletes a session value.
+func (s *Session) Delete(key string) {
+	s.load()
+	delete(s.values, key)
+}
+
+// Clear deletes all session values. Saving a cleared session deletes the session.
+func (s *Session) Clear() {
+	s.loaded = true
+	s.values = make(map[string]any)
+}
+
+// Save persists the session. It must be called before the response is written.
+func (s *Session) Save() error {
+	s.load()
+	if len(s.values) == 0 {
+		return s.store.Save(s.c, nil)
+	}
+	return s.store.Save(s.c, s.values)
+}
+
+// Regenerate persists the session under a new id and deletes the previous session. Call it when the privileges of
+// the session change, e.g. after login, to prevent session fixation. Stores without session ids, like
+// CookieSessionStore, save the session.
+func (s *Session) Regenerate() error {
+	s.load()
+	if r, ok := s.store.(SessionRegenerator); ok {
+		return r.Regenerate(s.c, s.values)
+	}
+	return s.Save()
+}
@@ -0,0 +1,280 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCookieSize is the maximum size of a cookie value browsers are guaranteed to accept.
+const maxCookieSize = 4096
+
+var errInvalidSessionCookie = errors.New("invalid session cookie")
+
+// deriveKey derives a purpose specific key from a secret.
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// CookieSessionStore keeps session values in an encrypted and authenticated cookie.
+type CookieSessionStore struct {
+	options SessionOptions
+	aead    cipher.AEAD
+}
+
+type cookieSession struct {
+	Expires int64          `json:"e"`
+	Values  map[string]any `json:"v"`
+}
+
+// NewCookieSessionStore creates a store keeping sessions in cookies encrypted with AES-GCM.
+// The key should be at least 32 random bytes. Sessions must fit into a cookie of 4 KB.
+func NewCookieSessionStore(key []byte, options SessionOptions) *CookieSessionStore {
+	block, err := aes.NewCipher(deriveKey(key, "jug session encryption"))
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &CookieSessionStore{
+		options: options.withDefaults(),
+		aead:    aead,
+	}
+}
+
+func (s *CookieSessionStore) Load(c Context) (map[string]any, error) {
+	raw, ok := c.Cookie(s.options.CookieName)
+	if !ok {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil || len(data) < s.aead.NonceSize() {
+		return nil, errInvalidSessionCookie
+	}
+	nonce, ciphertext := data[:s.aead.NonceSize()], data[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, []byte(s.options.CookieName))
+	if err != nil {
+		return nil, errInvalidSessionCookie
+	}
+	var session cookieSession
+	if err := json.Unmarshal(plaintext, &session); err != nil {
+		return nil, err
+	}
+	if time.Now().Unix() > session.Expires {
+		return nil, nil
+	}
+	return session.Values, nil
+}
+
+func (s *CookieSessionStore) Save(c Context, values map[string]any) error {
+	if values == nil {
+		c.SetHTTPCookie(s.options.cookie(""))
+		return nil
+	}
+	plaintext, err := json.Marshal(cookieSession{
+		Expires: time.Now().Add(s.options.MaxAge).Unix(),
+		Values:  values,
+	})
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	value := base64.RawURLEncoding.EncodeToString(s.aead.Seal(nonce, nonce, plaintext, []byte(s.options.CookieName)))
+	if len(value) > maxCookieSize {
+		return ErrSessionTooLarge
+	}
+	c.SetHTTPCookie(s.options.cookie(value))
+	return nil
+}
+
+// SessionBackend stores session values by id, e.g. in memory, a database or Redis.
+type SessionBackend interface {
+	// Get gets the values of a session. It returns nil values if the session does not exist or has expired.
+	Get(id string) (map[string]any, error)
+	// Set stores the values of a session for the given time.
+	Set(id string, values map[string]any, ttl time.Duration) error
+	// Delete deletes a session.
+	Delete(id string) error
+}
+
+// ServerSessionStore keeps session values in a backend. The cookie only holds a signed session id.
+type ServerSessionStore struct {
+	options SessionOptions
+	key     []byte
+	backend SessionBackend
+}
+
+// NewServerSessionStore creates a store keeping sessions in the given backend.
+// The key signs session ids and should be at least 32 random bytes.
+func NewServerSessionStore(key []byte, backend SessionBackend, options SessionOptions) *ServerSessionStore {
+	return &ServerSessionStore{
+		options: options.withDefaults(),
+		key:     deriveKey(key, "jug session signing"),
+		backend: backend,
+	}
+}
+
+func (s *ServerSessionStore) sign(id string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// regeneratedSessionKey holds the session id issued by Regenerate during a request.
+var regeneratedSessionKey = Key[string]("jug.regeneratedSession")
+
+// sessionID returns the verified session id of a request, or the id issued by Regenerate.
+func (s *ServerSessionStore) sessionID(c Context) (string, bool) {
+	if id, ok := regeneratedSessionKey.Get(c); ok {
+		return id, true
+	}
+	raw, ok := c.Cookie(s.options.CookieName)
+	if !ok {
+		return "", false
+	}
+	id, _, ok := strings.Cut(raw, ".")
+	if !ok || !hmac.Equal([]byte(s.sign(id)), []byte(raw)) {
+		return "", false
+	}
+	return id, true
+}
+
+func (s *ServerSessionStore) Load(c Context) (map[string]any, error) {
+	id, ok := s.sessionID(c)
+	if !ok {
+		return nil, nil
+	}
+	return s.backend.Get(id)
+}
+
+func (s *ServerSessionStore) Save(c Context, values map[string]any) error {
+	id, ok := s.sessionID(c)
+	if values == nil {
+		if ok {
+			if err := s.backend.Delete(id); err != nil {
+				return err
+			}
+		}
+		c.SetHTTPCookie(s.options.cookie(""))
+		return nil
+	}
+	if !ok {
+		var err error
+		if id, err = newSessionID(); err != nil {
+			return err
+		}
+	}
+	if err := s.backend.Set(id, values, s.options.MaxAge); err != nil {
+		return err
+	}
+	c.SetHTTPCookie(s.options.cookie(s.sign(id)))
+	return nil
+}
+
+// Regenerate stores the values under a new session id and deletes the previous session.
+func (s *ServerSessionStore) Regenerate(c Context, values map[string]any) error {
+	id, err := newSessionID()
+	if err != nil {
+		return err
+	}
+	if err := s.backend.Set(id, values, s.options.MaxAge); err != nil {
+		return err
+	}
+	if previous, ok := s.sessionID(c); ok {
+		if err := s.backend.Delete(previous); err != nil {
+			return err
+		}
+	}
+	regeneratedSessionKey.Set(c, id)
+	c.SetHTTPCookie(s.options.cookie(s.sign(id)))
+	return nil
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// MemorySessionBackend keeps sessions in memory. Sessions are lost on restart and not shared between instances.
+// Expired sessions are removed when sessions are stored, at most once a minute.
+type MemorySessionBackend struct {
+	mu       sync.Mutex
+	sessions map[string]memorySession
+	swept    time.Time
+}
+
+type memorySession struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemorySessionBackend creates an in-memory session backend.
+func NewMemorySessionBackend() *MemorySessionBackend {
+	return &MemorySessionBackend{
+		sessions: make(map[string]memorySession),
+	}
+}
+
+func (b *MemorySessionBackend) Get(id string) (map[string]any, error) {
+	b.mu.Lock()
+	s, ok := b.sessions[id]
+	b.mu.Unlock()
+	if !ok || time.Now().After(s.expires) {
+		return nil, nil
+	}
+	var values map[string]any
+	if err := json.Unmarshal(s.data, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (b *MemorySessionBackend) Set(id string, values map[string]any, ttl time.Duration) error {
+	// values are stored encoded, so that they behave like those of other backends
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if now.Sub(b.swept) > time.Minute {
+		for k, s := range b.sessions {
+			if now.After(s.expires) {
+				delete(b.sessions, k)
+			}
+		}
+		b.swept = now
+	}
+	b.sessions[id] = memorySession{data: data, expires: now.Add(ttl)}
+	return nil
+}
+
+func (b *MemorySessionBackend) Delete(id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.sessions, id)
+	return nil
+}
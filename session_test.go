@@ -0,0 +1,147 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func sessionTestEngine(store SessionStore) Engine {
+	e := New()
+	e.Use(Sessions(store))
+	e.POST("/login", func(c Context) {
+		c.Session().Set("user", "alice")
+		if err := c.Session().Save(); err != nil {
+			c.RespondInternalServerErrorE(err)
+			return
+		}
+		c.RespondNoContent()
+	})
+	e.GET("/me", func(c Context) {
+		user, ok := c.Session().Get("user")
+		if !ok {
+			c.RespondUnauthorized("not logged in")
+			return
+		}
+		c.String(http.StatusOK, fmt.Sprint(user))
+	})
+	e.POST("/logout", func(c Context) {
+		c.Session().Clear()
+		_ = c.Session().Save()
+		c.RespondNoContent()
+	})
+	return e
+}
+
+func serveSession(e Engine, method string, path string, cookie *http.Cookie) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	return serveRequest(e, r)
+}
+
+func testSessionStore(t *testing.T, store SessionStore) {
+	e := sessionTestEngine(store)
+	if w := serveSession(e, http.MethodGet, "/me", nil); w.Code != http.StatusUnauthorized {
+		t.Fatal("expected no session, got", w.Code)
+	}
+	w := serveSession(e, http.MethodPost, "/login", nil)
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatal("expected an http only session cookie, got", cookies)
+	}
+	cookie := cookies[0]
+	if w := serveSession(e, http.MethodGet, "/me", cookie); w.Body.String() != "alice" {
+		t.Fatal("expected session value, got", w.Code, w.Body.String())
+	}
+
+	tampered := *cookie
+	tampered.Value = "x" + cookie.Value[1:]
+	if cookie.Value[0] == 'x' {
+		tampered.Value = "y" + cookie.Value[1:]
+	}
+	if w := serveSession(e, http.MethodGet, "/me", &tampered); w.Code != http.StatusUnauthorized {
+		t.Fatal("expected tampered cookie to be rejected, got", w.Code)
+	}
+
+	w = serveSession(e, http.MethodPost, "/logout", cookie)
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatal("expected session cookie to be deleted, got", cookies)
+	}
+}
+
+func TestCookieSessionStore(t *testing.T) {
+	testSessionStore(t, NewCookieSessionStore([]byte("0123456789abcdef0123456789abcdef"), SessionOptions{}))
+}
+
+func TestServerSessionStore(t *testing.T) {
+	backend := NewMemorySessionBackend()
+	testSessionStore(t, NewServerSessionStore([]byte("0123456789abcdef0123456789abcdef"), backend, SessionOptions{}))
+	if len(backend.sessions) != 0 {
+		t.Fatal("expected session to be deleted from the backend, got", backend.sessions)
+	}
+}
+
+func TestSession_Regenerate(t *testing.T) {
+	backend := NewMemorySessionBackend()
+	e := New()
+	e.Use(Sessions(NewServerSessionStore([]byte("0123456789abcdef0123456789abcdef"), backend, SessionOptions{})))
+	e.POST("/visit", func(c Context) {
+		c.Session().Set("cart", "1")
+		_ = c.Session().Save()
+		c.RespondNoContent()
+	})
+	e.POST("/login", func(c Context) {
+		c.Session().Set("user", "alice")
+		if err := c.Session().Regenerate(); err != nil {
+			c.RespondInternalServerErrorE(err)
+			return
+		}
+		c.Session().Set("role", "admin")
+		_ = c.Session().Save()
+		c.RespondNoContent()
+	})
+	e.GET("/me", func(c Context) {
+		user, _ := c.Session().Get("user")
+		role, _ := c.Session().Get("role")
+		cart, _ := c.Session().Get("cart")
+		c.String(http.StatusOK, "%v %v %v", user, role, cart)
+	})
+
+	anonymous := serveSession(e, http.MethodPost, "/visit", nil).Result().Cookies()[0]
+	cookies := serveSession(e, http.MethodPost, "/login", anonymous).Result().Cookies()
+	session := cookies[len(cookies)-1]
+	if session.Value == anonymous.Value {
+		t.Fatal("expected a new session id")
+	}
+	if w := serveSession(e, http.MethodGet, "/me", session); w.Body.String() != "alice admin 1" {
+		t.Error("expected the values under the new id, got", w.Body.String())
+	}
+	if w := serveSession(e, http.MethodGet, "/me", anonymous); w.Body.String() != "<nil> <nil> <nil>" {
+		t.Error("expected the previous session to be deleted, got", w.Body.String())
+	}
+	if len(backend.sessions) != 1 {
+		t.Error("expected one session in the backend, got", len(backend.sessions))
+	}
+}
+
+func TestMemorySessionBackend_Sweep(t *testing.T) {
+	b := NewMemorySessionBackend()
+	_ = b.Set("expired", map[string]any{"a": 1}, -time.Second)
+	_ = b.Set("active", map[string]any{"a": 1}, time.Hour)
+	if _, ok := b.sessions["expired"]; !ok {
+		t.Fatal("expected sessions to be swept at most once a minute")
+	}
+	b.swept = time.Now().Add(-2 * time.Minute)
+	_ = b.Set("active", map[string]any{"a": 1}, time.Hour)
+	if _, ok := b.sessions["expired"]; ok {
+		t.Fatal("expected expired sessions to be swept")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrShuttingDown is returned by long-lived responses that were ended because the engine is shutting down.
+var ErrShuttingDown = errors.New("engine is shutting down")
+
+// shutdownSignal notifies long-lived connections about a shutdown and keeps track of WebSocket connections,
+// which are not covered by the graceful shutdown of the http server.
+type shutdownSignal struct {
+	done    chan struct{}
+	once    sync.Once
+	mu      sync.Mutex
+	conns   map[*wsConn]struct{}
+	drained chan struct{}
+}
+
+func newShutdownSignal() *shutdownSignal {
+	return &shutdownSignal{
+		done:  make(chan struct{}),
+		conns: make(map[*wsConn]struct{}),
+	}
+}
+
+// Done returns a channel that is closed when the shutdown starts.
+func (s *shutdownSignal) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *shutdownSignal) trigger() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *shutdownSignal) track(c *wsConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conns[c] = struct{}{}
+}
+
+func (s *shutdownSignal) untrack(c *wsConn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, c)
+	if len(s.conns) == 0 && s.drained != nil {
+		close(s.drained)
+		s.drained = nil
+	}
+}
+
+// wait waits until all tracked connections are closed. If ctx is done first, the remaining connections are closed forcibly.
+func (s *shutdownSignal) wait(ctx context.Context) error {
+	s.mu.Lock()
+	if len(s.conns) == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	drained := make(chan struct{})
+	s.drained = drained
+	s.mu.Unlock()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		conns := make([]*wsConn, 0, len(s.conns))
+		for c := range s.conns {
+			conns = append(conns, c)
+		}
+		s.mu.Unlock()
+		for _, c := range conns {
+			_ = c.conn.Close()
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a server sent event.
+type Event struct {
+	// ID sets the event id. Clients send the last received id in the Last-Event-ID header when reconnecting.
+	ID string
+	// Event sets the event type.
+	Event string
+	// Data is the event payload. Strings and byte slices are written as is, other values are marshalled to JSON.
+	Data any
+	// Retry sets the reconnection time of the client.
+	Retry time.Duration
+}
+
+// writeEvent writes an event in the text/event-stream format.
+func writeEvent(w io.Writer, e Event) error {
+	var sb strings.Builder
+	if len(e.ID) > 0 {
+		sb.WriteString("id: " + stripNewlines(e.ID) + "\n")
+	}
+	if len(e.Event) > 0 {
+		sb.WriteString("event: " + stripNewlines(e.Event) + "\n")
+	}
+	if e.Retry > 0 {
+		sb.WriteString(fmt.Sprintf("retry: %d\n", e.Retry.Milliseconds()))
+	}
+	data, err := eventData(e.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(data, "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func eventData(data any) (string, error) {
+	switch d := data.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return d, nil
+	case []byte:
+		return string(d), nil
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
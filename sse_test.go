@@ -0,0 +1,52 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteEvent(t *testing.T) {
+	tests := []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Data: "hello"}, "data: hello\n\n"},
+		{Event{Data: "a\nb"}, "data: a\ndata: b\n\n"},
+		{Event{ID: "7", Event: "update", Retry: 3 * time.Second, Data: []byte("x")}, "id: 7\nevent: update\nretry: 3000\ndata: x\n\n"},
+		{Event{Data: map[string]int{"n": 1}}, "data: {\"n\":1}\n\n"},
+		{Event{ID: "a\nb"}, "id: ab\ndata: \n\n"},
+	}
+	for _, test := range tests {
+		var sb strings.Builder
+		if err := writeEvent(&sb, test.event); err != nil {
+			t.Fatal("writeEvent() should not fail, got", err)
+		}
+		if sb.String() != test.expected {
+			t.Errorf("writeEvent(%+v) = %q, expected %q", test.event, sb.String(), test.expected)
+		}
+	}
+}
+
+func TestEventStream_Shutdown(t *testing.T) {
+	e := New()
+	e.SetShutdownEvent(&Event{Event: "shutdown"})
+	e.GET("/events", func(c Context) {
+		if err := c.EventStream(c, make(chan Event)); err != ErrShuttingDown {
+			t.Error("EventStream() should return ErrShuttingDown, got", err)
+		}
+	})
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal("Shutdown() should not fail, got", err)
+	}
+	w := serve(e, http.MethodGet, "/events")
+	if !strings.Contains(w.Body.String(), "event: shutdown\n") {
+		t.Errorf("expected final shutdown event, got %q", w.Body.String())
+	}
+}
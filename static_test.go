@@ -0,0 +1,90 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var staticTestFS = fstest.MapFS{
+	"index.html":  {Data: []byte("<html>app</html>")},
+	"app.js":      {Data: []byte("console.log('app')")},
+	"css/app.css": {Data: []byte("body {}")},
+}
+
+func TestRouter_Static(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	e.Static("/assets", dir)
+
+	if w := serve(e, http.MethodGet, "/assets/hello.txt"); w.Code != http.StatusOK || w.Body.String() != "hello" {
+		t.Error("expected the file, got", w.Code, w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/assets/missing.txt"); w.Code != http.StatusNotFound {
+		t.Error("expected 404 for a missing file, got", w.Code)
+	}
+}
+
+func TestRouter_StaticFS(t *testing.T) {
+	e := New()
+	e.Group("/v1").StaticFS("/static", staticTestFS)
+
+	w := serve(e, http.MethodGet, "/v1/static/css/app.css")
+	if w.Code != http.StatusOK || w.Body.String() != "body {}" {
+		t.Error("expected the file, got", w.Code, w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/v1/static/missing.css"); w.Code != http.StatusNotFound {
+		t.Error("expected 404 for a missing file, got", w.Code)
+	}
+}
+
+func TestRouter_SPA(t *testing.T) {
+	e := New()
+	e.GET("/api/users", func(c Context) {
+		c.RespondOk([]string{})
+	})
+	e.SPA("/", staticTestFS, "index.html")
+
+	tests := []struct {
+		path     string
+		accept   string
+		expected int
+		body     string
+	}{
+		{"/app.js", "", http.StatusOK, "console.log('app')"},
+		{"/users/42", "text/html,application/xhtml+xml", http.StatusOK, "<html>app</html>"},
+		{"/missing.js", "text/html", http.StatusNotFound, "404 page not found"},
+		{"/api/unknown", "application/json", http.StatusNotFound, "404 page not found"},
+		{"/api/users", "application/json", http.StatusOK, "[]"},
+	}
+	for _, tt := range tests {
+		w := serveHeader(e, tt.path, "Accept", tt.accept)
+		if w.Code != tt.expected || w.Body.String() != tt.body {
+			t.Errorf("expected %d %q for %s, got %d %q", tt.expected, tt.body, tt.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouter_SPA_Prefix(t *testing.T) {
+	e := New()
+	e.SPA("/app", staticTestFS, "index.html")
+
+	if w := serveHeader(e, "/app/settings", "Accept", "text/html"); w.Code != http.StatusOK || w.Body.String() != "<html>app</html>" {
+		t.Error("expected the index file, got", w.Code, w.Body.String())
+	}
+	if w := serveHeader(e, "/app/app.js", "Accept", ""); w.Body.String() != "console.log('app')" {
+		t.Error("expected the file, got", w.Code, w.Body.String())
+	}
+	if w := serveHeader(e, "/app/missing.png", "Accept", "image/*"); w.Code != http.StatusNotFound {
+		t.Error("expected 404 for a missing asset, got", w.Code)
+	}
+}
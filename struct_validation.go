@@ -0,0 +1,231 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidateStruct validates a struct using `validate` struct tags, e.g. `validate:"required,min=3,max=40"`.
+//
+// Supported rules:
+//
+//	required    the value must not be the zero value
+//	min=n       strings must have at least n characters, slices and maps at least n elements, numbers must be at least n
+//	max=n       strings must have at most n characters, slices and maps at most n elements, numbers must be at most n
+//	email       strings must be an email address
+//	url         strings must be an absolute URL
+//	uuid        strings must be a UUID
+//	oneof=a b   strings must be one of the space separated values
+//
+// Nested structs, pointers to structs and slices of structs are validated recursively.
+// Fields are reported by their JSON name.
+//
+// Localized validators, see Validator.Localize, look up the messages of failed rules under the keys validation.required,
+// validation.email, validation.url, validation.uuid and validation.oneof, formatted with the field name and the values.
+// Bounds use validation.min and validation.max for numbers, validation.min_length and validation.max_length for strings
+// and validation.min_items and validation.max_items for collections, formatted with the field name and the bound.
+func ValidateStruct(obj any) error {
+	return NewValidator().Struct(obj).Validate()
+}
+
+// Struct validates a struct using `validate` struct tags. See ValidateStruct for the supported rules.
+func (v *Validator) Struct(obj any) *Validator {
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return v
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return v
+	}
+	field := v.field
+	v.validateStruct(val, "")
+	v.field = field
+	return v
+}
+
+func (v *Validator) validateStruct(val reflect.Value, prefix string) {
+	for _, f := range structRulesFor(val.Type()) {
+		fv := val.Field(f.index)
+		name := prefix + f.name
+		v.field = name
+		for _, r := range f.rules {
+			r.apply(v, name, fv)
+		}
+		v.validateNested(fv, name)
+	}
+}
+
+func (v *Validator) validateNested(fv reflect.Value, name string) {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != timeType && hasStructRules(fv.Type()) {
+			v.validateStruct(fv, name+".")
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			v.validateNested(fv.Index(i), fmt.Sprintf("%s[%d]", name, i))
+		}
+	}
+}
+
+type structField struct {
+	index int
+	name  string
+	rules []structRule
+}
+
+type structRule struct {
+	name  string
+	param string
+}
+
+var structRulesCache sync.Map
+
+// structRulesFor returns the validated fields of a struct type. Rules are parsed once per type.
+func structRulesFor(t reflect.Type) []structField {
+	if cached, ok := structRulesCache.Load(t); ok {
+		return cached.([]structField)
+	}
+	fields := make([]structField, 0)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		rules := parseStructRules(t, f, tag)
+		if len(rules) == 0 && !mayHaveNestedRules(f.Type) {
+			continue
+		}
+		fields = append(fields, structField{
+			index: i,
+			name:  jsonFieldName(f),
+			rules: rules,
+		})
+	}
+	structRulesCache.Store(t, fields)
+	return fields
+}
+
+func parseStructRules(t reflect.Type, f reflect.StructField, tag string) []structRule {
+	rules := make([]structRule, 0)
+	if len(tag) == 0 {
+		return rules
+	}
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch name {
+		case "required", "email", "url", "uuid":
+		case "min", "max":
+			if _, err := strconv.ParseFloat(param, 64); err != nil {
+				panic(fmt.Sprintf("jug: invalid parameter for rule %s on %s.%s", name, t.Name(), f.Name))
+			}
+		case "oneof":
+		default:
+			panic(fmt.Sprintf("jug: unknown validation rule %s on %s.%s", name, t.Name(), f.Name))
+		}
+		rules = append(rules, structRule{name: name, param: param})
+	}
+	return rules
+}
+
+func hasStructRules(t reflect.Type) bool {
+	return len(structRulesFor(t)) > 0
+}
+
+func mayHaveNestedRules(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+func jsonFieldName(f reflect.StructField) string {
+	name := strings.Split(f.Tag.Get("json"), ",")[0]
+	if len(name) == 0 || name == "-" {
+		return f.Name
+	}
+	return name
+}
+
+func (r structRule) apply(v *Validator, name string, fv reflect.Value) {
+	if r.name == "required" {
+		v.require(!fv.IsZero(), CodeRequired, v.ruleMessage("validation.required", "%s is required", name))
+		return
+	}
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	switch r.name {
+	case "min", "max":
+		r.applyBound(v, name, fv)
+	case "email":
+		if fv.Kind() == reflect.String {
+			v.RequireEmail(fv.String(), v.ruleMessage("validation.email", "%s must be a valid email address", name))
+		}
+	case "url":
+		if fv.Kind() == reflect.String {
+			v.RequireURL(fv.String(), v.ruleMessage("validation.url", "%s must be a valid URL", name))
+		}
+	case "uuid":
+		if fv.Kind() == reflect.String {
+			v.RequireUUID(fv.String(), v.ruleMessage("validation.uuid", "%s must be a valid UUID", name))
+		}
+	case "oneof":
+		if fv.Kind() == reflect.String {
+			values := strings.Fields(r.param)
+			v.RequireEnum(fv.String(), v.ruleMessage("validation.oneof", "%s must be one of %s", name, strings.Join(values, ", ")), values...)
+		}
+	}
+}
+
+func (r structRule) applyBound(v *Validator, name string, fv reflect.Value) {
+	bound, _ := strconv.ParseFloat(r.param, 64)
+	var x float64
+	key, code, verb := "validation."+r.name, r.name, "%s must be"
+	unit := ""
+	switch fv.Kind() {
+	case reflect.String:
+		x = float64(utf8.RuneCountInString(fv.String()))
+		key, code, verb, unit = key+"_length", r.name+"_length", "%s must have", " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		x = float64(fv.Len())
+		key, code, verb, unit = key+"_items", r.name+"_length", "%s must have", " elements"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x = float64(fv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x = float64(fv.Uint())
+	case reflect.Float32, reflect.Float64:
+		x = fv.Float()
+	default:
+		return
+	}
+	if r.name == "min" {
+		v.require(x >= bound, code, v.ruleMessage(key, verb+" at least %s"+unit, name, r.param))
+		return
+	}
+	v.require(x <= bound, code, v.ruleMessage(key, verb+" at most %s"+unit, name, r.param))
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import "testing"
+
+type structValidationTestAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type structValidationTestUser struct {
+	Name      string                        `json:"name" validate:"required,min=3,max=5"`
+	Email     string                        `json:"email" validate:"email"`
+	Role      string                        `json:"role" validate:"oneof=admin user"`
+	Age       int                           `json:"age" validate:"min=18"`
+	Tags      []string                      `json:"tags" validate:"max=2"`
+	Address   *structValidationTestAddress  `json:"address"`
+	Addresses []structValidationTestAddress `json:"addresses"`
+}
+
+func TestValidateStruct(t *testing.T) {
+	valid := structValidationTestUser{
+		Name:  "Jane",
+		Email: "jane@example.com",
+		Role:  "admin",
+		Age:   18,
+		Tags:  []string{"a", "b"},
+	}
+	if err := ValidateStruct(&valid); err != nil {
+		t.Fatal("ValidateStruct() should not fail for a valid struct, got", err)
+	}
+}
+
+func TestValidateStruct_Invalid(t *testing.T) {
+	invalid := structValidationTestUser{
+		Name:      "Jo",
+		Email:     "jane",
+		Role:      "guest",
+		Age:       17,
+		Tags:      []string{"a", "b", "c"},
+		Address:   &structValidationTestAddress{},
+		Addresses: []structValidationTestAddress{{City: "Berlin"}, {}},
+	}
+	err := NewValidator().Structured().Struct(invalid).Validate()
+	e, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatal("expected a *ValidationError, got", err)
+	}
+	expected := []FieldError{
+		{Field: "name", Code: CodeMinLength, Message: "name must have at least 3 characters"},
+		{Field: "email", Code: CodeEmail, Message: "email must be a valid email address"},
+		{Field: "role", Code: CodeEnum, Message: "role must be one of admin, user"},
+		{Field: "age", Code: CodeMin, Message: "age must be at least 18"},
+		{Field: "tags", Code: CodeMaxLength, Message: "tags must have at most 2 elements"},
+		{Field: "address.city", Code: CodeRequired, Message: "address.city is required"},
+		{Field: "addresses[1].city", Code: CodeRequired, Message: "addresses[1].city is required"},
+	}
+	if len(e.Errors) != len(expected) {
+		t.Fatal("unexpected field errors", e.Errors)
+	}
+	for i := range expected {
+		if e.Errors[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], e.Errors[i])
+		}
+	}
+}
+
+func TestValidateStruct_UnknownRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("ValidateStruct() should panic for unknown rules")
+		}
+	}()
+	_ = ValidateStruct(struct {
+		Name string `validate:"requird"`
+	}{})
+}
@@ -0,0 +1,121 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// ErrTaskQueueFull is returned by Context.Go if all workers are busy and the queue is full.
+var ErrTaskQueueFull = errors.New("task queue full")
+
+const (
+	defaultTaskWorkers   = 8
+	defaultTaskQueueSize = 256
+)
+
+// Task is a function run in the background, see Context.Go.
+type Task func(ctx context.Context)
+
+// taskPool runs background tasks with a bounded number of workers.
+// Workers are started on first use. Closing the pool waits for queued and running tasks.
+type taskPool struct {
+	workers int
+	queue   chan Task
+	// slots limits the number of running and queued tasks.
+	slots   chan struct{}
+	ctx     context.Context
+	cancel  context.CancelFunc
+	start   sync.Once
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+}
+
+func newTaskPool(workers int, queueSize int) *taskPool {
+	if workers <= 0 {
+		workers = defaultTaskWorkers
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &taskPool{
+		workers: workers,
+		queue:   make(chan Task, workers+queueSize),
+		slots:   make(chan struct{}, workers+queueSize),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// submit queues a task. It fails if the pool is closed or the queue is full.
+func (p *taskPool) submit(task Task) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrShuttingDown
+	}
+	p.start.Do(func() {
+		for i := 0; i < p.workers; i++ {
+			go p.work()
+		}
+	})
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		return ErrTaskQueueFull
+	}
+	p.pending.Add(1)
+	p.queue <- task
+	return nil
+}
+
+func (p *taskPool) work() {
+	for task := range p.queue {
+		p.run(task)
+	}
+}
+
+func (p *taskPool) run(task Task) {
+	defer p.pending.Done()
+	defer func() {
+		<-p.slots
+	}()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("[jug] task panic recovered: %v\n%s", recovered, debug.Stack())
+		}
+	}()
+	task(p.ctx)
+}
+
+// close stops accepting tasks and waits for queued and running tasks.
+// If ctx is done first, the context of the running tasks is canceled and ctx.Err() is returned.
+func (p *taskPool) close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.queue)
+	}
+	p.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		p.pending.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
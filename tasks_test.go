@@ -0,0 +1,93 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestContext_Go(t *testing.T) {
+	var completed int32
+	e := New()
+	e.POST("/signup", func(c Context) {
+		if err := c.Go(func(ctx context.Context) {
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&completed, 1)
+		}); err != nil {
+			c.RespondServiceUnavailableE(err)
+			return
+		}
+		_ = c.Go(func(ctx context.Context) {
+			panic("boom")
+		})
+		c.RespondNoContent()
+	})
+
+	if w := serve(e, http.MethodPost, "/signup"); w.Code != http.StatusNoContent {
+		t.Fatal("expected response before the task finished, got", w.Code)
+	}
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal("unexpected shutdown error", err)
+	}
+	if atomic.LoadInt32(&completed) != 1 {
+		t.Fatal("expected shutdown to drain the task")
+	}
+	if w := serve(e, http.MethodPost, "/signup"); w.Code != http.StatusServiceUnavailable {
+		t.Fatal("expected tasks to be rejected after shutdown, got", w.Code)
+	}
+}
+
+func TestTaskPool_Bounded(t *testing.T) {
+	p := newTaskPool(1, 1)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.submit(func(ctx context.Context) {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.submit(func(ctx context.Context) {}); err != nil {
+		t.Fatal("expected task to be queued, got", err)
+	}
+	if err := p.submit(func(ctx context.Context) {}); !errors.Is(err, ErrTaskQueueFull) {
+		t.Fatal("expected ErrTaskQueueFull, got", err)
+	}
+	close(release)
+	if err := p.close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.submit(func(ctx context.Context) {}); !errors.Is(err, ErrShuttingDown) {
+		t.Fatal("expected ErrShuttingDown, got", err)
+	}
+}
+
+func TestTaskPool_CloseDeadline(t *testing.T) {
+	p := newTaskPool(1, 0)
+	canceled := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.submit(func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+	})
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := p.close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("expected deadline error, got", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected task context to be canceled")
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+// tenantKey stores the tenant resolved by the Tenant middleware.
+var tenantKey = Key[string]("jug.tenant")
+
+// TenantID returns the tenant resolved by the Tenant middleware or an empty string.
+func TenantID(c Context) string {
+	return tenantKey.GetOr(c, "")
+}
+
+// Tenant returns a middleware resolving the tenant of a request with resolve, e.g. from a header, the host or the
+// authenticated principal. The tenant is available with TenantID and is used by RateLimitByTenant.
+// Requests without tenant are aborted with 400.
+func Tenant(resolve func(c Context) (string, bool)) HandlerFunc {
+	return func(c Context) {
+		tenant, ok := resolve(c)
+		if !ok || len(tenant) == 0 {
+			c.HandleError(NewBadRequestError("tenant is required"))
+			c.Abort()
+			return
+		}
+		tenantKey.Set(c, tenant)
+	}
+}
+
+// TenantFromHeader resolves the tenant from the value of a request header, e.g. X-Tenant-ID.
+func TenantFromHeader(name string) func(c Context) (string, bool) {
+	return func(c Context) (string, bool) {
+		tenant := c.GetHeader(name)
+		return tenant, len(tenant) > 0
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	e := New()
+	e.Use(Tenant(TenantFromHeader("X-Tenant-ID")))
+	e.GET("/", func(c Context) {
+		c.String(http.StatusOK, TenantID(c))
+	})
+
+	if w := serveHeader(e, "/", "X-Tenant-ID", "acme"); w.Code != http.StatusOK || w.Body.String() != "acme" {
+		t.Error("expected tenant to be resolved, got", w.Code, w.Body.String())
+	}
+	if w := serveHeader(e, "/", "X-Tenant-ID", ""); w.Code != http.StatusBadRequest {
+		t.Error("expected requests without tenant to be rejected, got", w.Code)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout returns a middleware that sets a deadline for subsequent handlers.
+// The deadline is visible through the Context's Deadline, Done and Err methods and cancels downstream work
+// using the Context. If the deadline is exceeded and the handlers did not write a response, onTimeout is invoked.
+// Without onTimeout, the request is answered with 503.
+//
+// Handlers are not interrupted, they must return when the Context is done.
+func Timeout(d time.Duration, onTimeout ...HandlerFunc) HandlerFunc {
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(gc.Request.Context(), d)
+		defer cancel()
+		gc.Request = gc.Request.WithContext(ctx)
+		c.Next()
+		if ctx.Err() != context.DeadlineExceeded || gc.Writer.Written() {
+			return
+		}
+		if len(onTimeout) == 0 {
+			c.HandleError(NewResponseStatusError(http.StatusServiceUnavailable, "request timed out"))
+		}
+		for _, h := range onTimeout {
+			h(c)
+		}
+		c.Abort()
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	e := New()
+	e.Use(Timeout(10 * time.Millisecond))
+	e.GET("/slow", func(c Context) {
+		if _, ok := c.Deadline(); !ok {
+			t.Error("expected a deadline")
+		}
+		<-c.Done()
+	})
+	e.GET("/fast", func(c Context) {
+		c.RespondNoContent()
+	})
+	e.GET("/upstream", func(c Context) {
+		<-c.Done()
+		c.HandleError(c.Err())
+	})
+
+	if w := serve(e, http.MethodGet, "/slow"); w.Code != http.StatusServiceUnavailable {
+		t.Error("expected timeout response, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/fast"); w.Code != http.StatusNoContent {
+		t.Error("expected handler response, got", w.Code)
+	}
+	if w := serve(e, http.MethodGet, "/upstream"); w.Code != http.StatusGatewayTimeout {
+		t.Error("expected deadline errors to be answered with 504, got", w.Code)
+	}
+}
+
+func TestTimeout_Custom(t *testing.T) {
+	e := New()
+	e.GET("/", Timeout(time.Millisecond, func(c Context) {
+		c.String(http.StatusGatewayTimeout, "too slow")
+	}), func(c Context) {
+		<-c.Done()
+	})
+	if w := serve(e, http.MethodGet, "/"); w.Code != http.StatusGatewayTimeout || w.Body.String() != "too slow" {
+		t.Error("expected custom timeout response, got", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// defaultMaxDecompressedSize is the default limit of decompressed request bodies.
+const defaultMaxDecompressedSize = 10 << 20
+
+// TransformConfig configures the Transform middleware.
+type TransformConfig struct {
+	// DecompressRequests decompresses request bodies with a gzip or deflate Content-Encoding.
+	// Requests with other encodings are rejected with 415.
+	DecompressRequests bool
+	// MaxDecompressedSize limits the size of decompressed request bodies. Defaults to 10 MiB.
+	MaxDecompressedSize int64
+	// DecodeCharsets converts request bodies declaring a charset other than UTF-8 to UTF-8.
+	// Requests with unknown charsets are rejected with 415.
+	DecodeCharsets bool
+	// RewriteResponse rewrites the buffered response before it is sent.
+	RewriteResponse func(c Context, r *ResponseInfo)
+	// ResponseCharset encodes text responses with the given charset, e.g. iso-8859-1.
+	ResponseCharset string
+}
+
+// Transform returns a middleware rewriting request and response bodies.
+// It panics if the ResponseCharset is unknown.
+func Transform(cfg TransformConfig) HandlerFunc {
+	if cfg.MaxDecompressedSize <= 0 {
+		cfg.MaxDecompressedSize = defaultMaxDecompressedSize
+	}
+	var responseEncoding encoding.Encoding
+	var responseCharset string
+	if cfg.ResponseCharset != "" {
+		enc, err := htmlindex.Get(cfg.ResponseCharset)
+		if err != nil {
+			panic(fmt.Sprintf("jug: unknown response charset %s", cfg.ResponseCharset))
+		}
+		responseEncoding = enc
+		responseCharset, _ = htmlindex.Name(enc)
+	}
+	return func(c Context) {
+		gc, ok := ginContextOf(c)
+		if !ok {
+			return
+		}
+		r := gc.Request
+		if cfg.DecompressRequests {
+			if err := decompressBody(gc.Writer, r, cfg.MaxDecompressedSize); err != nil {
+				if e, ok := err.(*ResponseStatusError); ok && e.StatusCode == http.StatusUnsupportedMediaType {
+					c.SetHeader("Accept-Encoding", "gzip, deflate")
+				}
+				c.HandleError(err)
+				c.Abort()
+				return
+			}
+		}
+		if cfg.DecodeCharsets {
+			if err := decodeCharset(r); err != nil {
+				c.HandleError(err)
+				c.Abort()
+				return
+			}
+		}
+		if cfg.RewriteResponse == nil && responseEncoding == nil {
+			return
+		}
+		c.BufferResponse()
+		c.OnResponse(func(info *ResponseInfo) {
+			if cfg.RewriteResponse != nil {
+				cfg.RewriteResponse(c, info)
+			}
+			if responseEncoding != nil {
+				encodeCharset(info, responseEncoding, responseCharset)
+			}
+		})
+	}
+}
+
+// decompressBody replaces the request body with its decompressed content.
+func decompressBody(w http.ResponseWriter, r *http.Request, limit int64) error {
+	header := r.Header.Get("Content-Encoding")
+	if header == "" || r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	codings := strings.Split(header, ",")
+	body := r.Body
+	var reader io.Reader = body
+	for i := len(codings) - 1; i >= 0; i-- {
+		var err error
+		switch coding := strings.ToLower(strings.TrimSpace(codings[i])); coding {
+		case "identity", "":
+			continue
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(reader)
+		case "deflate":
+			reader, err = zlib.NewReader(reader)
+		default:
+			return NewResponseStatusError(http.StatusUnsupportedMediaType, "unsupported content encoding "+coding)
+		}
+		if err != nil {
+			if tooLarge, ok := bodyTooLarge(err); ok {
+				return NewStatusError(http.StatusRequestEntityTooLarge, tooLarge)
+			}
+			return NewBadRequestError(fmt.Sprintf("invalid %s body", strings.TrimSpace(codings[i])))
+		}
+	}
+	r.Body = http.MaxBytesReader(w, readCloser{Reader: reader, Closer: body}, limit)
+	r.ContentLength = -1
+	r.Header.Del("Content-Length")
+	r.Header.Del("Content-Encoding")
+	return nil
+}
+
+// decodeCharset converts the request body to UTF-8.
+func decodeCharset(r *http.Request) error {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || params["charset"] == "" {
+		return nil
+	}
+	enc, err := htmlindex.Get(params["charset"])
+	if err != nil {
+		return NewResponseStatusError(http.StatusUnsupportedMediaType, "unsupported charset "+params["charset"])
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return nil
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = readCloser{Reader: transform.NewReader(r.Body, enc.NewDecoder()), Closer: r.Body}
+		r.ContentLength = -1
+		r.Header.Del("Content-Length")
+	}
+	params["charset"] = "utf-8"
+	r.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	return nil
+}
+
+// encodeCharset encodes text responses with the given encoding. Characters the charset cannot represent are replaced.
+func encodeCharset(info *ResponseInfo, enc encoding.Encoding, charset string) {
+	mediaType, params, err := mime.ParseMediaType(info.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mediaType, "text/") {
+		return
+	}
+	body, err := encoding.ReplaceUnsupported(enc.NewEncoder()).Bytes(info.Body)
+	if err != nil {
+		return
+	}
+	info.Body = body
+	params["charset"] = charset
+	info.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveTransform(e Engine, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/echo", body)
+	for k, v := range header {
+		r.Header[k] = v
+	}
+	return serveRequest(e, r)
+}
+
+func gzipped(s string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(s))
+	_ = zw.Close()
+	return &buf
+}
+
+func echoBody(c Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.HandleError(err)
+		return
+	}
+	c.String(http.StatusOK, "%s|%s", c.GetHeader("Content-Type"), body)
+}
+
+func TestTransformDecompressesRequests(t *testing.T) {
+	e := New()
+	e.POST("/echo", Transform(TransformConfig{DecompressRequests: true, MaxDecompressedSize: 32}), echoBody)
+
+	w := serveTransform(e, gzipped("hello"), http.Header{"Content-Encoding": {"gzip"}})
+	if w.Code != http.StatusOK || w.Body.String() != "|hello" {
+		t.Error("expected decompressed body, got", w.Code, w.Body.String())
+	}
+	w = serveTransform(e, strings.NewReader("plain"), nil)
+	if w.Body.String() != "|plain" {
+		t.Error("expected uncompressed body to pass, got", w.Body.String())
+	}
+	w = serveTransform(e, strings.NewReader("data"), http.Header{"Content-Encoding": {"br"}})
+	if w.Code != http.StatusUnsupportedMediaType || w.Header().Get("Accept-Encoding") != "gzip, deflate" {
+		t.Error("expected 415 for unsupported encoding, got", w.Code, w.Header())
+	}
+	w = serveTransform(e, strings.NewReader("not gzip"), http.Header{"Content-Encoding": {"gzip"}})
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for invalid gzip body, got", w.Code)
+	}
+	w = serveTransform(e, gzipped(strings.Repeat("x", 64)), http.Header{"Content-Encoding": {"gzip"}})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Error("expected 413 for large decompressed body, got", w.Code, w.Body.String())
+	}
+}
+
+func TestTransformCharsets(t *testing.T) {
+	e := New()
+	e.POST("/echo", Transform(TransformConfig{DecodeCharsets: true, ResponseCharset: "latin1"}), echoBody)
+
+	w := serveTransform(e, strings.NewReader("caf\xe9"), http.Header{"Content-Type": {"text/plain; charset=iso-8859-1"}})
+	if w.Code != http.StatusOK {
+		t.Fatal("expected 200, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=windows-1252" {
+		t.Error("expected encoded response charset, got", ct)
+	}
+	if w.Body.String() != "text/plain; charset=utf-8|caf\xe9" {
+		t.Errorf("expected request decoded to UTF-8 and response encoded, got %q", w.Body.String())
+	}
+	w = serveTransform(e, strings.NewReader("x"), http.Header{"Content-Type": {"text/plain; charset=unknown"}})
+	if w.Code != http.StatusUnsupportedMediaType {
+		t.Error("expected 415 for unknown charset, got", w.Code)
+	}
+}
+
+func TestTransformRewritesResponses(t *testing.T) {
+	e := New()
+	e.POST("/echo", Transform(TransformConfig{
+		RewriteResponse: func(c Context, r *ResponseInfo) {
+			r.Body = bytes.ToUpper(r.Body)
+			r.Header.Set("X-Rewritten", "true")
+		},
+	}), echoBody)
+
+	w := serveTransform(e, strings.NewReader("hello"), nil)
+	if w.Body.String() != "|HELLO" || w.Header().Get("X-Rewritten") != "true" {
+		t.Error("expected rewritten response, got", w.Body.String(), w.Header())
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Codes reported in FieldError.Code by the built-in validation rules.
+const (
+	CodeInvalid   = "invalid"
+	CodeRequired  = "required"
+	CodeEnum      = "enum"
+	CodePattern   = "pattern"
+	CodeMinLength = "min_length"
+	CodeMaxLength = "max_length"
+	CodeLength    = "length"
+	CodeEmail     = "email"
+	CodeURL       = "url"
+	CodeUUID      = "uuid"
+
+	CodeMin         = "min"
+	CodeMax         = "max"
+	CodeBetween     = "between"
+	CodePositive    = "positive"
+	CodeNonNegative = "non_negative"
+
+	CodeBefore   = "before"
+	CodeAfter    = "after"
+	CodeFuture   = "future"
+	CodePast     = "past"
+	CodeDuration = "duration"
+
+	CodeEquals         = "equals"
+	CodeNotEquals      = "not_equals"
+	CodeLessThan       = "less_than"
+	CodeLessOrEqual    = "less_or_equal"
+	CodeGreaterThan    = "greater_than"
+	CodeGreaterOrEqual = "greater_or_equal"
+
+	CodeFileSize        = "file_size"
+	CodeContentType     = "content_type"
+	CodeImageDimensions = "image_dimensions"
+)
+
+// FieldError describes a failed validation rule.
+type FieldError struct {
+	Field string `json:"field,omitempty"`
+	// Other is the field Field was compared with by cross-field rules.
+	Other   string `json:"other,omitempty"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError holds all failed validation rules. It marshals to a JSON array of FieldError.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		messages = append(messages, fe.Message)
+	}
+	return strings.Join(messages, ", ")
+}
+
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Errors)
+}
@@ -5,31 +5,89 @@
 package jug
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
 type Validator struct {
-	errors strings.Builder
+	errors     []FieldError
+	field      string
+	structured bool
+	limits     *SchemaLimits
+	bundle     Bundle
+	locale     string
+	pending    []asyncRule
+	timeout    time.Duration
+	// configErr is a configuration error returned by Validate instead of the failed rules.
+	configErr error
 }
 
 func NewValidator() *Validator {
 	return &Validator{
-		errors: strings.Builder{},
+		errors: make([]FieldError, 0),
 	}
 }
 
+// Structured makes Validate return a *ValidationError listing each failed rule with its field.
+func (v *Validator) Structured() *Validator {
+	v.structured = true
+	return v
+}
+
+// Limits sets the schema limits used by RequireFitsColumn. Defaults to DefaultSchemaLimits.
+func (v *Validator) Limits(limits *SchemaLimits) *Validator {
+	v.limits = limits
+	return v
+}
+
+// Localize translates the messages of failed rules into the given locale. Messages are looked up as keys in the
+// bundle and kept as they are if the bundle has no translation. See ValidateStruct for the keys of struct tag rules.
+func (v *Validator) Localize(bundle Bundle, locale string) *Validator {
+	v.bundle = bundle
+	v.locale = locale
+	return v
+}
+
+// Field sets the field name reported for all following rules.
+func (v *Validator) Field(name string) *Validator {
+	v.field = name
+	return v
+}
+
 // V invokes a validation function on the validator
 func (v *Validator) V(fun func(*Validator)) *Validator {
 	fun(v)
 	return v
 }
 
+// When invokes a validation function on the validator if cond is true.
+func (v *Validator) When(cond bool, fun func(*Validator)) *Validator {
+	if cond {
+		fun(v)
+	}
+	return v
+}
+
 // Require requires a condition to be truthy
 func (v *Validator) Require(condition bool, message string) *Validator {
+	return v.require(condition, CodeInvalid, message)
+}
+
+func (v *Validator) require(condition bool, code string, message string) *Validator {
 	if !condition {
-		v.append(message)
+		v.append(code, message)
 	}
 	return v
 }
@@ -44,14 +102,14 @@ func (v *Validator) RequireEnum(s string, message string, values ...string) *Val
 			return v
 		}
 	}
-	v.append(message)
+	v.append(CodeEnum, message)
 	return v
 }
 
 // RequireStringSliceMinLength requires the given slice to have at least min elements
 func (v *Validator) RequireStringSliceMinLength(s []string, min int, message string) *Validator {
 	if len(s) < min {
-		v.append(message)
+		v.append(CodeMinLength, message)
 	}
 	return v
 }
@@ -59,7 +117,7 @@ func (v *Validator) RequireStringSliceMinLength(s []string, min int, message str
 // RequireStringSliceNotEmpty requires the given slice not to be empty
 func (v *Validator) RequireStringSliceNotEmpty(s []string, message string) *Validator {
 	if len(s) == 0 {
-		v.append(message)
+		v.append(CodeRequired, message)
 	}
 	return v
 }
@@ -76,62 +134,289 @@ func (v *Validator) RequireStringSliceEnum(s []string, message string, values ..
 	for _, i := range s {
 		_, ok := m[i]
 		if !ok {
-			v.append(message)
+			v.append(CodeEnum, message)
+			return v
+		}
+	}
+	return v
+}
+
+// RequireOneOfPresent requires at least one of the given fields to be non-empty. fields maps field names to values.
+// The failure is reported for the field names, sorted and joined by a comma.
+func (v *Validator) RequireOneOfPresent(fields map[string]string, message string) *Validator {
+	names := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if len(value) > 0 {
 			return v
 		}
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	field := v.field
+	v.field = strings.Join(names, ",")
+	v.append(CodeRequired, message)
+	v.field = field
 	return v
 }
 
 // RequireMatchesRegex requires a value to match a given regular expression
 func (v *Validator) RequireMatchesRegex(s string, regex *regexp.Regexp, message string) *Validator {
 	if len(s) > 0 && !regex.MatchString(s) {
-		v.append(message)
+		v.append(CodePattern, message)
 	}
 	return v
 }
 
+// RequireEmail requires a value to be an email address as defined in RFC 5322, without display name
+func (v *Validator) RequireEmail(s string, message string) *Validator {
+	if len(s) == 0 {
+		return v
+	}
+	addr, err := mail.ParseAddress(s)
+	return v.require(err == nil && addr.Address == s, CodeEmail, message)
+}
+
+// RequireURL requires a value to be an absolute URL with scheme and host
+func (v *Validator) RequireURL(s string, message string) *Validator {
+	if len(s) == 0 {
+		return v
+	}
+	u, err := url.Parse(s)
+	return v.require(err == nil && len(u.Scheme) > 0 && len(u.Host) > 0, CodeURL, message)
+}
+
+// RequireUUID requires a value to be a UUID in its canonical textual representation as defined in RFC 4122
+func (v *Validator) RequireUUID(s string, message string) *Validator {
+	if len(s) == 0 {
+		return v
+	}
+	return v.require(uuidRegex.MatchString(s), CodeUUID, message)
+}
+
 // RequireStringMinLength requires a value to have a given minimum length
 func (v *Validator) RequireStringMinLength(s string, min int, message string) *Validator {
-	return v.Require(len(s) >= min, message)
+	return v.require(len(s) >= min, CodeMinLength, message)
 }
 
 // RequireStringMaxLength requires a value to have a given maximum length
 func (v *Validator) RequireStringMaxLength(s string, max int, message string) *Validator {
-	return v.Require(len(s) < max, message)
+	return v.require(len(s) < max, CodeMaxLength, message)
+}
+
+// RequireFitsColumn requires a string to fit into the given column registered in the schema limits.
+// The length is counted in characters. If the column is not registered, Validate returns a configuration error.
+func (v *Validator) RequireFitsColumn(s string, column string, message string) *Validator {
+	limit, ok := v.columnLimit(column)
+	if !ok {
+		return v
+	}
+	return v.require(utf8.RuneCountInString(s) <= limit, CodeMaxLength, message)
+}
+
+func (v *Validator) columnLimit(column string) (int, bool) {
+	limits := v.limits
+	if limits == nil {
+		limits = DefaultSchemaLimits
+	}
+	limit, ok := limits.Limit(column)
+	if !ok && v.configErr == nil {
+		v.configErr = &unknownColumnError{column: column}
+	}
+	return limit, ok
+}
+
+// FieldValue validates a string value of a field, see Validator.FieldValue.
+type FieldValue struct {
+	v     *Validator
+	name  string
+	value string
+}
+
+// FieldValue sets the field name reported for all following rules, like Field, and returns rules for the value.
+// The rules generate their messages from the field name.
+func (v *Validator) FieldValue(name string, value string) *FieldValue {
+	v.field = name
+	return &FieldValue{v: v, name: name, value: value}
+}
+
+// FitsColumn requires the value to fit into the given column registered in the schema limits, see RequireFitsColumn.
+// The message uses the key validation.max_length.
+func (f *FieldValue) FitsColumn(column string) *Validator {
+	limit, ok := f.v.columnLimit(column)
+	if !ok {
+		return f.v
+	}
+	message := f.v.ruleMessage("validation.max_length", "%s must have at most %s characters", f.name, strconv.Itoa(limit))
+	return f.v.require(utf8.RuneCountInString(f.value) <= limit, CodeMaxLength, message)
 }
 
 // RequireStringNotEmpty requires a string not to be empty
 func (v *Validator) RequireStringNotEmpty(s string, message string) *Validator {
-	return v.Require(len(s) > 0, message)
+	return v.require(len(s) > 0, CodeRequired, message)
 }
 
 // RequireStringLengthBetween requires a string TODO
 func (v *Validator) RequireStringLengthBetween(s string, min int, max int, message string) *Validator {
-	return v.Require(len(s) >= min && len(s) < max, message)
+	return v.require(len(s) >= min && len(s) < max, CodeLength, message)
 }
 
-// Validate performs the validation
+// Validate performs the validation. Rules added with RequireFunc run concurrently before, see ValidateContext.
+// Configuration errors, e.g. an unregistered column, are returned instead of the failed rules.
 func (v *Validator) Validate() error {
-	if v.errors.Len() > 0 {
-		return fmt.Errorf(v.errors.String())
+	v.runPending(context.Background())
+	if v.configErr != nil {
+		return v.configErr
 	}
-	return nil
+	return v.err()
+}
+
+// err returns the failed rules as error or nil.
+func (v *Validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	e := &ValidationError{Errors: v.errors}
+	if v.structured {
+		return e
+	}
+	return &unstructuredValidationError{errors: v.errors, message: e.Error()}
+}
+
+// unstructuredValidationError is returned by validators that are not structured.
+// It keeps the failed rules for metrics but is answered with its message only.
+type unstructuredValidationError struct {
+	errors  []FieldError
+	message string
+}
+
+func (e *unstructuredValidationError) Error() string {
+	return e.message
 }
 
-func (v *Validator) append(msg string) {
-	if v.errors.Len() > 0 {
-		v.errors.WriteString(", ")
+func (v *Validator) append(code string, msg string) {
+	v.errors = append(v.errors, FieldError{
+		Field:   v.field,
+		Code:    code,
+		Message: v.translate(msg),
+	})
+}
+
+// translate returns the translation of msg or msg.
+func (v *Validator) translate(msg string) string {
+	if v.bundle != nil {
+		if translated, ok := v.bundle.Translate(v.locale, msg); ok {
+			return translated
+		}
+	}
+	return msg
+}
+
+// ruleMessage returns the translation of key formatted with args, or the fallback formatted with args.
+func (v *Validator) ruleMessage(key string, fallback string, args ...any) string {
+	if v.bundle != nil {
+		if translated, ok := v.bundle.Translate(v.locale, key, args...); ok {
+			return translated
+		}
+	}
+	return fmt.Sprintf(fallback, args...)
+}
+
+// Sub performs validation on a single nested item. Nil items are skipped.
+// Field names of structured errors are prefixed with key.
+func (v *Validator) Sub(key string, item Validatable) *Validator {
+	if item == nil {
+		return v
+	}
+	if rv := reflect.ValueOf(item); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return v
+	}
+	if err := item.Validate(); err != nil {
+		v.appendSub(key, err)
 	}
-	v.errors.WriteString(msg)
+	return v
 }
 
 // ValidateSub performs validation on a sub item.
 func ValidateSub[T Validatable](v *Validator, key string, items []T) *Validator {
 	for i, item := range items {
 		if err := item.Validate(); err != nil {
-			v.append(fmt.Sprintf("%s[%d]: %s", key, i, err.Error()))
+			v.appendSub(fmt.Sprintf("%s[%d]", key, i), err)
 		}
 	}
 	return v
 }
+
+// RequireEach runs the rules of fn for each item. Failures are reported for key[i], field names of structured
+// errors are prefixed with it. Rules added with RequireFunc run with the validator's rules, see ValidateContext.
+func RequireEach[T any](v *Validator, items []T, fn func(*Validator, T), key string) *Validator {
+	for i, item := range items {
+		sub := v.child()
+		fn(sub, item)
+		v.adopt(fmt.Sprintf("%s[%d]", key, i), sub)
+	}
+	return v
+}
+
+// child creates an empty validator with the settings of v.
+func (v *Validator) child() *Validator {
+	return &Validator{
+		errors:     make([]FieldError, 0),
+		structured: v.structured,
+		limits:     v.limits,
+		bundle:     v.bundle,
+		locale:     v.locale,
+		timeout:    v.timeout,
+	}
+}
+
+// adopt takes over the failures, the configuration error and the pending rules of a child validator for key.
+func (v *Validator) adopt(key string, sub *Validator) {
+	if sub.configErr != nil && v.configErr == nil {
+		v.configErr = sub.configErr
+	}
+	if err := sub.err(); err != nil {
+		v.appendSub(key, err)
+	}
+	for _, rule := range sub.pending {
+		if v.structured {
+			if len(rule.field) > 0 {
+				rule.field = key + "." + rule.field
+			} else {
+				rule.field = key
+			}
+		} else {
+			rule.field = v.field
+			rule.prefix = key + ": " + rule.prefix
+		}
+		v.pending = append(v.pending, rule)
+	}
+}
+
+// appendSub appends the errors of a sub item. Field names of structured errors are prefixed with key.
+func (v *Validator) appendSub(key string, err error) {
+	if !v.structured {
+		v.append(CodeInvalid, fmt.Sprintf("%s: %s", key, err.Error()))
+		return
+	}
+	var e *ValidationError
+	if !errors.As(err, &e) {
+		v.errors = append(v.errors, FieldError{Field: key, Code: CodeInvalid, Message: err.Error()})
+		return
+	}
+	for _, fe := range e.Errors {
+		field := key
+		if len(fe.Field) > 0 {
+			field = key + "." + fe.Field
+		}
+		other := fe.Other
+		if len(other) > 0 {
+			other = key + "." + other
+		}
+		v.errors = append(v.errors, FieldError{
+			Field:   field,
+			Other:   other,
+			Code:    fe.Code,
+			Message: fe.Message,
+		})
+	}
+}
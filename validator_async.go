@@ -0,0 +1,105 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// defaultAsyncTimeout is the default time rules added with RequireFunc may take.
+const defaultAsyncTimeout = 5 * time.Second
+
+// CodeTimeout is reported for rules added with RequireFunc that did not finish in time.
+const CodeTimeout = "timeout"
+
+type asyncRule struct {
+	field string
+	// prefix is prepended to the messages of rules adopted from unstructured child validators.
+	prefix string
+	fn     func(ctx context.Context) error
+}
+
+type asyncResult struct {
+	index int
+	err   error
+}
+
+// AsyncTimeout sets the time rules added with RequireFunc may take. Defaults to 5 seconds.
+func (v *Validator) AsyncTimeout(d time.Duration) *Validator {
+	v.timeout = d
+	return v
+}
+
+// RequireFunc adds a rule performing external lookups, e.g. checking that a user name is not taken.
+// The rule fails if fn returns an error, reported with the error message.
+// Rules run concurrently when ValidateContext or Validate is called. fn receives the context passed to ValidateContext,
+// limited by the AsyncTimeout; rules that did not finish when it is done fail with CodeTimeout.
+func (v *Validator) RequireFunc(fn func(ctx context.Context) error) *Validator {
+	v.pending = append(v.pending, asyncRule{field: v.field, fn: fn})
+	return v
+}
+
+// ValidateContext performs the validation like Validate. Rules added with RequireFunc run with ctx, e.g. the request
+// Context, so they are canceled with the request.
+//
+// Validate does not take a context because it implements Validatable, which is called by the binding helpers.
+func (v *Validator) ValidateContext(ctx context.Context) error {
+	v.runPending(ctx)
+	return v.Validate()
+}
+
+// runPending runs the rules added with RequireFunc and appends their failures in the order the rules were added.
+func (v *Validator) runPending(parent context.Context) {
+	if len(v.pending) == 0 {
+		return
+	}
+	rules := v.pending
+	v.pending = nil
+	timeout := v.timeout
+	if timeout <= 0 {
+		timeout = defaultAsyncTimeout
+	}
+	results := make(chan asyncResult, len(rules))
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+	for i, rule := range rules {
+		go func(i int, fn func(ctx context.Context) error) {
+			results <- asyncResult{index: i, err: fn(ctx)}
+		}(i, rule.fn)
+	}
+	errs := make([]error, len(rules))
+	done := make([]bool, len(rules))
+collect:
+	for remaining := len(rules); remaining > 0; remaining-- {
+		select {
+		case r := <-results:
+			errs[r.index] = r.err
+			done[r.index] = true
+		case <-ctx.Done():
+			break collect
+		}
+	}
+	field := v.field
+	for i, rule := range rules {
+		v.field = rule.field
+		if !done[i] || errors.Is(errs[i], context.DeadlineExceeded) || errors.Is(errs[i], context.Canceled) {
+			v.appendRule(rule, CodeTimeout, "validation timed out")
+		} else if errs[i] != nil {
+			v.appendRule(rule, CodeInvalid, errs[i].Error())
+		}
+	}
+	v.field = field
+}
+
+// appendRule appends the failure of an async rule, prefixed for rules adopted from child validators.
+func (v *Validator) appendRule(rule asyncRule, code string, msg string) {
+	v.errors = append(v.errors, FieldError{
+		Field:   v.field,
+		Code:    code,
+		Message: rule.prefix + v.translate(msg),
+	})
+}
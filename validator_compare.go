@@ -0,0 +1,75 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import "time"
+
+// Ordered is a constraint for all types supporting the ordering operators.
+type Ordered interface {
+	Number | ~string
+}
+
+// RequireEquals requires a value to equal the value of the other field, e.g. a password confirmation.
+// The failure is reported for the current field and the other field.
+func RequireEquals[T comparable](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x == y, CodeEquals, other, message)
+}
+
+// RequireNotEquals requires a value to differ from the value of the other field.
+// The failure is reported for the current field and the other field.
+func RequireNotEquals[T comparable](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x != y, CodeNotEquals, other, message)
+}
+
+// RequireLessThanField requires a value to be less than the value of the other field.
+// The failure is reported for the current field and the other field.
+func RequireLessThanField[T Ordered](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x < y, CodeLessThan, other, message)
+}
+
+// RequireLessOrEqualField requires a value to be less than or equal to the value of the other field.
+// The failure is reported for the current field and the other field.
+func RequireLessOrEqualField[T Ordered](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x <= y, CodeLessOrEqual, other, message)
+}
+
+// RequireGreaterThanField requires a value to be greater than the value of the other field.
+// The failure is reported for the current field and the other field.
+func RequireGreaterThanField[T Ordered](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x > y, CodeGreaterThan, other, message)
+}
+
+// RequireGreaterOrEqualField requires a value to be greater than or equal to the value of the other field.
+// The failure is reported for the current field and the other field.
+func RequireGreaterOrEqualField[T Ordered](v *Validator, x T, other string, y T, message string) *Validator {
+	return v.requirePair(x >= y, CodeGreaterOrEqual, other, message)
+}
+
+// RequireBeforeField requires a time to be before the time of the other field, e.g. a start before its end.
+// Zero times are skipped. The failure is reported for the current field and the other field.
+func (v *Validator) RequireBeforeField(t time.Time, other string, u time.Time, message string) *Validator {
+	if t.IsZero() || u.IsZero() {
+		return v
+	}
+	return v.requirePair(t.Before(u), CodeBefore, other, message)
+}
+
+// RequireAfterField requires a time to be after the time of the other field.
+// Zero times are skipped. The failure is reported for the current field and the other field.
+func (v *Validator) RequireAfterField(t time.Time, other string, u time.Time, message string) *Validator {
+	if t.IsZero() || u.IsZero() {
+		return v
+	}
+	return v.requirePair(t.After(u), CodeAfter, other, message)
+}
+
+// requirePair requires a condition comparing the current field with the other field.
+func (v *Validator) requirePair(condition bool, code string, other string, message string) *Validator {
+	if !condition {
+		v.append(code, message)
+		v.errors[len(v.errors)-1].Other = other
+	}
+	return v
+}
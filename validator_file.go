@@ -0,0 +1,79 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+)
+
+// RequireFile requires a file to be uploaded
+func (v *Validator) RequireFile(file *multipart.FileHeader, message string) *Validator {
+	return v.require(file != nil, CodeRequired, message)
+}
+
+// RequireFileMaxSize requires an uploaded file to have at most max bytes
+func (v *Validator) RequireFileMaxSize(file *multipart.FileHeader, max int64, message string) *Validator {
+	if file == nil {
+		return v
+	}
+	return v.require(file.Size <= max, CodeFileSize, message)
+}
+
+// RequireFileContentType requires the content of an uploaded file to be one of the given media types.
+// The content type is detected from the file content, the content type sent by the client is ignored.
+func (v *Validator) RequireFileContentType(file *multipart.FileHeader, message string, contentTypes ...string) *Validator {
+	if file == nil {
+		return v
+	}
+	detected, err := detectContentType(file)
+	if err != nil {
+		v.append(CodeContentType, message)
+		return v
+	}
+	for _, ct := range contentTypes {
+		if ct == detected {
+			return v
+		}
+	}
+	v.append(CodeContentType, message)
+	return v
+}
+
+// RequireImageDimensions requires an uploaded file to be a GIF, JPEG or PNG image not exceeding the given dimensions
+func (v *Validator) RequireImageDimensions(file *multipart.FileHeader, maxWidth int, maxHeight int, message string) *Validator {
+	if file == nil {
+		return v
+	}
+	f, err := file.Open()
+	if err != nil {
+		v.append(CodeImageDimensions, message)
+		return v
+	}
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	return v.require(err == nil && cfg.Width <= maxWidth && cfg.Height <= maxHeight, CodeImageDimensions, message)
+}
+
+func detectContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	return mediaType, err
+}
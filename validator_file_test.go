@@ -0,0 +1,81 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+)
+
+// fileHeader creates the header of an uploaded file with the given content.
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fw.Write(content)
+	_ = mw.Close()
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		t.Fatal(err)
+	}
+	return r.MultipartForm.File["file"][0]
+}
+
+func pngImage(t *testing.T, width int, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestValidator_FileRules(t *testing.T) {
+	text := fileHeader(t, "notes.txt", []byte("hello world"))
+	img := fileHeader(t, "avatar.png", pngImage(t, 64, 32))
+	tests := []struct {
+		name     string
+		validate func(v *Validator)
+		code     string
+	}{
+		{"required missing", func(v *Validator) { v.RequireFile(nil, "m") }, CodeRequired},
+		{"required present", func(v *Validator) { v.RequireFile(text, "m") }, ""},
+		{"max size exceeded", func(v *Validator) { v.RequireFileMaxSize(text, 5, "m") }, CodeFileSize},
+		{"max size", func(v *Validator) { v.RequireFileMaxSize(text, 11, "m") }, ""},
+		{"max size without file", func(v *Validator) { v.RequireFileMaxSize(nil, 5, "m") }, ""},
+		{"content type", func(v *Validator) { v.RequireFileContentType(img, "m", "image/png", "image/jpeg") }, ""},
+		{"content type detected", func(v *Validator) { v.RequireFileContentType(text, "m", "image/png") }, CodeContentType},
+		{"content type without file", func(v *Validator) { v.RequireFileContentType(nil, "m", "image/png") }, ""},
+		{"dimensions", func(v *Validator) { v.RequireImageDimensions(img, 64, 32, "m") }, ""},
+		{"dimensions exceeded", func(v *Validator) { v.RequireImageDimensions(img, 32, 32, "m") }, CodeImageDimensions},
+		{"dimensions of non image", func(v *Validator) { v.RequireImageDimensions(text, 64, 64, "m") }, CodeImageDimensions},
+		{"dimensions without file", func(v *Validator) { v.RequireImageDimensions(nil, 1, 1, "m") }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewValidator().Structured()
+			tt.validate(v)
+			err := v.Validate()
+			if len(tt.code) == 0 {
+				if err != nil {
+					t.Fatal("expected no error, got", err)
+				}
+				return
+			}
+			var ve *ValidationError
+			if !errors.As(err, &ve) || len(ve.Errors) != 1 || ve.Errors[0].Code != tt.code {
+				t.Fatalf("expected %s, got %v", tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+// Number is a constraint for all integer and floating point types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// RequireMin requires a number to be at least min.
+func RequireMin[T Number](v *Validator, x T, min T, message string) *Validator {
+	return v.require(x >= min, CodeMin, message)
+}
+
+// RequireMax requires a number to be at most max.
+func RequireMax[T Number](v *Validator, x T, max T, message string) *Validator {
+	return v.require(x <= max, CodeMax, message)
+}
+
+// RequireBetween requires a number to be between min and max (both inclusive).
+func RequireBetween[T Number](v *Validator, x T, min T, max T, message string) *Validator {
+	return v.require(x >= min && x <= max, CodeBetween, message)
+}
+
+// RequirePositive requires a number to be greater than zero.
+func RequirePositive[T Number](v *Validator, x T, message string) *Validator {
+	return v.require(x > 0, CodePositive, message)
+}
+
+// RequireNonNegative requires a number to be zero or greater.
+func RequireNonNegative[T Number](v *Validator, x T, message string) *Validator {
+	return v.require(x >= 0, CodeNonNegative, message)
+}
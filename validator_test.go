@@ -4,7 +4,13 @@
 
 package jug
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
 
 func TestNewValidator(t *testing.T) {
 	v := NewValidator()
@@ -41,3 +47,395 @@ func TestValidator_RequireEnum(t *testing.T) {
 		t.Fatal("error should contain the provided message, got", err.Error())
 	}
 }
+
+func TestValidator_Structured(t *testing.T) {
+	err := NewValidator().
+		Structured().
+		Field("name").
+		RequireStringNotEmpty("", "name is required").
+		Field("role").
+		RequireEnum("x", "invalid role", "admin", "user").
+		Validate()
+	e, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatal("Validate() should return a *ValidationError in structured mode, got", err)
+	}
+	expected := []FieldError{
+		{Field: "name", Code: CodeRequired, Message: "name is required"},
+		{Field: "role", Code: CodeEnum, Message: "invalid role"},
+	}
+	if len(e.Errors) != len(expected) || e.Errors[0] != expected[0] || e.Errors[1] != expected[1] {
+		t.Fatal("unexpected field errors", e.Errors)
+	}
+	if e.Error() != "name is required, invalid role" {
+		t.Fatal("error should join all messages, got", e.Error())
+	}
+	data, _ := json.Marshal(e)
+	if string(data) != `[{"field":"name","code":"required","message":"name is required"},{"field":"role","code":"enum","message":"invalid role"}]` {
+		t.Fatal("ValidationError should marshal to a JSON array, got", string(data))
+	}
+}
+
+type validatorTestItem struct {
+	name string
+}
+
+func (i validatorTestItem) Validate() error {
+	return NewValidator().Structured().Field("name").RequireStringNotEmpty(i.name, "name is required").Validate()
+}
+
+func TestValidateSub_Structured(t *testing.T) {
+	err := ValidateSub(NewValidator().Structured(), "items", []validatorTestItem{{"a"}, {""}}).Validate()
+	e, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatal("Validate() should return a *ValidationError in structured mode, got", err)
+	}
+	if len(e.Errors) != 1 || e.Errors[0].Field != "items[1].name" {
+		t.Fatal("expected the field name to be prefixed with the item key, got", e.Errors)
+	}
+}
+
+func TestValidator_RequireFitsColumn(t *testing.T) {
+	limits := NewSchemaLimits().Register("users.name", 3)
+	if err := NewValidator().Limits(limits).RequireFitsColumn("äöü", "users.name", "too long").Validate(); err != nil {
+		t.Fatal("RequireFitsColumn() should count characters, not bytes, got", err)
+	}
+	if err := NewValidator().Limits(limits).RequireFitsColumn("abcd", "users.name", "too long").Validate(); err == nil {
+		t.Fatal("RequireFitsColumn() should fail when the value exceeds the column")
+	}
+}
+
+func TestValidator_RequireFitsColumn_Unregistered(t *testing.T) {
+	err := NewValidator().Limits(NewSchemaLimits()).RequireFitsColumn("a", "users.name", "too long").Validate()
+	if err == nil || err.Error() != "jug: no schema limit registered for column users.name" {
+		t.Fatal("RequireFitsColumn() should return a configuration error for unregistered columns, got", err)
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		t.Fatal("configuration errors should not be validation errors")
+	}
+}
+
+func TestFieldValue_FitsColumn(t *testing.T) {
+	limits := NewSchemaLimits().Register("users.name", 3)
+	if err := NewValidator().Limits(limits).FieldValue("name", "äöü").FitsColumn("users.name").Validate(); err != nil {
+		t.Fatal("FitsColumn() should count characters, not bytes, got", err)
+	}
+	err := NewValidator().Structured().Limits(limits).FieldValue("name", "abcd").FitsColumn("users.name").Validate()
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Errors) != 1 {
+		t.Fatal("FitsColumn() should fail when the value exceeds the column, got", err)
+	}
+	if fe := ve.Errors[0]; fe.Field != "name" || fe.Code != CodeMaxLength || fe.Message != "name must have at most 3 characters" {
+		t.Fatal("expected a field error for name, got", fe)
+	}
+}
+
+func TestValidator_RequireEmail(t *testing.T) {
+	for _, s := range []string{"", "jane@example.com", "jane.doe+tag@sub.example.org"} {
+		if err := NewValidator().RequireEmail(s, "message").Validate(); err != nil {
+			t.Errorf("RequireEmail(%q) should not fail, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"jane", "jane@", "Jane <jane@example.com>", "jane@example.com "} {
+		if err := NewValidator().RequireEmail(s, "message").Validate(); err == nil {
+			t.Errorf("RequireEmail(%q) should fail", s)
+		}
+	}
+}
+
+func TestValidator_RequireURL(t *testing.T) {
+	for _, s := range []string{"", "https://example.com", "http://localhost:8000/path?q=1"} {
+		if err := NewValidator().RequireURL(s, "message").Validate(); err != nil {
+			t.Errorf("RequireURL(%q) should not fail, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"example.com", "/path", "https://", "://example.com"} {
+		if err := NewValidator().RequireURL(s, "message").Validate(); err == nil {
+			t.Errorf("RequireURL(%q) should fail", s)
+		}
+	}
+}
+
+func TestValidator_RequireUUID(t *testing.T) {
+	for _, s := range []string{"", "123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"} {
+		if err := NewValidator().RequireUUID(s, "message").Validate(); err != nil {
+			t.Errorf("RequireUUID(%q) should not fail, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g", "{123e4567-e89b-12d3-a456-426614174000}"} {
+		if err := NewValidator().RequireUUID(s, "message").Validate(); err == nil {
+			t.Errorf("RequireUUID(%q) should fail", s)
+		}
+	}
+}
+
+func TestRequireBetween(t *testing.T) {
+	if err := RequireBetween(NewValidator(), 5, 1, 5, "message").Validate(); err != nil {
+		t.Fatal("RequireBetween() should include the bounds, got", err)
+	}
+	if err := RequireBetween(NewValidator(), 0.5, 1.0, 5.0, "message").Validate(); err == nil {
+		t.Fatal("RequireBetween() should fail for values below min")
+	}
+	if err := RequireBetween(NewValidator(), int64(6), 1, 5, "message").Validate(); err == nil {
+		t.Fatal("RequireBetween() should fail for values above max")
+	}
+}
+
+func TestRequirePositive(t *testing.T) {
+	v := NewValidator()
+	RequirePositive(v, 0, "positive")
+	RequireNonNegative(v, 0, "non negative")
+	err := v.Validate()
+	if err == nil || err.Error() != "positive" {
+		t.Fatal("only RequirePositive() should fail for zero, got", err)
+	}
+}
+
+func TestValidator_When(t *testing.T) {
+	err := NewValidator().
+		When(false, func(v *Validator) { v.Require(false, "skipped") }).
+		When(true, func(v *Validator) { v.Require(false, "applied") }).
+		Validate()
+	if err == nil || err.Error() != "applied" {
+		t.Error("expected only the applied rule to fail, got", err)
+	}
+}
+
+func TestValidator_RequireOneOfPresent(t *testing.T) {
+	if err := NewValidator().RequireOneOfPresent(map[string]string{"email": "", "phone": "123"}, "contact required").Validate(); err != nil {
+		t.Error("expected no error, got", err)
+	}
+	err := NewValidator().Structured().Field("name").RequireOneOfPresent(map[string]string{"phone": "", "email": ""}, "contact required").Validate()
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 1 || e.Errors[0].Field != "email,phone" || e.Errors[0].Code != CodeRequired {
+		t.Error("expected required error for email and phone, got", err)
+	}
+}
+
+func TestValidator_Sub(t *testing.T) {
+	var missing *validatorTestItem
+	err := NewValidator().Structured().
+		Sub("address", validatorTestItem{}).
+		Sub("billing", missing).
+		Sub("shipping", nil).
+		Validate()
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 1 || e.Errors[0].Field != "address.name" {
+		t.Error("expected nested error for address only, got", err)
+	}
+}
+
+func TestValidator_TimeRules(t *testing.T) {
+	now := time.Now()
+	hour := time.Hour
+	tests := []struct {
+		name  string
+		fun   func(v *Validator)
+		valid bool
+	}{
+		{"before", func(v *Validator) { v.RequireBefore(now, now.Add(hour), "") }, true},
+		{"not before", func(v *Validator) { v.RequireBefore(now, now, "") }, false},
+		{"after", func(v *Validator) { v.RequireAfter(now, now.Add(-hour), "") }, true},
+		{"not after", func(v *Validator) { v.RequireAfter(now, now, "") }, false},
+		{"between inclusive", func(v *Validator) { v.RequireBetweenTimes(now, now, now.Add(hour), "") }, true},
+		{"not between", func(v *Validator) { v.RequireBetweenTimes(now.Add(2*hour), now, now.Add(hour), "") }, false},
+		{"future", func(v *Validator) { v.RequireFutureDate(now.Add(hour), "") }, true},
+		{"not future", func(v *Validator) { v.RequireFutureDate(now.Add(-hour), "") }, false},
+		{"past", func(v *Validator) { v.RequirePastDate(now.Add(-hour), "") }, true},
+		{"not past", func(v *Validator) { v.RequirePastDate(now.Add(hour), "") }, false},
+		{"zero skipped", func(v *Validator) { v.RequirePastDate(time.Time{}, "") }, true},
+	}
+	for _, tt := range tests {
+		err := NewValidator().V(tt.fun).Validate()
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestValidator_RequireISODuration(t *testing.T) {
+	for _, s := range []string{"", "P1Y", "P1Y2M3D", "P3W", "PT1H30M", "PT0.5S", "P1DT12H"} {
+		if err := NewValidator().RequireISODuration(s, "invalid").Validate(); err != nil {
+			t.Errorf("expected %q to be valid, got %v", s, err)
+		}
+	}
+	for _, s := range []string{"P", "PT", "1D", "P1DT", "PT1D", "P1.5D", "P-1D"} {
+		if err := NewValidator().RequireISODuration(s, "invalid").Validate(); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
+
+func TestValidator_CrossFieldRules(t *testing.T) {
+	now := time.Now()
+	v := NewValidator().Structured()
+	v.Field("passwordConfirmation")
+	RequireEquals(v, "secret", "password", "secret", "passwords must match")
+	RequireEquals(v, "secret", "password", "other", "passwords must match")
+	v.Field("newPassword")
+	RequireNotEquals(v, "secret", "oldPassword", "secret", "password must change")
+	v.Field("min")
+	RequireLessThanField(v, 1, "max", 2, "min must be less than max")
+	RequireLessOrEqualField(v, 3, "max", 2, "min must not exceed max")
+	v.Field("max")
+	RequireGreaterThanField(v, "b", "min", "a", "max must be greater than min")
+	RequireGreaterOrEqualField(v, 1.5, "min", 1.5, "max must not be less than min")
+	v.Field("start").RequireBeforeField(now, "end", now.Add(-time.Hour), "start must be before end")
+	v.Field("end").RequireAfterField(now, "start", time.Time{}, "skipped")
+
+	e, ok := v.Validate().(*ValidationError)
+	if !ok {
+		t.Fatal("expected a *ValidationError")
+	}
+	expected := []FieldError{
+		{Field: "passwordConfirmation", Other: "password", Code: CodeEquals, Message: "passwords must match"},
+		{Field: "newPassword", Other: "oldPassword", Code: CodeNotEquals, Message: "password must change"},
+		{Field: "min", Other: "max", Code: CodeLessOrEqual, Message: "min must not exceed max"},
+		{Field: "start", Other: "end", Code: CodeBefore, Message: "start must be before end"},
+	}
+	if len(e.Errors) != len(expected) {
+		t.Fatal("unexpected field errors", e.Errors)
+	}
+	for i := range expected {
+		if e.Errors[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], e.Errors[i])
+		}
+	}
+	data, _ := json.Marshal(e.Errors[0])
+	if string(data) != `{"field":"passwordConfirmation","other":"password","code":"equals","message":"passwords must match"}` {
+		t.Error("expected the other field in JSON, got", string(data))
+	}
+}
+
+func TestRequireEach(t *testing.T) {
+	emails := []string{"jane@example.com", "invalid", ""}
+	rule := func(v *Validator, email string) {
+		v.RequireStringNotEmpty(email, "email is required").RequireEmail(email, "email is invalid")
+	}
+	err := RequireEach(NewValidator(), emails, rule, "emails").Validate()
+	if err == nil || err.Error() != "emails[1]: email is invalid, emails[2]: email is required" {
+		t.Error("expected index prefixed messages, got", err)
+	}
+
+	err = RequireEach(NewValidator().Structured(), emails, func(v *Validator, email string) {
+		v.Field("address")
+		rule(v, email)
+	}, "emails").Validate()
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 2 || e.Errors[0].Field != "emails[1].address" || e.Errors[1].Field != "emails[2].address" {
+		t.Error("expected index prefixed fields, got", err)
+	}
+}
+
+func TestRequireEach_Child(t *testing.T) {
+	err := RequireEach(NewValidator().Limits(NewSchemaLimits()), []string{"a"}, func(v *Validator, s string) {
+		v.RequireFitsColumn(s, "users.name", "too long")
+	}, "names").Validate()
+	if err == nil || err.Error() != "jug: no schema limit registered for column users.name" {
+		t.Error("expected configuration error of the child, got", err)
+	}
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "request")
+	taken := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			if ctx.Value(key{}) != "request" {
+				return errors.New("missing request context")
+			}
+			if name == "jane" {
+				return errors.New("name is taken")
+			}
+			return nil
+		}
+	}
+	rule := func(v *Validator, name string) {
+		v.RequireFunc(taken(name))
+	}
+	err = RequireEach(NewValidator().Structured(), []string{"john", "jane"}, func(v *Validator, name string) {
+		v.Field("name")
+		rule(v, name)
+	}, "users").ValidateContext(ctx)
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 1 || e.Errors[0].Field != "users[1].name" || e.Errors[0].Message != "name is taken" {
+		t.Error("expected child rules to run with the context, got", err)
+	}
+
+	err = RequireEach(NewValidator(), []string{"john", "jane"}, rule, "users").ValidateContext(ctx)
+	if err == nil || err.Error() != "users[1]: name is taken" {
+		t.Error("expected index prefixed message, got", err)
+	}
+}
+
+func TestValidator_RequireFunc(t *testing.T) {
+	taken := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			if name == "jane" {
+				return errors.New("name is taken")
+			}
+			return nil
+		}
+	}
+	start := time.Now()
+	err := NewValidator().Structured().
+		Field("name").RequireFunc(taken("jane")).
+		Field("alias").RequireFunc(taken("john")).
+		Field("nickname").RequireFunc(taken("jane")).
+		Validate()
+	if time.Since(start) > 25*time.Millisecond {
+		t.Error("expected rules to run concurrently")
+	}
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 2 || e.Errors[0].Field != "name" || e.Errors[1].Field != "nickname" || e.Errors[0].Message != "name is taken" {
+		t.Error("expected errors for name and nickname, got", err)
+	}
+
+	block := make(chan struct{})
+	defer close(block)
+	slow := func(ctx context.Context) error {
+		<-block
+		return nil
+	}
+	aware := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	err = NewValidator().Structured().AsyncTimeout(10 * time.Millisecond).
+		Field("slow").RequireFunc(slow).
+		Field("aware").RequireFunc(aware).
+		Validate()
+	e, ok = err.(*ValidationError)
+	if !ok || len(e.Errors) != 2 || e.Errors[0].Code != CodeTimeout || e.Errors[1].Code != CodeTimeout {
+		t.Error("expected timeouts, got", err)
+	}
+}
+
+func TestValidator_ValidateContext(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "request"))
+	var got interface{}
+	record := func(ctx context.Context) error {
+		got = ctx.Value(key{})
+		return nil
+	}
+	err := NewValidator().Structured().Field("name").RequireFunc(record).ValidateContext(ctx)
+	if err != nil || got != "request" {
+		t.Errorf("expected rule to receive the context, got %v, %v", got, err)
+	}
+
+	aware := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	cancel()
+	start := time.Now()
+	err = NewValidator().Structured().Field("name").RequireFunc(aware).ValidateContext(ctx)
+	if time.Since(start) > time.Second {
+		t.Error("expected cancellation to end validation")
+	}
+	e, ok := err.(*ValidationError)
+	if !ok || len(e.Errors) != 1 || e.Errors[0].Code != CodeTimeout {
+		t.Error("expected timeout for canceled context, got", err)
+	}
+}
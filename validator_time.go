@@ -0,0 +1,66 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+var isoDurationRegex = regexp.MustCompile(`^P(?:\d+Y)?(?:\d+M)?(?:\d+W)?(?:\d+D)?(?:T(?:\d+H)?(?:\d+M)?(?:\d+(?:[.,]\d+)?S)?)?$`)
+
+// RequireBefore requires a time to be before max. Zero times are skipped.
+func (v *Validator) RequireBefore(t time.Time, max time.Time, message string) *Validator {
+	if t.IsZero() {
+		return v
+	}
+	return v.require(t.Before(max), CodeBefore, message)
+}
+
+// RequireAfter requires a time to be after min. Zero times are skipped.
+func (v *Validator) RequireAfter(t time.Time, min time.Time, message string) *Validator {
+	if t.IsZero() {
+		return v
+	}
+	return v.require(t.After(min), CodeAfter, message)
+}
+
+// RequireBetweenTimes requires a time to be between min and max (both inclusive). Zero times are skipped.
+func (v *Validator) RequireBetweenTimes(t time.Time, min time.Time, max time.Time, message string) *Validator {
+	if t.IsZero() {
+		return v
+	}
+	return v.require(!t.Before(min) && !t.After(max), CodeBetween, message)
+}
+
+// RequireFutureDate requires a time to be in the future. Zero times are skipped.
+func (v *Validator) RequireFutureDate(t time.Time, message string) *Validator {
+	if t.IsZero() {
+		return v
+	}
+	return v.require(t.After(time.Now()), CodeFuture, message)
+}
+
+// RequirePastDate requires a time to be in the past. Zero times are skipped.
+func (v *Validator) RequirePastDate(t time.Time, message string) *Validator {
+	if t.IsZero() {
+		return v
+	}
+	return v.require(t.Before(time.Now()), CodePast, message)
+}
+
+// RequireISODuration requires a value to be an ISO 8601 duration, e.g. P1Y2M, P3W or PT1H30M.
+func (v *Validator) RequireISODuration(s string, message string) *Validator {
+	if len(s) == 0 {
+		return v
+	}
+	return v.require(isISODuration(s), CodeDuration, message)
+}
+
+// isISODuration reports whether s is an ISO 8601 duration with at least one component.
+func isISODuration(s string) bool {
+	return isoDurationRegex.MatchString(s) && s != "P" && !strings.HasSuffix(s, "T")
+}
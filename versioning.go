@@ -0,0 +1,235 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionStrategy selects how clients request an API version.
+type VersionStrategy int
+
+const (
+	// VersionByPath selects the version by a path segment, e.g. /v1/users.
+	VersionByPath VersionStrategy = iota
+	// VersionByHeader selects the version by a vendor media type in the Accept header,
+	// e.g. application/vnd.acme.v2+json, or by a custom header.
+	VersionByHeader
+	// VersionByQuery selects the version by a query parameter, e.g. /users?version=v2.
+	VersionByQuery
+)
+
+// VersioningConfig configures API versions.
+type VersioningConfig struct {
+	Strategy VersionStrategy
+	// Vendor is the vendor of the media types of VersionByHeader, e.g. acme for application/vnd.acme.v2+json.
+	Vendor string
+	// Header is a request header holding the version, e.g. Api-Version. It is used by VersionByHeader instead of
+	// the Accept header.
+	Header string
+	// Query is the query parameter of VersionByQuery. Defaults to version.
+	Query string
+	// Default is the version of requests not asking for one with VersionByHeader and VersionByQuery.
+	// If empty, such requests are answered with 400.
+	Default string
+}
+
+// VersionOption configures an API version.
+type VersionOption func(v *apiVersion)
+
+// Deprecated marks a version as deprecated since the given time. Responses carry a Deprecation header.
+func Deprecated(since time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.deprecated = since
+	}
+}
+
+// Sunset announces the time a version is removed. Responses carry a Sunset header.
+func Sunset(at time.Time) VersionOption {
+	return func(v *apiVersion) {
+		v.sunset = at
+	}
+}
+
+// DeprecationLink links documentation about the deprecation, e.g. a migration guide.
+func DeprecationLink(url string) VersionOption {
+	return func(v *apiVersion) {
+		v.link = url
+	}
+}
+
+// apiVersionKey stores the version selected for a request.
+var apiVersionKey = Key[string]("jug.apiVersion")
+
+// APIVersion returns the API version of the route handling the request.
+func APIVersion(c Context) string {
+	return apiVersionKey.GetOr(c, "")
+}
+
+type apiVersion struct {
+	name       string
+	deprecated time.Time
+	sunset     time.Time
+	link       string
+	// router serves the routes of the version with VersionByHeader and VersionByQuery.
+	router *gin.Engine
+}
+
+// headers sets the version and the deprecation headers.
+func (v *apiVersion) headers(c Context) {
+	apiVersionKey.Set(c, v.name)
+	if !v.deprecated.IsZero() {
+		c.SetHeader("Deprecation", "@"+strconv.FormatInt(v.deprecated.Unix(), 10))
+	}
+	if !v.sunset.IsZero() {
+		c.SetHeader("Sunset", v.sunset.UTC().Format(http.TimeFormat))
+	}
+	if len(v.link) > 0 {
+		c.SetHeader("Link", "<"+v.link+">; rel=\"deprecation\"")
+	}
+}
+
+// APIVersions holds the versions of an API.
+// With VersionByPath, each version is a group below its name. With VersionByHeader and VersionByQuery, the versions
+// share their paths: each version has its own routes and the route of the group passes requests on to the
+// routes of the requested version.
+type APIVersions struct {
+	cfg      VersioningConfig
+	parent   RouterGroup
+	basePath string
+	config   *engineConfig
+	versions map[string]*apiVersion
+	names    []string
+	// routes holds the methods and paths routes were registered for on the parent.
+	routes map[dynamicRouteKey]bool
+}
+
+func newAPIVersions(parent RouterGroup, basePath string, config *engineConfig, cfg VersioningConfig) *APIVersions {
+	if len(cfg.Query) == 0 {
+		cfg.Query = "version"
+	}
+	return &APIVersions{
+		cfg:      cfg,
+		parent:   parent,
+		basePath: basePath,
+		config:   config,
+		versions: make(map[string]*apiVersion),
+		names:    make([]string, 0),
+		routes:   make(map[dynamicRouteKey]bool),
+	}
+}
+
+// Version creates the group of a version. It panics if the version already exists.
+func (a *APIVersions) Version(version string, opts ...VersionOption) RouterGroup {
+	if _, ok := a.versions[version]; ok {
+		panic(fmt.Sprintf("jug: API version %s is already registered", version))
+	}
+	v := &apiVersion{name: version}
+	for _, opt := range opts {
+		opt(v)
+	}
+	a.versions[version] = v
+	a.names = append(a.names, version)
+	if a.cfg.Strategy == VersionByPath {
+		return a.parent.Group("/"+version, v.headers)
+	}
+	v.router = newInnerRouter()
+	g := newGinRouterGroup(v.router, v.router.Group(a.basePath, a.config.wrapHandler(v.headers)), a.config, nil)
+	g.onAdd = a.route
+	return g
+}
+
+// Versions returns the names of the versions in order of creation.
+func (a *APIVersions) Versions() []string {
+	return append([]string(nil), a.names...)
+}
+
+// route registers a route passing requests on to the requested version, unless the method and path are registered.
+func (a *APIVersions) route(relativePath string, methods ...string) {
+	for _, m := range methods {
+		key := dynamicRouteKey{method: m, path: relativePath}
+		if a.routes[key] {
+			continue
+		}
+		a.routes[key] = true
+		handleMethod(a.parent, m, relativePath, a.dispatch)
+	}
+}
+
+func (a *APIVersions) dispatch(c Context) {
+	gc, ok := ginContextOf(c)
+	if !ok {
+		return
+	}
+	if len(a.cfg.Header) > 0 {
+		gc.Writer.Header().Add("Vary", a.cfg.Header)
+	} else if a.cfg.Strategy == VersionByHeader {
+		gc.Writer.Header().Add("Vary", "Accept")
+	}
+	name := a.requestedVersion(gc.Request)
+	if len(name) == 0 {
+		name = a.cfg.Default
+	}
+	if len(name) == 0 {
+		c.HandleError(NewBadRequestError("missing API version"))
+		return
+	}
+	v, ok := a.versions[name]
+	if !ok || v.router == nil {
+		c.HandleError(NewBadRequestError("unsupported API version " + name))
+		return
+	}
+	serveInner(v.router, gc)
+}
+
+// requestedVersion returns the version requested by the client or an empty string.
+func (a *APIVersions) requestedVersion(r *http.Request) string {
+	if a.cfg.Strategy == VersionByQuery {
+		return r.URL.Query().Get(a.cfg.Query)
+	}
+	if len(a.cfg.Header) > 0 {
+		return r.Header.Get(a.cfg.Header)
+	}
+	prefix := "application/vnd." + a.cfg.Vendor + "."
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil || !strings.HasPrefix(mediaType, prefix) {
+			continue
+		}
+		version := strings.TrimPrefix(mediaType, prefix)
+		if i := strings.IndexByte(version, '+'); i >= 0 {
+			version = version[:i]
+		}
+		return version
+	}
+	return ""
+}
+
+// handleMethod registers handlers for a method on a router.
+func handleMethod(r Router, method string, relativePath string, handlers ...HandlerFunc) Router {
+	switch method {
+	case http.MethodGet:
+		return r.GET(relativePath, handlers...)
+	case http.MethodPost:
+		return r.POST(relativePath, handlers...)
+	case http.MethodPut:
+		return r.PUT(relativePath, handlers...)
+	case http.MethodDelete:
+		return r.DELETE(relativePath, handlers...)
+	case http.MethodPatch:
+		return r.PATCH(relativePath, handlers...)
+	case http.MethodOptions:
+		return r.OPTIONS(relativePath, handlers...)
+	case http.MethodHead:
+		return r.HEAD(relativePath, handlers...)
+	}
+	panic("jug: unsupported method " + method)
+}
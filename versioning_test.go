@@ -0,0 +1,75 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func versionHandler(c Context) {
+	c.String(http.StatusOK, "%s %s", APIVersion(c), c.Param("id"))
+}
+
+func TestEngine_Version(t *testing.T) {
+	sunset := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	e := New()
+	e.Version("v1", Deprecated(time.Unix(1700000000, 0)), Sunset(sunset), DeprecationLink("https://example.com/migrate")).GET("/users/:id", versionHandler)
+	e.Version("v2").GET("/users/:id", versionHandler)
+
+	w := serve(e, http.MethodGet, "/v1/users/1")
+	if w.Body.String() != "v1 1" || w.Header().Get("Deprecation") != "@1700000000" || w.Header().Get("Sunset") != "Sun, 30 Jun 2024 00:00:00 GMT" || w.Header().Get("Link") != `<https://example.com/migrate>; rel="deprecation"` {
+		t.Fatal("expected deprecated v1, got", w.Body.String(), w.Header())
+	}
+	w = serve(e, http.MethodGet, "/v2/users/1")
+	if w.Body.String() != "v2 1" || len(w.Header().Get("Deprecation")) > 0 {
+		t.Fatal("expected v2, got", w.Body.String(), w.Header())
+	}
+}
+
+func TestRouterGroup_Versioned_Header(t *testing.T) {
+	e := New()
+	e.Use(func(c Context) {
+		c.Set("tenant", "acme")
+	})
+	versions := e.Group("/api").Versioned(VersioningConfig{Strategy: VersionByHeader, Vendor: "acme", Default: "v1"})
+	versions.Version("v1").GET("/users/:id", versionHandler)
+	v2 := versions.Version("v2").Group("/users")
+	v2.GET("/:id", func(c Context) {
+		tenant, _ := c.Get("tenant")
+		c.String(http.StatusOK, "%s %s %s", APIVersion(c), c.Param("id"), tenant)
+	})
+	v2.DELETE("/:id", func(c Context) {
+		c.RespondNoContent()
+	})
+
+	if w := serveHeader(e, "/api/users/1", "Accept", "application/vnd.acme.v2+json"); w.Body.String() != "v2 1 acme" || w.Header().Get("Vary") != "Accept" {
+		t.Fatal("expected v2, got", w.Body.String(), w.Header())
+	}
+	if w := serveHeader(e, "/api/users/1", "Accept", "application/json"); w.Body.String() != "v1 1" {
+		t.Fatal("expected default version, got", w.Body.String())
+	}
+	if w := serveHeader(e, "/api/users/1", "Accept", "application/vnd.acme.v3+json"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected unknown version to be rejected, got", w.Code)
+	}
+	if versions := versions.Versions(); len(versions) != 2 || versions[0] != "v1" || versions[1] != "v2" {
+		t.Fatal("unexpected versions", versions)
+	}
+}
+
+func TestRouterGroup_Versioned_Query(t *testing.T) {
+	e := New()
+	versions := e.Versioned(VersioningConfig{Strategy: VersionByQuery})
+	versions.Version("2023-01").GET("/users/:id", versionHandler)
+	versions.Version("2024-01").GET("/users/:id", versionHandler)
+
+	if w := serve(e, http.MethodGet, "/users/1?version=2024-01"); w.Body.String() != "2024-01 1" {
+		t.Fatal("expected 2024-01, got", w.Body.String())
+	}
+	if w := serve(e, http.MethodGet, "/users/1"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected missing version to be rejected, got", w.Code)
+	}
+}
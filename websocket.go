@@ -0,0 +1,173 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// WSTextMessage denotes a text data message.
+	WSTextMessage = websocket.TextMessage
+	// WSBinaryMessage denotes a binary data message.
+	WSBinaryMessage = websocket.BinaryMessage
+)
+
+// WSConn is a WebSocket connection. It is safe to write from multiple goroutines.
+type WSConn interface {
+	// ReadMessage reads the next data message.
+	ReadMessage() (messageType int, data []byte, err error)
+	// WriteMessage writes a data message.
+	WriteMessage(messageType int, data []byte) error
+	// Close sends a close frame and closes the connection.
+	Close() error
+}
+
+type wsConfig struct {
+	checkOrigin  func(r *http.Request) bool
+	subprotocols []string
+	readLimit    int64
+	pingInterval time.Duration
+}
+
+// WSOption configures a WebSocket upgrade.
+type WSOption func(c *wsConfig)
+
+// WSCheckOrigin sets the function used to validate the Origin header. By default only same origin requests are accepted.
+func WSCheckOrigin(f func(r *http.Request) bool) WSOption {
+	return func(c *wsConfig) {
+		c.checkOrigin = f
+	}
+}
+
+// WSSubprotocols sets the supported subprotocols in order of preference.
+func WSSubprotocols(protocols ...string) WSOption {
+	return func(c *wsConfig) {
+		c.subprotocols = protocols
+	}
+}
+
+// WSReadLimit sets the maximum size in bytes of a message read from the peer.
+func WSReadLimit(limit int64) WSOption {
+	return func(c *wsConfig) {
+		c.readLimit = limit
+	}
+}
+
+// WSPingInterval enables keep alive pings. If the peer does not answer with a pong within twice the interval, reads fail.
+func WSPingInterval(d time.Duration) WSOption {
+	return func(c *wsConfig) {
+		c.pingInterval = d
+	}
+}
+
+type wsConn struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+	done      chan struct{}
+	closeOnce sync.Once
+	shutdown  *shutdownSignal
+}
+
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, shutdown *shutdownSignal, opts ...WSOption) (WSConn, error) {
+	cfg := &wsConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	upgrader := websocket.Upgrader{
+		CheckOrigin:  cfg.checkOrigin,
+		Subprotocols: cfg.subprotocols,
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.readLimit > 0 {
+		conn.SetReadLimit(cfg.readLimit)
+	}
+	c := &wsConn{
+		conn:     conn,
+		done:     make(chan struct{}),
+		shutdown: shutdown,
+	}
+	if cfg.pingInterval > 0 {
+		c.keepAlive(cfg.pingInterval)
+	}
+	c.closeOnShutdown()
+	return c, nil
+}
+
+// closeOnShutdown sends a going away close frame when the engine shuts down.
+// The connection stays open until the peer answers or the shutdown grace period ends.
+func (c *wsConn) closeOnShutdown() {
+	c.shutdown.track(c)
+	go func() {
+		select {
+		case <-c.shutdown.Done():
+			c.writeLock.Lock()
+			_ = c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(time.Second),
+			)
+			c.writeLock.Unlock()
+		case <-c.done:
+		}
+	}()
+}
+
+func (c *wsConn) keepAlive(interval time.Duration) {
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * interval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * interval))
+	})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.writeLock.Lock()
+				err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(interval))
+				c.writeLock.Unlock()
+				if err != nil {
+					return
+				}
+			case <-c.done:
+				return
+			}
+		}
+	}()
+}
+
+func (c *wsConn) ReadMessage() (int, []byte, error) {
+	return c.conn.ReadMessage()
+}
+
+func (c *wsConn) WriteMessage(messageType int, data []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+	return c.conn.WriteMessage(messageType, data)
+}
+
+func (c *wsConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.writeLock.Lock()
+		_ = c.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+			time.Now().Add(time.Second),
+		)
+		c.writeLock.Unlock()
+		err = c.conn.Close()
+		c.shutdown.untrack(c)
+	})
+	return err
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newWebSocketEngine() Engine {
+	e := New()
+	e.GET("/ws", func(c Context) {
+		conn, err := c.UpgradeWebSocket(WSSubprotocols("echo"))
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, append([]byte("echo: "), data...)); err != nil {
+				return
+			}
+		}
+	})
+	return e
+}
+
+func TestContext_UpgradeWebSocket(t *testing.T) {
+	server := httptest.NewServer(newWebSocketEngine())
+	defer server.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"echo"}}
+	conn, res, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatal("expected the upgrade to succeed, got", err)
+	}
+	defer conn.Close()
+	if res.StatusCode != http.StatusSwitchingProtocols || conn.Subprotocol() != "echo" {
+		t.Fatal("expected the echo subprotocol, got", res.StatusCode, conn.Subprotocol())
+	}
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messageType != websocket.TextMessage || string(data) != "echo: hello" {
+		t.Error("expected the message to be echoed, got", messageType, string(data))
+	}
+}
+
+func TestContext_UpgradeWebSocket_NoUpgrade(t *testing.T) {
+	w := serve(newWebSocketEngine(), http.MethodGet, "/ws")
+	if w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for a request without upgrade, got", w.Code)
+	}
+}
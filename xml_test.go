@@ -0,0 +1,50 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type xmlTestOrder struct {
+	XMLName  xml.Name `xml:"order"`
+	ID       string   `xml:"id,attr"`
+	Customer string   `xml:"customer" validate:"required"`
+	Total    int      `xml:"total"`
+}
+
+func TestContext_MustBindXML(t *testing.T) {
+	e := New()
+	e.POST("/orders", func(c Context) {
+		var order xmlTestOrder
+		if c.MustBindXML(&order) {
+			order.Total *= 2
+			c.RespondOkXML(order)
+		}
+	})
+	post := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/xml")
+		return serveRequest(e, r)
+	}
+
+	w := post(`<order id="7"><customer>jug</customer><total>21</total></order>`)
+	if w.Code != http.StatusOK || w.Body.String() != `<order id="7"><customer>jug</customer><total>42</total></order>` {
+		t.Fatal("expected the bound order, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+		t.Error("expected an XML content type, got", ct)
+	}
+	if w := post(`<order><customer>`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for malformed XML, got", w.Code)
+	}
+	if w := post(`<order id="7"><total>1</total></order>`); w.Code != http.StatusBadRequest {
+		t.Error("expected 400 for failed validation, got", w.Code)
+	}
+}
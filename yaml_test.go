@@ -0,0 +1,60 @@
+// Copyright 2023 Christoph Fichtmüller. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package jug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type yamlConfig struct {
+	Name     string `yaml:"name" json:"name" validate:"required"`
+	Replicas int    `yaml:"replicas" json:"replicas"`
+}
+
+func TestContext_YAML(t *testing.T) {
+	e := New()
+	e.PUT("/config", func(c Context) {
+		var cfg yamlConfig
+		if !c.MustBindYAML(&cfg) {
+			return
+		}
+		c.RespondOkYAML(cfg)
+	})
+	e.PATCH("/config", func(c Context) {
+		var cfg yamlConfig
+		if !c.MayBindYAML(&cfg) {
+			return
+		}
+		c.YAML(http.StatusAccepted, nil)
+	})
+	send := func(method string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/config", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/yaml")
+		return serveRequest(e, r)
+	}
+
+	w := send(http.MethodPut, "name: api\nreplicas: 3\n")
+	if w.Code != http.StatusOK || w.Body.String() != "name: api\nreplicas: 3\n" {
+		t.Fatal("expected YAML echo, got", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != YAMLContentType {
+		t.Fatal("unexpected content type", ct)
+	}
+	if w := send(http.MethodPut, "replicas: 3\n"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected validation to fail, got", w.Code)
+	}
+	if w := send(http.MethodPut, "name: [\n"); w.Code != http.StatusBadRequest {
+		t.Fatal("expected invalid YAML to be rejected, got", w.Code)
+	}
+	if w := send(http.MethodPut, ""); w.Code != http.StatusBadRequest || w.Body.String() != `{"error":"request body is missing"}` {
+		t.Fatal("expected missing body, got", w.Code, w.Body.String())
+	}
+	if w := send(http.MethodPatch, ""); w.Code != http.StatusAccepted {
+		t.Fatal("expected empty body to be accepted, got", w.Code)
+	}
+}